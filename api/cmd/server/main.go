@@ -38,6 +38,11 @@ func main() {
 
 	log.Println("Database initialized successfully")
 
+	// Refill normalized performer names before anything looks performers up by name
+	if err := services.NewPerformerService().SyncNormalizedNames(); err != nil {
+		log.Printf("Warning: Performer name sync failed: %v", err)
+	}
+
 	// Run startup performer scan
 	log.Println("Running startup performer scan...")
 	scanService := services.NewPerformerScanService()
@@ -54,6 +59,11 @@ func main() {
 		}
 	}
 
+	// Copy aliases from performer metadata, which may predate the aliases table
+	if err := services.NewPerformerService().SyncAllMetadataAliases(); err != nil {
+		log.Printf("Warning: Performer alias sync failed: %v", err)
+	}
+
 	// Setup router
 	router := api.SetupRouter(cfg)
 
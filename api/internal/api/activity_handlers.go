@@ -70,6 +70,31 @@ func getActivity(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(activity, "Activity retrieved successfully"))
 }
 
+// getActivityTree retrieves an activity with its nested child activities
+func getActivityTree(c *gin.Context) {
+	svc := ensureActivityService()
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid activity ID",
+			err.Error(),
+		))
+		return
+	}
+
+	tree, err := svc.GetTree(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg(
+			"Activity not found",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(tree, "Activity tree retrieved successfully"))
+}
+
 // createActivity creates a new activity log
 func createActivity(c *gin.Context) {
 	svc := ensureActivityService()
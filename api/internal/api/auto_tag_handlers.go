@@ -0,0 +1,162 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"github.com/brixen96/video-storage-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+var autoTagService *services.AutoTagService
+
+// ensureAutoTagService initializes the service if needed
+func ensureAutoTagService() *services.AutoTagService {
+	if autoTagService == nil {
+		autoTagService = services.NewAutoTagService()
+	}
+	return autoTagService
+}
+
+// getAutoTagRules handles GET /api/v1/auto-tag-rules
+func getAutoTagRules(c *gin.Context) {
+	svc := ensureAutoTagService()
+
+	rules, err := svc.GetRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve auto-tag rules", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(rules, "Auto-tag rules retrieved successfully"))
+}
+
+// getAutoTagRule handles GET /api/v1/auto-tag-rules/:id
+func getAutoTagRule(c *gin.Context) {
+	svc := ensureAutoTagService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid auto-tag rule ID", err.Error()))
+		return
+	}
+
+	rule, err := svc.GetRule(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Auto-tag rule not found", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(rule, "Auto-tag rule retrieved successfully"))
+}
+
+// createAutoTagRule handles POST /api/v1/auto-tag-rules. A rule's only action
+// is adding tag_ids; it can't set a category, which videos don't have.
+func createAutoTagRule(c *gin.Context) {
+	svc := ensureAutoTagService()
+
+	var create models.AutoTagRuleCreate
+	if err := c.ShouldBindJSON(&create); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	rule, err := svc.CreateRule(&create)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to create auto-tag rule", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(rule, "Auto-tag rule created successfully"))
+}
+
+// updateAutoTagRule handles PUT /api/v1/auto-tag-rules/:id
+func updateAutoTagRule(c *gin.Context) {
+	svc := ensureAutoTagService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid auto-tag rule ID", err.Error()))
+		return
+	}
+
+	var update models.AutoTagRuleUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	rule, err := svc.UpdateRule(id, &update)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to update auto-tag rule", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(rule, "Auto-tag rule updated successfully"))
+}
+
+// deleteAutoTagRule handles DELETE /api/v1/auto-tag-rules/:id
+func deleteAutoTagRule(c *gin.Context) {
+	svc := ensureAutoTagService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid auto-tag rule ID", err.Error()))
+		return
+	}
+
+	if err := svc.DeleteRule(id); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Failed to delete auto-tag rule", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Auto-tag rule deleted successfully"))
+}
+
+// previewAutoTagRules handles POST /api/v1/auto-tag-rules/preview
+func previewAutoTagRules(c *gin.Context) {
+	svc := ensureAutoTagService()
+
+	var req models.AutoTagRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	preview, err := svc.Preview(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to preview auto-tag rules", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(preview, "Auto-tag preview completed successfully"))
+}
+
+// applyAutoTagRules handles POST /api/v1/auto-tag-rules/apply
+func applyAutoTagRules(c *gin.Context) {
+	svc := ensureAutoTagService()
+
+	var req models.AutoTagRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	// Unknown rules are reported now rather than from the background run
+	rules, err := svc.SelectRules(req.RuleIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to apply auto-tag rules", err.Error()))
+		return
+	}
+
+	// Rules can match every video, so run in background; progress shows as an activity
+	go func() {
+		if _, err := svc.Run(rules, req.VideoIDs); err != nil {
+			log.Printf("Failed to apply auto-tag rules: %v", err)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse(nil, "Auto-tagging started"))
+}
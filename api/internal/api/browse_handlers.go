@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -43,6 +44,10 @@ func browseLibrary(c *gin.Context) {
 	// Browse
 	response, err := svc.BrowseLibrary(id, path, extractMetadata)
 	if err != nil {
+		if errors.Is(err, services.ErrLibraryOffline) {
+			c.JSON(http.StatusServiceUnavailable, models.ErrorResponseMsg("Library is offline", err.Error()))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg(
 			"Failed to browse library",
 			err.Error(),
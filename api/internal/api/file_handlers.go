@@ -213,6 +213,6 @@ func deleteFile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.SuccessResponse(
 		gin.H{"file_path": req.FilePath},
-		"File deleted successfully",
+		"File moved to trash",
 	))
 }
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/brixen96/video-storage-ai/internal/models"
 	"github.com/brixen96/video-storage-ai/internal/services"
@@ -148,6 +150,65 @@ func updateLibrary(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(library, "Library updated successfully"))
 }
 
+// getLibrarySettings retrieves the scan settings of a library
+func getLibrarySettings(c *gin.Context) {
+	svc := ensureLibraryService()
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid library ID",
+			err.Error(),
+		))
+		return
+	}
+
+	settings, err := svc.GetSettings(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg(
+			"Library not found",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(settings, "Library settings retrieved successfully"))
+}
+
+// updateLibrarySettings updates the scan settings of a library
+func updateLibrarySettings(c *gin.Context) {
+	svc := ensureLibraryService()
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid library ID",
+			err.Error(),
+		))
+		return
+	}
+
+	var update models.LibrarySettingsUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	settings, err := svc.UpdateSettings(id, &update)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Failed to update library settings",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(settings, "Library settings updated successfully"))
+}
+
 // deleteLibrary deletes a library
 func deleteLibrary(c *gin.Context) {
 	svc := ensureLibraryService()
@@ -172,3 +233,86 @@ func deleteLibrary(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Library deleted successfully"))
 }
+
+// checkLibraryStatus re-probes a library's root and returns its availability
+func checkLibraryStatus(c *gin.Context) {
+	svc := ensureLibraryService()
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid library ID",
+			err.Error(),
+		))
+		return
+	}
+
+	library, err := svc.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg(
+			"Library not found",
+			err.Error(),
+		))
+		return
+	}
+
+	if _, err := svc.CheckStatus(library); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg(
+			"Failed to check library status",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(library, fmt.Sprintf("Library is %s", library.Status)))
+}
+
+// getLibraryStatsHistory returns a library's daily stats snapshots for charting.
+// from and to are YYYY-MM-DD and default to the last 90 days; bucket is day, week or month.
+func getLibraryStatsHistory(c *gin.Context) {
+	svc := ensureLibraryService()
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid library ID",
+			err.Error(),
+		))
+		return
+	}
+
+	if _, err := svc.GetByID(id); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg(
+			"Library not found",
+			err.Error(),
+		))
+		return
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -90)
+	for param, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+				fmt.Sprintf("Invalid %s date, expected YYYY-MM-DD", param),
+				err.Error(),
+			))
+			return
+		}
+		*dst = parsed
+	}
+
+	history, err := svc.GetStatsHistory(id, from, to, c.Query("bucket"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Failed to retrieve stats history",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(history, "Stats history retrieved successfully"))
+}
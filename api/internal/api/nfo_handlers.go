@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"github.com/brixen96/video-storage-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+var nfoService *services.NFOService
+
+// ensureNFOService initializes the service if needed
+func ensureNFOService() *services.NFOService {
+	if nfoService == nil {
+		nfoService = services.NewNFOService()
+	}
+	return nfoService
+}
+
+// importNFO handles POST /api/v1/nfo/import
+func importNFO(c *gin.Context) {
+	svc := ensureNFOService()
+
+	var req models.NFOImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	result, err := svc.Import(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to import NFO files", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, "NFO import completed"))
+}
+
+// exportNFO handles POST /api/v1/nfo/export
+func exportNFO(c *gin.Context) {
+	svc := ensureNFOService()
+
+	var req models.NFOExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	result, err := svc.Export(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to export NFO files", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, "NFO export completed"))
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"github.com/brixen96/video-storage-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+var operationService *services.OperationService
+
+// ensureOperationService initializes the service if needed
+func ensureOperationService() *services.OperationService {
+	if operationService == nil {
+		operationService = services.NewOperationService()
+	}
+	return operationService
+}
+
+// getOperations handles GET /api/v1/operations
+func getOperations(c *gin.Context) {
+	svc := ensureOperationService()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	operations, err := svc.GetAll(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve operations", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(operations, "Operations retrieved successfully"))
+}
+
+// getOperation handles GET /api/v1/operations/:id
+func getOperation(c *gin.Context) {
+	svc := ensureOperationService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid operation ID", err.Error()))
+		return
+	}
+
+	operation, err := svc.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Operation not found", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(operation, "Operation retrieved successfully"))
+}
+
+// undoOperation handles POST /api/v1/operations/:id/undo
+func undoOperation(c *gin.Context) {
+	svc := ensureOperationService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid operation ID", err.Error()))
+		return
+	}
+
+	operation, err := svc.Undo(id)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrOperationConflict):
+			status = http.StatusConflict
+		case errors.Is(err, services.ErrLibraryOffline):
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, models.ErrorResponseMsg("Failed to undo operation", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(operation, "Operation undone"))
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"github.com/brixen96/video-storage-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+var organizerService *services.OrganizerService
+
+// ensureOrganizerService initializes the service if needed
+func ensureOrganizerService() *services.OrganizerService {
+	if organizerService == nil {
+		organizerService = services.NewOrganizerService()
+	}
+	return organizerService
+}
+
+// planOrganize handles POST /api/v1/organize/plan
+func planOrganize(c *gin.Context) {
+	svc := ensureOrganizerService()
+
+	var req models.OrganizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	plan, err := svc.Plan(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to plan organize", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(plan, "Organize plan created"))
+}
+
+// executeOrganize handles POST /api/v1/organize/execute
+func executeOrganize(c *gin.Context) {
+	svc := ensureOrganizerService()
+
+	var req models.OrganizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	run, err := svc.Execute(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to organize videos", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(run, fmt.Sprintf("Moved %d videos (%d failed)", run.Moved, run.Failed)))
+}
+
+// getOrganizeRuns handles GET /api/v1/organize/runs
+func getOrganizeRuns(c *gin.Context) {
+	svc := ensureOrganizerService()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	runs, err := svc.GetRuns(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve organize runs", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(runs, "Organize runs retrieved successfully"))
+}
+
+// getOrganizeRun handles GET /api/v1/organize/runs/:id
+func getOrganizeRun(c *gin.Context) {
+	svc := ensureOrganizerService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid organize run ID", err.Error()))
+		return
+	}
+
+	run, err := svc.GetRun(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Organize run not found", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(run, "Organize run retrieved successfully"))
+}
+
+// revertOrganizeRun handles POST /api/v1/organize/runs/:id/revert
+func revertOrganizeRun(c *gin.Context) {
+	svc := ensureOrganizerService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid organize run ID", err.Error()))
+		return
+	}
+
+	run, err := svc.Revert(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to revert organize run", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(run, "Organize run reverted"))
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"github.com/brixen96/video-storage-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+var filenameParserService *services.FilenameParserService
+
+// ensureFilenameParserService initializes the service if needed
+func ensureFilenameParserService() *services.FilenameParserService {
+	if filenameParserService == nil {
+		filenameParserService = services.NewFilenameParserService()
+	}
+	return filenameParserService
+}
+
+// getParseRules handles GET /api/v1/parse-rules
+func getParseRules(c *gin.Context) {
+	svc := ensureFilenameParserService()
+
+	// library_id narrows the list to the rules that apply to that library
+	var libraryID int64
+	if raw := c.Query("library_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid library ID", err.Error()))
+			return
+		}
+		libraryID = id
+	}
+
+	rules, err := svc.GetRules(libraryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve parse rules", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(rules, "Parse rules retrieved successfully"))
+}
+
+// createParseRule handles POST /api/v1/parse-rules
+func createParseRule(c *gin.Context) {
+	svc := ensureFilenameParserService()
+
+	var create models.FilenameParseRuleCreate
+	if err := c.ShouldBindJSON(&create); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	rule, err := svc.CreateRule(&create)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to create parse rule", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(rule, "Parse rule created successfully"))
+}
+
+// updateParseRule handles PUT /api/v1/parse-rules/:id
+func updateParseRule(c *gin.Context) {
+	svc := ensureFilenameParserService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid parse rule ID", err.Error()))
+		return
+	}
+
+	var update models.FilenameParseRuleUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	rule, err := svc.UpdateRule(id, &update)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to update parse rule", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(rule, "Parse rule updated successfully"))
+}
+
+// deleteParseRule handles DELETE /api/v1/parse-rules/:id
+func deleteParseRule(c *gin.Context) {
+	svc := ensureFilenameParserService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid parse rule ID", err.Error()))
+		return
+	}
+
+	if err := svc.DeleteRule(id); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Failed to delete parse rule", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Parse rule deleted successfully"))
+}
+
+// dryRunParseRules handles POST /api/v1/parse-rules/dry-run
+func dryRunParseRules(c *gin.Context) {
+	svc := ensureFilenameParserService()
+
+	var req models.FilenameParseDryRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	results, err := svc.DryRun(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to run parse rules", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(results, "Dry run completed successfully"))
+}
+
+// applyParseRules handles POST /api/v1/parse-rules/apply
+func applyParseRules(c *gin.Context) {
+	svc := ensureFilenameParserService()
+
+	var req models.FilenameParseApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	// Refuse up front rather than failing in the background
+	if _, err := ensureLibraryService().GetByID(req.LibraryID); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Library not found", err.Error()))
+		return
+	}
+
+	// Applying can touch every video in the library, so run in background
+	go func() {
+		if err := svc.ApplyToLibrary(&req); err != nil {
+			log.Printf("Failed to apply parse rules to library %d: %v", req.LibraryID, err)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse(nil, "Filename parsing started"))
+}
@@ -607,6 +607,102 @@ func removePerformerTag(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Master tag removed from performer"))
 }
 
+// getPerformerAliases retrieves all aliases for a performer
+func getPerformerAliases(c *gin.Context) {
+	svc := ensurePerformerService()
+
+	idStr := c.Param("id")
+	performerID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid performer ID",
+			err.Error(),
+		))
+		return
+	}
+
+	aliases, err := svc.GetAliases(performerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg(
+			"Failed to retrieve performer aliases",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(aliases, "Performer aliases retrieved successfully"))
+}
+
+// addPerformerAlias adds an alias to a performer
+func addPerformerAlias(c *gin.Context) {
+	svc := ensurePerformerService()
+
+	idStr := c.Param("id")
+	performerID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid performer ID",
+			err.Error(),
+		))
+		return
+	}
+
+	var create models.PerformerAliasCreate
+	if err := c.ShouldBindJSON(&create); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	alias, err := svc.AddAlias(performerID, create.Alias)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Failed to add performer alias",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(alias, "Alias added to performer"))
+}
+
+// removePerformerAlias removes an alias from a performer
+func removePerformerAlias(c *gin.Context) {
+	svc := ensurePerformerService()
+
+	idStr := c.Param("id")
+	performerID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid performer ID",
+			err.Error(),
+		))
+		return
+	}
+
+	aliasIDStr := c.Param("aliasId")
+	aliasID, err := strconv.ParseInt(aliasIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg(
+			"Invalid alias ID",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := svc.DeleteAlias(performerID, aliasID); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg(
+			"Failed to remove performer alias",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Alias removed from performer"))
+}
+
 // syncPerformerTags syncs a performer's master tags to all their videos
 func syncPerformerTags(c *gin.Context) {
 	svc := ensurePerformerService()
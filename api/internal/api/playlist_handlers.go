@@ -0,0 +1,290 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"github.com/brixen96/video-storage-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+var playlistService *services.PlaylistService
+
+// ensurePlaylistService initializes the service if needed
+func ensurePlaylistService() *services.PlaylistService {
+	if playlistService == nil {
+		playlistService = services.NewPlaylistService()
+	}
+	return playlistService
+}
+
+// playlistIDs parses the playlist ID and, when the route has one, the item ID
+func playlistIDs(c *gin.Context) (int64, int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid playlist ID", err.Error()))
+		return 0, 0, false
+	}
+	if c.Param("itemId") == "" {
+		return id, 0, true
+	}
+	itemID, err := strconv.ParseInt(c.Param("itemId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid playlist item ID", err.Error()))
+		return 0, 0, false
+	}
+	return id, itemID, true
+}
+
+// getPlaylists handles GET /api/v1/playlists
+func getPlaylists(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	playlists, err := svc.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve playlists", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(playlists, "Playlists retrieved successfully"))
+}
+
+// getPlaylist handles GET /api/v1/playlists/:id
+func getPlaylist(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	id, _, ok := playlistIDs(c)
+	if !ok {
+		return
+	}
+
+	playlist, err := svc.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Playlist not found", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(playlist, "Playlist retrieved successfully"))
+}
+
+// createPlaylist handles POST /api/v1/playlists
+func createPlaylist(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	var create models.PlaylistCreate
+	if err := c.ShouldBindJSON(&create); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	playlist, err := svc.Create(&create)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to create playlist", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(playlist, "Playlist created successfully"))
+}
+
+// updatePlaylist handles PUT /api/v1/playlists/:id
+func updatePlaylist(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	id, _, ok := playlistIDs(c)
+	if !ok {
+		return
+	}
+
+	var update models.PlaylistUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	playlist, err := svc.Update(id, &update)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to update playlist", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(playlist, "Playlist updated successfully"))
+}
+
+// deletePlaylist handles DELETE /api/v1/playlists/:id
+func deletePlaylist(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	id, _, ok := playlistIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := svc.Delete(id); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Failed to delete playlist", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Playlist deleted successfully"))
+}
+
+// addPlaylistItems handles POST /api/v1/playlists/:id/items
+func addPlaylistItems(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	id, _, ok := playlistIDs(c)
+	if !ok {
+		return
+	}
+
+	var req models.PlaylistAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+	if len(req.VideoIDs) == 0 && req.Query == nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", "video_ids or query is required"))
+		return
+	}
+
+	result, err := svc.AddVideos(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to add videos to playlist", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, fmt.Sprintf("Added %d videos to playlist", result.Added)))
+}
+
+// removePlaylistItem handles DELETE /api/v1/playlists/:id/items/:itemId
+func removePlaylistItem(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	id, itemID, ok := playlistIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := svc.RemoveItem(id, itemID); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Failed to remove playlist item", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Playlist item removed successfully"))
+}
+
+// movePlaylistItem handles PUT /api/v1/playlists/:id/items/:itemId/position
+func movePlaylistItem(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	id, itemID, ok := playlistIDs(c)
+	if !ok {
+		return
+	}
+
+	var req models.PlaylistMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	if err := svc.MoveItem(id, itemID, *req.Position); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Failed to move playlist item", err.Error()))
+		return
+	}
+
+	playlist, err := svc.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve playlist", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(playlist, "Playlist item moved successfully"))
+}
+
+// getPlaylistNext handles GET /api/v1/playlists/:id/next?after=<videoId>&loop=true
+func getPlaylistNext(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	id, _, ok := playlistIDs(c)
+	if !ok {
+		return
+	}
+
+	var after int64
+	if raw := c.Query("after"); raw != "" {
+		var err error
+		if after, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid after video ID", err.Error()))
+			return
+		}
+	}
+	loop := c.Query("loop") == "true"
+
+	item, err := svc.Next(id, after, loop)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Failed to find next playlist item", err.Error()))
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusOK, models.SuccessResponse(nil, "End of playlist"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(item, "Next playlist item retrieved successfully"))
+}
+
+// unsafeFilenameChars are replaced when a playlist name becomes a download name
+var unsafeFilenameChars = regexp.MustCompile(`[^\w\- ]+`)
+
+// exportPlaylist handles GET /api/v1/playlists/:id/export?format=m3u8|xspf
+func exportPlaylist(c *gin.Context) {
+	svc := ensurePlaylistService()
+
+	id, _, ok := playlistIDs(c)
+	if !ok {
+		return
+	}
+
+	// Stream URLs point back at this server as the client reached it
+	scheme := "http"
+	if c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	baseURL := scheme + "://" + c.Request.Host
+
+	playlist, err := svc.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Playlist not found", err.Error()))
+		return
+	}
+	filename := strings.TrimSpace(unsafeFilenameChars.ReplaceAllString(playlist.Name, "_"))
+	if filename == "" {
+		filename = "playlist"
+	}
+
+	var body []byte
+	var contentType string
+	switch format := strings.ToLower(c.DefaultQuery("format", "m3u8")); format {
+	case "m3u8", "m3u":
+		m3u, exportErr := svc.ExportM3U8(id, baseURL)
+		body, err = []byte(m3u), exportErr
+		contentType, filename = "application/vnd.apple.mpegurl", filename+".m3u8"
+	case "xspf":
+		body, err = svc.ExportXSPF(id, baseURL)
+		contentType, filename = "application/xspf+xml", filename+".xspf"
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid export format", fmt.Sprintf("unknown format %q (use m3u8 or xspf)", format)))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to export playlist", err.Error()))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, contentType, body)
+}
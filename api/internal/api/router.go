@@ -70,6 +70,10 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			libraries.GET("/:id/stream", streamVideo)                  // Stream video file
 			libraries.POST("", createLibrary)                          // Create library
 			libraries.PUT("/:id", updateLibrary)                       // Update library
+			libraries.GET("/:id/settings", getLibrarySettings)         // Get library scan settings
+			libraries.PUT("/:id/settings", updateLibrarySettings)      // Update library scan settings
+			libraries.POST("/:id/check-status", checkLibraryStatus)    // Re-probe whether the library root is online
+			libraries.GET("/:id/stats/history", getLibraryStatsHistory) // Daily stats snapshots for growth charts
 			libraries.DELETE("/:id", deleteLibrary)                    // Delete library
 			libraries.POST("/:id/generate-thumbnails", generateThumbnailsForFolder) // Generate thumbnails for folder
 		}
@@ -79,10 +83,15 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		{
 			videos.GET("", getVideos)                              // List all videos
 			videos.GET("/:id", getVideo)                           // Get single video
+			videos.GET("/:id/related", getRelatedVideos)           // Videos like this one, with reasons
 			videos.POST("", createVideo)                           // Create video entry
 			videos.PUT("/:id", updateVideo)                        // Update video
+			videos.PATCH("/bulk", bulkUpdateVideos)                // Apply the same edit to many videos
 			videos.DELETE("/:id", deleteVideo)                     // Delete video
+			videos.DELETE("/missing", purgeMissingVideos)          // Purge videos whose files are missing
 			videos.GET("/search", searchVideos)                    // Search videos
+			videos.GET("/by-hash/:hash", getVideosByHash)          // Find videos by oshash or MD5
+			videos.POST("/compute-hashes", computeVideoHashes)     // Compute full content hashes in background
 			videos.POST("/scan", scanVideos)                       // Scan library for videos
 			videos.POST("/scan-all-parallel", scanAllVideosParallel) // Scan all libraries in parallel
 			videos.POST("/generate-previews", generateAllPreviews) // Generate preview storyboards for all videos
@@ -93,6 +102,61 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			videos.POST("/:id/convert", convertVideoToMP4)         // Convert video to MP4
 		}
 
+		// Saved search endpoints
+		savedSearches := v1.Group("/saved-searches")
+		{
+			savedSearches.GET("", getSavedSearches)                          // List saved searches, pinned first
+			savedSearches.POST("", createSavedSearch)                        // Save a search
+			savedSearches.GET("/:id", getSavedSearch)                        // Get saved search
+			savedSearches.PUT("/:id", updateSavedSearch)                     // Update saved search
+			savedSearches.DELETE("/:id", deleteSavedSearch)                  // Delete saved search
+			savedSearches.GET("/:id/videos", getSavedSearchVideos)           // Run saved search and return a page of videos
+			savedSearches.POST("/refresh-counts", refreshSavedSearchCounts)  // Recount every saved search in background
+		}
+
+		// Playlist endpoints
+		playlists := v1.Group("/playlists")
+		{
+			playlists.GET("", getPlaylists)                                  // List playlists
+			playlists.POST("", createPlaylist)                               // Create playlist
+			playlists.GET("/:id", getPlaylist)                               // Get playlist with its items in order
+			playlists.PUT("/:id", updatePlaylist)                            // Update playlist
+			playlists.DELETE("/:id", deletePlaylist)                         // Delete playlist
+			playlists.POST("/:id/items", addPlaylistItems)                   // Add videos by ID or search
+			playlists.DELETE("/:id/items/:itemId", removePlaylistItem)       // Remove item
+			playlists.PUT("/:id/items/:itemId/position", movePlaylistItem)   // Move item to a new position
+			playlists.GET("/:id/next", getPlaylistNext)                      // Next item for continuous playback
+			playlists.GET("/:id/export", exportPlaylist)                     // Export as M3U8 or XSPF
+		}
+
+		// File organizer endpoints
+		organize := v1.Group("/organize")
+		{
+			organize.POST("/plan", planOrganize)                      // Dry run: where a template would move videos
+			organize.POST("/execute", executeOrganize)                // Move videos and journal the run
+			organize.GET("/runs", getOrganizeRuns)                    // List organize runs
+			organize.GET("/runs/:id", getOrganizeRun)                 // Get organize run with its moves
+			organize.POST("/runs/:id/revert", revertOrganizeRun)      // Move a run's videos back
+		}
+
+		// Trash endpoints
+		trash := v1.Group("/trash")
+		{
+			trash.GET("", getTrash)                           // List trashed files and videos
+			trash.GET("/settings", getTrashSettings)          // Get trash retention
+			trash.PUT("/settings", updateTrashSettings)       // Update trash retention
+			trash.POST("/:id/restore", restoreTrashItem)      // Restore file and video metadata
+			trash.DELETE("/:id", deleteTrashItem)             // Delete permanently
+		}
+
+		// Operation journal endpoints
+		operations := v1.Group("/operations")
+		{
+			operations.GET("", getOperations)                 // List recent bulk operations
+			operations.GET("/:id", getOperation)              // Get operation with its changes
+			operations.POST("/:id/undo", undoOperation)       // Reverse an operation
+		}
+
 		// Conversion endpoints
 		conversion := v1.Group("/conversion")
 		{
@@ -120,6 +184,9 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			performers.POST("/:id/tags", addPerformerTag)           // Add master tag to performer
 			performers.DELETE("/:id/tags/:tagId", removePerformerTag) // Remove master tag from performer
 			performers.POST("/:id/sync-tags", syncPerformerTags)    // Sync master tags to all videos
+			performers.GET("/:id/aliases", getPerformerAliases)     // Get performer aliases
+			performers.POST("/:id/aliases", addPerformerAlias)      // Add alias to performer
+			performers.DELETE("/:id/aliases/:aliasId", removePerformerAlias) // Remove alias from performer
 		}
 
 		// Studios endpoints
@@ -147,12 +214,52 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		// Tags endpoints
 		tags := v1.Group("/tags")
 		{
-			tags.GET("", getTags)          // List all tags
-			tags.GET("/:id", getTag)       // Get single tag
-			tags.POST("", createTag)       // Create tag
-			tags.PUT("/:id", updateTag)    // Update tag
-			tags.DELETE("/:id", deleteTag) // Delete tag
-			tags.POST("/merge", mergeTags) // Merge multiple tags
+			tags.GET("", getTags)            // List all tags
+			tags.GET("/tree", getTagTree)    // Tag hierarchy from its roots
+			tags.GET("/resolve", resolveTag) // Find a tag by name or alias
+			tags.GET("/:id", getTag)         // Get single tag
+			tags.POST("", createTag)         // Create tag
+			tags.PUT("/:id", updateTag)      // Update tag
+			tags.DELETE("/:id", deleteTag)   // Delete tag
+			tags.POST("/merge", mergeTags)   // Merge multiple tags
+		}
+
+		// Filename parse rules endpoints
+		parseRules := v1.Group("/parse-rules")
+		{
+			parseRules.GET("", getParseRules)              // List parse rules (optionally for a library)
+			parseRules.POST("", createParseRule)           // Create parse rule
+			parseRules.PUT("/:id", updateParseRule)        // Update parse rule
+			parseRules.DELETE("/:id", deleteParseRule)     // Delete parse rule
+			parseRules.POST("/dry-run", dryRunParseRules)  // Show what files would parse to
+			parseRules.POST("/apply", applyParseRules)     // Apply parse rules to a library's videos
+		}
+
+		// Auto-tag rules endpoints
+		autoTagRules := v1.Group("/auto-tag-rules")
+		{
+			autoTagRules.GET("", getAutoTagRules)               // List auto-tag rules in run order
+			autoTagRules.GET("/:id", getAutoTagRule)            // Get single auto-tag rule
+			autoTagRules.POST("", createAutoTagRule)            // Create auto-tag rule
+			autoTagRules.PUT("/:id", updateAutoTagRule)         // Update auto-tag rule
+			autoTagRules.DELETE("/:id", deleteAutoTagRule)      // Delete auto-tag rule
+			autoTagRules.POST("/preview", previewAutoTagRules)  // Show which videos would gain which tags
+			autoTagRules.POST("/apply", applyAutoTagRules)      // Apply rules as a background activity
+		}
+
+		// Storage endpoints
+		storage := v1.Group("/storage")
+		{
+			storage.GET("/disk-usage", getDiskUsage)          // Capacity of library and asset filesystems
+			storage.GET("/thresholds", getDiskThresholds)     // Get low disk space alert thresholds
+			storage.PUT("/thresholds", updateDiskThresholds)  // Update low disk space alert thresholds
+		}
+
+		// NFO sidecar endpoints
+		nfo := v1.Group("/nfo")
+		{
+			nfo.POST("/import", importNFO) // Read NFO files next to videos into the database
+			nfo.POST("/export", exportNFO) // Write NFO files for videos and performers
 		}
 
 		// Activity Monitor endpoints
@@ -163,6 +270,7 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			activity.GET("/status", getActivityStatus)   // Get current running tasks
 			activity.GET("/stats", getActivityStats)     // Get statistics by type
 			activity.GET("/:id", getActivity)            // Get single activity
+			activity.GET("/:id/tree", getActivityTree)   // Get activity with nested children
 			activity.POST("", createActivity)            // Create activity log
 			activity.PUT("/:id", updateActivity)         // Update activity
 			activity.DELETE("/:id", deleteActivity)      // Delete activity
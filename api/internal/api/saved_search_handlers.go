@@ -0,0 +1,176 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"github.com/brixen96/video-storage-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+var savedSearchService *services.SavedSearchService
+
+// ensureSavedSearchService initializes the service if needed
+func ensureSavedSearchService() *services.SavedSearchService {
+	if savedSearchService == nil {
+		savedSearchService = services.NewSavedSearchService()
+	}
+	return savedSearchService
+}
+
+// getSavedSearches handles GET /api/v1/saved-searches
+func getSavedSearches(c *gin.Context) {
+	svc := ensureSavedSearchService()
+
+	searches, err := svc.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve saved searches", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(searches, "Saved searches retrieved successfully"))
+}
+
+// getSavedSearch handles GET /api/v1/saved-searches/:id
+func getSavedSearch(c *gin.Context) {
+	svc := ensureSavedSearchService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid saved search ID", err.Error()))
+		return
+	}
+
+	search, err := svc.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Saved search not found", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(search, "Saved search retrieved successfully"))
+}
+
+// createSavedSearch handles POST /api/v1/saved-searches
+func createSavedSearch(c *gin.Context) {
+	svc := ensureSavedSearchService()
+
+	var create models.SavedSearchCreate
+	if err := c.ShouldBindJSON(&create); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	search, err := svc.Create(&create)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to create saved search", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(search, "Saved search created successfully"))
+}
+
+// updateSavedSearch handles PUT /api/v1/saved-searches/:id
+func updateSavedSearch(c *gin.Context) {
+	svc := ensureSavedSearchService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid saved search ID", err.Error()))
+		return
+	}
+
+	var update models.SavedSearchUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	search, err := svc.Update(id, &update)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to update saved search", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(search, "Saved search updated successfully"))
+}
+
+// deleteSavedSearch handles DELETE /api/v1/saved-searches/:id
+func deleteSavedSearch(c *gin.Context) {
+	svc := ensureSavedSearchService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid saved search ID", err.Error()))
+		return
+	}
+
+	if err := svc.Delete(id); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Failed to delete saved search", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Saved search deleted successfully"))
+}
+
+// getSavedSearchVideos handles GET /api/v1/saved-searches/:id/videos
+func getSavedSearchVideos(c *gin.Context) {
+	svc := ensureSavedSearchService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid saved search ID", err.Error()))
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page <= 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 {
+		limit = 20
+	}
+
+	facetNames, err := services.ParseFacetNames(c.Query("facets"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid facets", err.Error()))
+		return
+	}
+
+	search, videos, total, err := svc.Execute(id, page, limit)
+	if respondVideoQueryError(c, err) {
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to run saved search %d: %v", id, err)
+		c.JSON(http.StatusNotFound, models.ErrorResponseMsg("Failed to run saved search", err.Error()))
+		return
+	}
+
+	response := models.NewPaginatedResponse(videos, page, limit, int64(total))
+	if len(facetNames) > 0 {
+		response.Facets, err = ensureVideoService().GetFacets(&search.Query, facetNames)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to count facets", err.Error()))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// refreshSavedSearchCounts handles POST /api/v1/saved-searches/refresh-counts
+func refreshSavedSearchCounts(c *gin.Context) {
+	svc := ensureSavedSearchService()
+
+	// Each count runs the full query, so recount in the background
+	go func() {
+		if err := svc.RefreshAllCounts(); err != nil {
+			log.Printf("Failed to refresh saved search counts: %v", err)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse(nil, "Saved search counts are being refreshed"))
+}
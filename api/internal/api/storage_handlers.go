@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// getDiskUsage handles GET /api/v1/storage/disk-usage
+func getDiskUsage(c *gin.Context) {
+	svc := ensureLibraryService()
+
+	usages, err := svc.GetDiskUsage()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to probe disk usage", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(usages, "Disk usage retrieved successfully"))
+}
+
+// getDiskThresholds handles GET /api/v1/storage/thresholds
+func getDiskThresholds(c *gin.Context) {
+	svc := ensureLibraryService()
+
+	thresholds, err := svc.GetDiskThresholds()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve disk thresholds", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(thresholds, "Disk thresholds retrieved successfully"))
+}
+
+// updateDiskThresholds handles PUT /api/v1/storage/thresholds
+func updateDiskThresholds(c *gin.Context) {
+	svc := ensureLibraryService()
+
+	var update models.DiskSpaceThresholdsUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	thresholds, err := svc.UpdateDiskThresholds(&update)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to update disk thresholds", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(thresholds, "Disk thresholds updated successfully"))
+}
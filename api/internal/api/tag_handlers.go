@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -21,10 +22,21 @@ func ensureTagService() *services.TagService {
 }
 
 // getTags handles GET /api/v1/tags
+// Supports ?parent_id= to list only that tag's descendants
 func getTags(c *gin.Context) {
 	svc := ensureTagService()
 
-	tags, err := svc.GetAll()
+	var parentID int64
+	if param := c.Query("parent_id"); param != "" {
+		id, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent tag ID"})
+			return
+		}
+		parentID = id
+	}
+
+	tags, err := svc.GetAll(parentID)
 	if err != nil {
 		log.Printf("Failed to get tags: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags"})
@@ -34,6 +46,41 @@ func getTags(c *gin.Context) {
 	c.JSON(http.StatusOK, tags)
 }
 
+// getTagTree handles GET /api/v1/tags/tree
+func getTagTree(c *gin.Context) {
+	svc := ensureTagService()
+
+	tree, err := svc.GetTree()
+	if err != nil {
+		log.Printf("Failed to get tag tree: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tag tree"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// resolveTag handles GET /api/v1/tags/resolve?name=
+// Finds the tag with a name or alias
+func resolveTag(c *gin.Context) {
+	svc := ensureTagService()
+
+	name := c.Query("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	tag, err := svc.Resolve(name)
+	if err != nil {
+		log.Printf("Failed to resolve tag %q: %v", name, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
 // getTag handles GET /api/v1/tags/:id
 func getTag(c *gin.Context) {
 	svc := ensureTagService()
@@ -67,6 +114,10 @@ func createTag(c *gin.Context) {
 	tag, err := svc.Create(&create)
 	if err != nil {
 		log.Printf("Failed to create tag: %v", err)
+		if errors.Is(err, services.ErrInvalidTag) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
 		return
 	}
@@ -93,6 +144,10 @@ func updateTag(c *gin.Context) {
 	tag, err := svc.Update(id, &update)
 	if err != nil {
 		log.Printf("Failed to update tag %d: %v", id, err)
+		if errors.Is(err, services.ErrInvalidTag) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag"})
 		return
 	}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"github.com/brixen96/video-storage-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+var trashService *services.TrashService
+
+// ensureTrashService initializes the service if needed
+func ensureTrashService() *services.TrashService {
+	if trashService == nil {
+		trashService = services.NewTrashService()
+	}
+	return trashService
+}
+
+// getTrash handles GET /api/v1/trash
+func getTrash(c *gin.Context) {
+	svc := ensureTrashService()
+
+	var libraryID int64
+	if raw := c.Query("library_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid library ID", err.Error()))
+			return
+		}
+		libraryID = id
+	}
+
+	items, err := svc.GetAll(libraryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve trash", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(items, "Trash retrieved successfully"))
+}
+
+// restoreTrashItem handles POST /api/v1/trash/:id/restore
+func restoreTrashItem(c *gin.Context) {
+	svc := ensureTrashService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid trash item ID", err.Error()))
+		return
+	}
+
+	videoID, err := svc.Restore(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to restore trash item", err.Error()))
+		return
+	}
+
+	var data interface{}
+	if videoID != 0 {
+		data = gin.H{"video_id": videoID}
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(data, "Trash item restored successfully"))
+}
+
+// deleteTrashItem handles DELETE /api/v1/trash/:id
+func deleteTrashItem(c *gin.Context) {
+	svc := ensureTrashService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid trash item ID", err.Error()))
+		return
+	}
+
+	if err := svc.Delete(id); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to delete trash item", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Trash item deleted permanently"))
+}
+
+// getTrashSettings handles GET /api/v1/trash/settings
+func getTrashSettings(c *gin.Context) {
+	svc := ensureTrashService()
+
+	settings, err := svc.GetSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponseMsg("Failed to retrieve trash settings", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(settings, "Trash settings retrieved successfully"))
+}
+
+// updateTrashSettings handles PUT /api/v1/trash/settings
+func updateTrashSettings(c *gin.Context) {
+	svc := ensureTrashService()
+
+	var update models.TrashSettingsUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid request body", err.Error()))
+		return
+	}
+
+	settings, err := svc.UpdateSettings(&update)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Failed to update trash settings", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(settings, "Trash settings updated successfully"))
+}
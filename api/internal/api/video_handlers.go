@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -37,20 +38,58 @@ func getVideos(c *gin.Context) {
 		return
 	}
 
+	facetNames, err := services.ParseFacetNames(c.Query("facets"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid facets", err.Error()))
+		return
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
 	// Get videos
-	videos, total, err := svc.GetAll(&query)
+	page, err := svc.GetPage(&query)
+	if respondVideoQueryError(c, err) {
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to get videos: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve videos"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  videos,
-		"total": total,
-		"page":  query.Page,
-		"limit": query.Limit,
-	})
+	response := models.NewPaginatedResponse(page.Videos, query.Page, query.Limit, int64(page.Total))
+	response.Pagination.NextCursor = page.NextCursor
+	response.Pagination.Seed = page.Seed
+	if len(facetNames) > 0 {
+		response.Facets, err = svc.GetFacets(&query, facetNames)
+		if err != nil {
+			log.Printf("Failed to count video facets: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count facets"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondVideoQueryError answers 400 when a structured ?q= query didn't parse or
+// the cursor doesn't fit the search
+func respondVideoQueryError(c *gin.Context, err error) bool {
+	if errors.Is(err, services.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid cursor", err.Error()))
+		return true
+	}
+	var queryErr *services.VideoQueryError
+	if !errors.As(err, &queryErr) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, models.ErrorResponseMsg("Invalid search query", queryErr.Error()))
+	return true
 }
 
 // getVideo handles GET /api/v1/videos/:id
@@ -73,6 +112,41 @@ func getVideo(c *gin.Context) {
 	c.JSON(http.StatusOK, video)
 }
 
+// getRelatedVideos handles GET /api/v1/videos/:id/related
+func getRelatedVideos(c *gin.Context) {
+	svc := ensureVideoService()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	video, err := svc.GetByID(id)
+	if err != nil {
+		log.Printf("Failed to get video %d: %v", id, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video not found"})
+		return
+	}
+
+	related, err := svc.GetRelated(video, limit)
+	if err != nil {
+		log.Printf("Failed to get related videos for %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find related videos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  related,
+		"total": len(related),
+	})
+}
+
 // createVideo handles POST /api/v1/videos
 func createVideo(c *gin.Context) {
 	svc := ensureVideoService()
@@ -122,24 +196,138 @@ func updateVideo(c *gin.Context) {
 	c.JSON(http.StatusOK, video)
 }
 
-// deleteVideo handles DELETE /api/v1/videos/:id
-func deleteVideo(c *gin.Context) {
+// bulkUpdateVideos handles PATCH /api/v1/videos/bulk
+func bulkUpdateVideos(c *gin.Context) {
 	svc := ensureVideoService()
 
+	var update models.VideoBulkUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := svc.BulkUpdate(&update)
+	if err != nil {
+		if respondVideoQueryError(c, err) {
+			return
+		}
+		log.Printf("Failed to bulk update videos: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to update videos: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// deleteVideo handles DELETE /api/v1/videos/:id
+func deleteVideo(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video ID"})
 		return
 	}
 
-	err = svc.Delete(id)
+	// The video and its file go to the trash so they can be restored
+	item, err := ensureTrashService().TrashVideo(id)
 	if err != nil {
 		log.Printf("Failed to delete video %d: %v", id, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete video"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete video: %v", err)})
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Video deleted"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Video moved to trash", "trash_item": item})
+}
+
+// purgeMissingVideos handles DELETE /api/v1/videos/missing
+func purgeMissingVideos(c *gin.Context) {
+	svc := ensureVideoService()
+
+	var libraryID int64
+	if raw := c.Query("library_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid library ID"})
+			return
+		}
+		libraryID = id
+	}
+
+	olderThanDays := 0
+	if raw := c.Query("older_than_days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid older_than_days"})
+			return
+		}
+		olderThanDays = days
+	}
+
+	purged, err := svc.PurgeMissing(libraryID, olderThanDays)
+	if err != nil {
+		if errors.Is(err, services.ErrLibraryOffline) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Failed to purge missing videos: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge missing videos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Purged %d missing videos", purged),
+		"purged":  purged,
+	})
+}
+
+// getVideosByHash handles GET /api/v1/videos/by-hash/:hash
+func getVideosByHash(c *gin.Context) {
+	svc := ensureVideoService()
+
+	videos, err := svc.GetByHash(c.Param("hash"))
+	if err != nil {
+		log.Printf("Failed to get videos by hash: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get videos"})
+		return
+	}
+
+	if len(videos) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No video with this hash"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Video deleted successfully"})
+	c.JSON(http.StatusOK, videos)
+}
+
+// computeVideoHashes handles POST /api/v1/videos/compute-hashes
+func computeVideoHashes(c *gin.Context) {
+	svc := ensureVideoService()
+
+	// library_id is optional; without it every library is hashed
+	var request struct {
+		LibraryID int64 `json:"library_id"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	// Full hashes read every byte, so run in background
+	go func() {
+		if err := svc.ComputeContentHashes(request.LibraryID); err != nil {
+			log.Printf("Failed to compute content hashes: %v", err)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Content hashing started",
+		"status":  "hashing",
+	})
 }
 
 // searchVideos handles GET /api/v1/videos/search
@@ -156,6 +344,9 @@ func searchVideos(c *gin.Context) {
 
 	// Get videos
 	videos, total, err := svc.GetAll(&query)
+	if respondVideoQueryError(c, err) {
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to search videos: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to search videos: %v", err)})
@@ -184,6 +375,18 @@ func scanVideos(c *gin.Context) {
 		return
 	}
 
+	// Refuse up front rather than failing in the background
+	librarySvc := ensureLibraryService()
+	library, err := librarySvc.GetByID(request.LibraryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Library not found"})
+		return
+	}
+	if err := librarySvc.EnsureOnline(library); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Start scanning in background
 	go func() {
 		err := svc.ScanLibrary(request.LibraryID)
@@ -58,10 +58,18 @@ func Initialize(cfg *config.Config) error {
 	}
 
 	// Run migrations
+	if err := dropFullTextTriggers(); err != nil {
+		return err
+	}
 	if err := runMigrations(); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	// Full-text search indexes
+	if err := setupFullTextSearch(); err != nil {
+		return err
+	}
+
 	log.Println("Database connection established")
 	return nil
 }
@@ -547,6 +555,239 @@ func runMigrations() error {
 		`CREATE INDEX IF NOT EXISTS idx_console_logs_source ON console_logs(source)`,
 		`CREATE INDEX IF NOT EXISTS idx_console_logs_level ON console_logs(level)`,
 		`CREATE INDEX IF NOT EXISTS idx_console_logs_created ON console_logs(created_at DESC)`,
+		// Migration 26: Add parent/child hierarchy and item counters to activity_logs
+		`ALTER TABLE activity_logs ADD COLUMN parent_id INTEGER REFERENCES activity_logs(id) ON DELETE CASCADE`,
+		`ALTER TABLE activity_logs ADD COLUMN items_processed INTEGER DEFAULT 0`,
+		`ALTER TABLE activity_logs ADD COLUMN items_total INTEGER DEFAULT 0`,
+		`CREATE INDEX IF NOT EXISTS idx_activity_logs_parent ON activity_logs(parent_id)`,
+		// Migration 27: Add availability status and file fingerprint columns to videos
+		`ALTER TABLE videos ADD COLUMN status TEXT DEFAULT 'available'`,
+		`ALTER TABLE videos ADD COLUMN missing_since DATETIME`,
+		`ALTER TABLE videos ADD COLUMN file_modified_at DATETIME`,
+		`ALTER TABLE videos ADD COLUMN partial_hash TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_status ON videos(status)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_size_hash ON videos(file_size, partial_hash)`,
+		// Migration 28: Create library_settings table for per-library scan rules
+		`CREATE TABLE IF NOT EXISTS library_settings (
+			library_id INTEGER PRIMARY KEY,
+			include_patterns TEXT DEFAULT '[]',
+			exclude_patterns TEXT DEFAULT '[]',
+			extensions TEXT DEFAULT '[]',
+			min_file_size INTEGER DEFAULT 0,
+			min_duration REAL DEFAULT 0,
+			max_depth INTEGER DEFAULT 0,
+			follow_symlinks BOOLEAN DEFAULT 0,
+			auto_thumbnails BOOLEAN DEFAULT 1,
+			auto_previews BOOLEAN DEFAULT 1,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (library_id) REFERENCES libraries(id) ON DELETE CASCADE
+		)`,
+		// Migration 29: Add content hashes to videos for path-independent identity
+		`ALTER TABLE videos ADD COLUMN oshash TEXT`,
+		`ALTER TABLE videos ADD COLUMN md5 TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_oshash ON videos(oshash)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_md5 ON videos(md5)`,
+		// Migration 30: Create filename_parse_rules table for metadata parsing on ingest
+		`CREATE TABLE IF NOT EXISTS filename_parse_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			library_id INTEGER,
+			name TEXT NOT NULL,
+			pattern_type TEXT NOT NULL DEFAULT 'template' CHECK(pattern_type IN ('template', 'regex')),
+			pattern TEXT NOT NULL,
+			priority INTEGER DEFAULT 0,
+			enabled BOOLEAN DEFAULT 1,
+			create_missing BOOLEAN DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (library_id) REFERENCES libraries(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_filename_parse_rules_library ON filename_parse_rules(library_id, priority)`,
+
+		// Migration 31: Track library availability so unmounted drives aren't scanned or purged
+		`ALTER TABLE libraries ADD COLUMN status TEXT DEFAULT 'online'`,
+		`ALTER TABLE libraries ADD COLUMN status_checked_at DATETIME`,
+		`ALTER TABLE libraries ADD COLUMN offline_since DATETIME`,
+
+		// Migration 32: Daily per-library statistics snapshots for growth charts
+		`CREATE TABLE IF NOT EXISTS library_stats_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			library_id INTEGER NOT NULL,
+			snapshot_date DATE NOT NULL,
+			video_count INTEGER DEFAULT 0,
+			total_bytes INTEGER DEFAULT 0,
+			total_duration REAL DEFAULT 0,
+			codec_distribution TEXT DEFAULT '{}',
+			resolution_distribution TEXT DEFAULT '{}',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(library_id, snapshot_date),
+			FOREIGN KEY (library_id) REFERENCES libraries(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_library_stats_history_date ON library_stats_history(snapshot_date)`,
+
+		// Migration 33: Saved searches, storing a serialized VideoSearchQuery
+		`CREATE TABLE IF NOT EXISTS saved_searches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT UNIQUE NOT NULL,
+			icon TEXT,
+			pinned BOOLEAN DEFAULT 0,
+			query TEXT NOT NULL DEFAULT '{}',
+			video_count INTEGER,
+			count_updated_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Migration 34: Keyset pagination indexes, matching the sort expressions with id as tie-breaker
+		`CREATE INDEX IF NOT EXISTS idx_videos_keyset_created ON videos(COALESCE(created_at, ''), id)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_keyset_updated ON videos(COALESCE(updated_at, ''), id)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_keyset_title ON videos(COALESCE(title, ''), id)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_keyset_duration ON videos(COALESCE(duration, 0), id)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_keyset_file_size ON videos(COALESCE(file_size, 0), id)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_keyset_play_count ON videos(COALESCE(play_count, 0), id)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_keyset_rating ON videos(COALESCE(rating, 0), id)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_keyset_last_played ON videos(COALESCE(last_played_at, ''), id)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_keyset_bitrate ON videos(COALESCE(bitrate, 0), id)`,
+
+		// Migration 35: Performer aliases, with a normalized form for accent and case-insensitive lookups.
+		// No foreign key: Migration 21 rebuilds the performers table on every start, which would cascade.
+		`CREATE TABLE IF NOT EXISTS performer_aliases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			performer_id INTEGER NOT NULL,
+			alias TEXT NOT NULL,
+			normalized TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT 'manual',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(performer_id, normalized)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_performer_aliases_normalized ON performer_aliases(normalized)`,
+
+		// Migration 36: Playlists, with items kept at contiguous 0-based positions
+		`CREATE TABLE IF NOT EXISTS playlists (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			description TEXT,
+			cover_video_id INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (cover_video_id) REFERENCES videos(id) ON DELETE SET NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS playlist_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			playlist_id INTEGER NOT NULL,
+			video_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(playlist_id, video_id),
+			FOREIGN KEY (playlist_id) REFERENCES playlists(id) ON DELETE CASCADE,
+			FOREIGN KEY (video_id) REFERENCES videos(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_playlist_items_position ON playlist_items(playlist_id, position)`,
+		`CREATE INDEX IF NOT EXISTS idx_playlist_items_video ON playlist_items(video_id)`,
+		// Close the gap an item leaves, including when its video is deleted
+		`CREATE TRIGGER IF NOT EXISTS playlist_items_close_gap AFTER DELETE ON playlist_items BEGIN
+			UPDATE playlist_items SET position = position - 1
+			WHERE playlist_id = OLD.playlist_id AND position > OLD.position;
+		END`,
+		// Migration 37: Journal of file organizer runs, so a run can be reverted
+		`CREATE TABLE IF NOT EXISTS organize_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			template TEXT NOT NULL,
+			moved INTEGER DEFAULT 0,
+			failed INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			reverted_at DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS organize_moves (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id INTEGER NOT NULL,
+			video_id INTEGER NOT NULL,
+			library_id INTEGER NOT NULL,
+			old_path TEXT NOT NULL,
+			new_path TEXT NOT NULL,
+			status TEXT NOT NULL,
+			error TEXT,
+			FOREIGN KEY (run_id) REFERENCES organize_runs(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_organize_moves_run ON organize_moves(run_id)`,
+		// Migration 38: Recycle bin; snapshot holds the video row and its links as JSON
+		`CREATE TABLE IF NOT EXISTS trash_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			library_id INTEGER NOT NULL,
+			original_path TEXT NOT NULL,
+			trash_path TEXT,
+			file_size INTEGER DEFAULT 0,
+			video_id INTEGER,
+			title TEXT,
+			snapshot TEXT,
+			deleted_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_trash_items_deleted ON trash_items(deleted_at)`,
+		// Migration 39: Operation journal; changes holds each change and its previous values as JSON
+		`CREATE TABLE IF NOT EXISTS operations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			change_count INTEGER DEFAULT 0,
+			changes TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			undone_at DATETIME
+		)`,
+		// Migration 40: Tag hierarchy and aliases. No foreign keys to tags: Migration 19
+		// rebuilds tags on every start, and dropping it would take these rows with it.
+		// tag_ancestors is every ancestor of each tag, kept in step with tag_parents
+		// by TagService.
+		`CREATE TABLE IF NOT EXISTS tag_parents (
+			tag_id INTEGER NOT NULL,
+			parent_id INTEGER NOT NULL,
+			PRIMARY KEY (tag_id, parent_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_tag_parents_parent ON tag_parents(parent_id)`,
+		`CREATE TABLE IF NOT EXISTS tag_ancestors (
+			tag_id INTEGER NOT NULL,
+			ancestor_id INTEGER NOT NULL,
+			PRIMARY KEY (tag_id, ancestor_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_tag_ancestors_ancestor ON tag_ancestors(ancestor_id)`,
+		`CREATE TABLE IF NOT EXISTS tag_aliases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tag_id INTEGER NOT NULL,
+			alias TEXT NOT NULL COLLATE NOCASE UNIQUE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_tag_aliases_tag ON tag_aliases(tag_id)`,
+		// Implied ancestor tags are added by the services so they can be journaled
+		`DROP TRIGGER IF EXISTS video_tags_imply_ancestors`,
+
+		// Migration 41: Auto-tag rules. Conditions use the video search syntax;
+		// tag_ids is a JSON array, as tags can't be referenced (see Migration 40).
+		`CREATE TABLE IF NOT EXISTS auto_tag_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			condition TEXT NOT NULL,
+			tag_ids TEXT NOT NULL DEFAULT '[]',
+			priority INTEGER DEFAULT 0,
+			enabled BOOLEAN DEFAULT 1,
+			on_ingest BOOLEAN DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// Migration 42: Drop trash items left behind by deleted libraries
+		`DELETE FROM trash_items WHERE library_id NOT IN (SELECT id FROM libraries)`,
+		// Migration 43: Normalized performer names for indexed accent and case-insensitive lookups.
+		// Migration 21 rebuilds the performers table on every start, so the server refills it afterwards.
+		`ALTER TABLE performers ADD COLUMN normalized_name TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_performers_normalized_name ON performers(normalized_name)`,
+		// Migration 44: Files a library's minimum duration kept out, so rescans don't probe them again
+		`CREATE TABLE IF NOT EXISTS scan_exclusions (
+			library_id INTEGER NOT NULL,
+			file_path TEXT NOT NULL,
+			file_size INTEGER NOT NULL,
+			file_modified_at DATETIME NOT NULL,
+			duration REAL NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (library_id, file_path),
+			FOREIGN KEY (library_id) REFERENCES libraries(id) ON DELETE CASCADE
+		)`,
 	}
 
 	for _, migration := range migrations {
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ftsEnabled reports whether the FTS5 search indexes were set up. FTS5 is only
+// compiled into go-sqlite3 with the sqlite_fts5 build tag; without it search falls
+// back to LIKE queries.
+var ftsEnabled bool
+
+// FTSEnabled reports whether full-text search indexes are available
+func FTSEnabled() bool {
+	return ftsEnabled
+}
+
+// ftsOptions folds case and accents, and the prefix indexes keep short prefix queries fast
+const ftsOptions = `tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3'`
+
+// videoNamesExpr aggregates the names linked to a video through a join table
+func videoNamesExpr(joinTable, nameTable, foreignKey, videoID string) string {
+	return fmt.Sprintf(`COALESCE((SELECT group_concat(n.name, ' ') FROM %s j JOIN %s n ON n.id = j.%s WHERE j.video_id = %s), '')`,
+		joinTable, nameTable, foreignKey, videoID)
+}
+
+// videoPathExpr is a video's path relative to its library root, so the root's own
+// directory names don't match every video
+func videoPathExpr(row string) string {
+	return fmt.Sprintf(`COALESCE((SELECT substr(%[1]s.file_path, length(l.path) + 2) FROM libraries l WHERE l.id = %[1]s.library_id), %[1]s.file_path)`, row)
+}
+
+// postBodyExpr prefers a post's extracted plain text over its HTML
+func postBodyExpr(row string) string {
+	return fmt.Sprintf(`COALESCE(NULLIF(%[1]s.plain_text, ''), %[1]s.content, '')`, row)
+}
+
+// ftsLinks are the relationships whose names are indexed with each video
+var ftsLinks = []struct {
+	column, joinTable, nameTable, foreignKey string
+}{
+	{"performers", "video_performers", "performers", "performer_id"},
+	{"studios", "video_studios", "studios", "studio_id"},
+	{"tags", "video_tags", "tags", "tag_id"},
+}
+
+// fullTextStatements creates the FTS5 tables and the triggers that keep them in sync
+func fullTextStatements() []string {
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS videos_fts USING fts5(title, description, path, performers, studios, tags, ` + ftsOptions + `)`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS threads_fts USING fts5(title, author, ` + ftsOptions + `)`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(author, body, ` + ftsOptions + `)`,
+	}
+
+	videoColumns := func(row string) string {
+		values := []string{row + ".id", row + ".title", "COALESCE(" + row + ".description, '')", videoPathExpr(row)}
+		for _, link := range ftsLinks {
+			values = append(values, videoNamesExpr(link.joinTable, link.nameTable, link.foreignKey, row+".id"))
+		}
+		return strings.Join(values, ", ")
+	}
+
+	statements = append(statements,
+		`CREATE TRIGGER IF NOT EXISTS videos_fts_insert AFTER INSERT ON videos BEGIN
+			INSERT INTO videos_fts (rowid, title, description, path, performers, studios, tags)
+			VALUES (`+videoColumns("NEW")+`);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS videos_fts_update AFTER UPDATE OF title, description, file_path, library_id ON videos BEGIN
+			UPDATE videos_fts SET title = NEW.title, description = COALESCE(NEW.description, ''), path = `+videoPathExpr("NEW")+`
+			WHERE rowid = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS videos_fts_delete AFTER DELETE ON videos BEGIN
+			DELETE FROM videos_fts WHERE rowid = OLD.id;
+		END`,
+	)
+
+	for _, link := range ftsLinks {
+		statements = append(statements,
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_fts_insert AFTER INSERT ON %[1]s BEGIN
+				UPDATE videos_fts SET %[2]s = %[3]s WHERE rowid = NEW.video_id;
+			END`, link.joinTable, link.column, videoNamesExpr(link.joinTable, link.nameTable, link.foreignKey, "NEW.video_id")),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_fts_delete AFTER DELETE ON %[1]s BEGIN
+				UPDATE videos_fts SET %[2]s = %[3]s WHERE rowid = OLD.video_id;
+			END`, link.joinTable, link.column, videoNamesExpr(link.joinTable, link.nameTable, link.foreignKey, "OLD.video_id")),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_fts_rename AFTER UPDATE OF name ON %[1]s BEGIN
+				UPDATE videos_fts SET %[2]s = %[3]s
+				WHERE rowid IN (SELECT video_id FROM %[4]s WHERE %[5]s = NEW.id);
+			END`, link.nameTable, link.column, videoNamesExpr(link.joinTable, link.nameTable, link.foreignKey, "videos_fts.rowid"),
+				link.joinTable, link.foreignKey),
+		)
+	}
+
+	statements = append(statements,
+		`CREATE TRIGGER IF NOT EXISTS scraped_threads_fts_insert AFTER INSERT ON scraped_threads BEGIN
+			INSERT INTO threads_fts (rowid, title, author) VALUES (NEW.id, NEW.title, COALESCE(NEW.author, ''));
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS scraped_threads_fts_update AFTER UPDATE OF title, author ON scraped_threads BEGIN
+			UPDATE threads_fts SET title = NEW.title, author = COALESCE(NEW.author, '') WHERE rowid = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS scraped_threads_fts_delete AFTER DELETE ON scraped_threads BEGIN
+			DELETE FROM threads_fts WHERE rowid = OLD.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS scraped_posts_fts_insert AFTER INSERT ON scraped_posts BEGIN
+			INSERT INTO posts_fts (rowid, author, body) VALUES (NEW.id, COALESCE(NEW.author, ''), `+postBodyExpr("NEW")+`);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS scraped_posts_fts_update AFTER UPDATE OF author, content, plain_text ON scraped_posts BEGIN
+			UPDATE posts_fts SET author = COALESCE(NEW.author, ''), body = `+postBodyExpr("NEW")+` WHERE rowid = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS scraped_posts_fts_delete AFTER DELETE ON scraped_posts BEGIN
+			DELETE FROM posts_fts WHERE rowid = OLD.id;
+		END`,
+	)
+
+	return statements
+}
+
+// backfillStatements index rows that existed before the triggers did
+func backfillStatements() []string {
+	videoColumns := []string{"v.id", "v.title", "COALESCE(v.description, '')", videoPathExpr("v")}
+	for _, link := range ftsLinks {
+		videoColumns = append(videoColumns, videoNamesExpr(link.joinTable, link.nameTable, link.foreignKey, "v.id"))
+	}
+
+	return []string{
+		`INSERT INTO videos_fts (rowid, title, description, path, performers, studios, tags)
+		SELECT ` + strings.Join(videoColumns, ", ") + ` FROM videos v
+		WHERE v.id NOT IN (SELECT rowid FROM videos_fts)`,
+		`INSERT INTO threads_fts (rowid, title, author)
+		SELECT t.id, t.title, COALESCE(t.author, '') FROM scraped_threads t
+		WHERE t.id NOT IN (SELECT rowid FROM threads_fts)`,
+		`INSERT INTO posts_fts (rowid, author, body)
+		SELECT p.id, COALESCE(p.author, ''), ` + postBodyExpr("p") + ` FROM scraped_posts p
+		WHERE p.id NOT IN (SELECT rowid FROM posts_fts)`,
+	}
+}
+
+// dropFullTextTriggers removes the sync triggers before migrations run. Some
+// migrations rebuild tables with DROP and RENAME, which fails while triggers on
+// other tables still reference them. setupFullTextSearch recreates the triggers.
+func dropFullTextTriggers() error {
+	rows, err := DB.Query(`SELECT name FROM sqlite_master WHERE type = 'trigger' AND name LIKE '%\_fts\_%' ESCAPE '\'`)
+	if err != nil {
+		return fmt.Errorf("failed to list full-text triggers: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan trigger name: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to list full-text triggers: %w", err)
+	}
+
+	for _, name := range names {
+		if _, err := DB.Exec(`DROP TRIGGER IF EXISTS "` + name + `"`); err != nil {
+			return fmt.Errorf("failed to drop trigger %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setupFullTextSearch creates the FTS5 indexes, or leaves search on LIKE queries
+// when this build of SQLite has no FTS5
+func setupFullTextSearch() error {
+	for _, statement := range fullTextStatements() {
+		if _, err := DB.Exec(statement); err != nil {
+			if strings.Contains(err.Error(), "no such module: fts5") {
+				log.Println("Full-text search unavailable (build with -tags sqlite_fts5); falling back to LIKE search")
+				return nil
+			}
+			return fmt.Errorf("failed to set up full-text search: %w", err)
+		}
+	}
+
+	for _, statement := range backfillStatements() {
+		if _, err := DB.Exec(statement); err != nil {
+			return fmt.Errorf("failed to backfill full-text search: %w", err)
+		}
+	}
+
+	ftsEnabled = true
+	return nil
+}
@@ -25,16 +25,49 @@ const (
 
 // Activity represents a background task or operation (new schema)
 type Activity struct {
-	ID          int        `json:"id" db:"id"`
-	TaskType    string     `json:"task_type" db:"task_type"`
-	Status      string     `json:"status" db:"status"`
-	Message     string     `json:"message" db:"message"`
-	Details     string     `json:"-" db:"details"`
-	Progress    int        `json:"progress" db:"progress"`
-	StartedAt   time.Time  `json:"started_at" db:"started_at"`
-	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
-	Error       *string    `json:"error,omitempty" db:"error"`
+	ID             int        `json:"id" db:"id"`
+	ParentID       *int       `json:"parent_id,omitempty" db:"parent_id"`
+	TaskType       string     `json:"task_type" db:"task_type"`
+	Status         string     `json:"status" db:"status"`
+	Message        string     `json:"message" db:"message"`
+	Details        string     `json:"-" db:"details"`
+	Progress       int        `json:"progress" db:"progress"`
+	ItemsProcessed int        `json:"items_processed" db:"items_processed"`
+	ItemsTotal     int        `json:"items_total" db:"items_total"`
+	StartedAt      time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	Error          *string    `json:"error,omitempty" db:"error"`
+
+	// Throughput (computed from the item counters, not stored)
+	ItemsPerSecond float64  `json:"items_per_second" db:"-"`
+	ETASeconds     *float64 `json:"eta_seconds,omitempty" db:"-"`
+
+	// Child activities (loaded separately)
+	Children []Activity `json:"children,omitempty" db:"-"`
+}
+
+// ComputeThroughput fills ItemsPerSecond and ETASeconds from the item counters
+// and the time the activity has been running
+func (a *Activity) ComputeThroughput(now time.Time) {
+	a.ItemsPerSecond = 0
+	a.ETASeconds = nil
+
+	end := now
+	if a.CompletedAt != nil {
+		end = *a.CompletedAt
+	}
+	elapsed := end.Sub(a.StartedAt).Seconds()
+	if elapsed <= 0 || a.ItemsProcessed <= 0 {
+		return
+	}
+
+	a.ItemsPerSecond = float64(a.ItemsProcessed) / elapsed
+
+	if a.Status == TaskStatusRunning && a.ItemsTotal > a.ItemsProcessed {
+		eta := float64(a.ItemsTotal-a.ItemsProcessed) / a.ItemsPerSecond
+		a.ETASeconds = &eta
+	}
 }
 
 // ActivityLog represents a background task or operation (legacy schema)
@@ -86,11 +119,13 @@ type ActivityLogCreate struct {
 
 // ActivityLogUpdate represents the data that can be updated in an activity log
 type ActivityLogUpdate struct {
-	Status    *string                `json:"status"`
-	Message   *string                `json:"message"`
-	Progress  *int                   `json:"progress"`
-	Details   map[string]interface{} `json:"details"`
-	Completed bool                   `json:"completed"`
+	Status         *string                `json:"status"`
+	Message        *string                `json:"message"`
+	Progress       *int                   `json:"progress"`
+	ItemsProcessed *int                   `json:"items_processed"`
+	ItemsTotal     *int                   `json:"items_total"`
+	Details        map[string]interface{} `json:"details"`
+	Completed      bool                   `json:"completed"`
 }
 
 // ActivityStatus represents the current status of all activities
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// AutoTagRule tags every video matching its condition. Conditions use the video
+// search syntax, such as res>=2160, duration<5m, path:/VR/ or performer:"Jane Doe".
+// Adding tags is the only action: videos have no category of their own, since a
+// video's category comes from its performers, so a rule can't set one. To mark
+// videos as 3d, add a tag whose category is 3d instead.
+type AutoTagRule struct {
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Condition string    `json:"condition" db:"condition"`
+	TagIDs    []int64   `json:"tag_ids" db:"tag_ids"`   // Tags added to matching videos
+	Priority  int       `json:"priority" db:"priority"` // Lower runs first
+	Enabled   bool      `json:"enabled" db:"enabled"`
+	OnIngest  bool      `json:"on_ingest" db:"on_ingest"` // Also applied to videos as they're added
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// AutoTagRuleCreate represents the data needed to create an auto-tag rule
+type AutoTagRuleCreate struct {
+	Name      string  `json:"name" binding:"required"`
+	Condition string  `json:"condition" binding:"required"`
+	TagIDs    []int64 `json:"tag_ids" binding:"required"`
+	Priority  int     `json:"priority"`
+	Enabled   *bool   `json:"enabled,omitempty"`   // Defaults to true
+	OnIngest  *bool   `json:"on_ingest,omitempty"` // Defaults to true
+}
+
+// AutoTagRuleUpdate represents the data that can be updated
+type AutoTagRuleUpdate struct {
+	Name      *string `json:"name,omitempty"`
+	Condition *string `json:"condition,omitempty"`
+	TagIDs    []int64 `json:"tag_ids,omitempty"`
+	Priority  *int    `json:"priority,omitempty"`
+	Enabled   *bool   `json:"enabled,omitempty"`
+	OnIngest  *bool   `json:"on_ingest,omitempty"`
+}
+
+// AutoTagRunRequest selects the rules and videos for a preview or run
+type AutoTagRunRequest struct {
+	RuleIDs  []int64 `json:"rule_ids,omitempty"`  // Defaults to every enabled rule
+	VideoIDs []int64 `json:"video_ids,omitempty"` // Defaults to every video
+	Limit    int     `json:"limit,omitempty"`     // Preview only; defaults to 100
+}
+
+// AutoTagMatch is a video a run would add tags to, with the rules that matched it
+type AutoTagMatch struct {
+	VideoID  int64    `json:"video_id"`
+	Title    string   `json:"title"`
+	FilePath string   `json:"file_path"`
+	TagIDs   []int64  `json:"tag_ids"`
+	Tags     []string `json:"tags"`
+	Rules    []string `json:"rules"`
+}
+
+// AutoTagPreview lists the videos a run would change
+type AutoTagPreview struct {
+	Videos    []AutoTagMatch `json:"videos"`
+	Total     int            `json:"total"` // Videos that would change, beyond the limit too
+	TagsAdded int            `json:"tags_added"`
+}
+
+// AutoTagResult reports what a run changed
+type AutoTagResult struct {
+	VideosTagged int   `json:"videos_tagged"`
+	TagsAdded    int   `json:"tags_added"`
+	OperationID  int64 `json:"operation_id,omitempty"` // Journal entry that undoes the run
+}
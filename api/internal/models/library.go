@@ -2,6 +2,12 @@ package models
 
 import "time"
 
+// Library availability, probed periodically so unmounted drives don't look empty
+const (
+	LibraryStatusOnline  = "online"
+	LibraryStatusOffline = "offline"
+)
+
 // Library represents a video library/collection
 type Library struct {
 	ID        int64     `json:"id" db:"id"`
@@ -11,6 +17,11 @@ type Library struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 
+	// Availability
+	Status          string     `json:"status" db:"status"` // online, offline
+	StatusCheckedAt *time.Time `json:"status_checked_at,omitempty" db:"status_checked_at"`
+	OfflineSince    *time.Time `json:"offline_since,omitempty" db:"offline_since"`
+
 	// Stats (loaded separately)
 	VideoCount     int `json:"video_count,omitempty" db:"-"`
 	PerformerCount int `json:"performer_count,omitempty" db:"-"`
@@ -36,4 +47,66 @@ type LibraryWithStats struct {
 	TotalSize      int64      `json:"total_size"`
 	LastScanned    *time.Time `json:"last_scanned,omitempty"`
 	ScanInProgress bool       `json:"scan_in_progress"`
+
+	// Capacity of the filesystem holding the library root
+	DiskTotalBytes int64    `json:"disk_total_bytes"`
+	DiskFreeBytes  int64    `json:"disk_free_bytes"`
+	DiskLevel      string   `json:"disk_level,omitempty"`      // ok, warning, critical
+	DaysUntilFull  *float64 `json:"days_until_full,omitempty"` // At the recent ingestion rate
+}
+
+// Stats history buckets
+const (
+	StatsBucketDay   = "day"
+	StatsBucketWeek  = "week"
+	StatsBucketMonth = "month"
+)
+
+// LibraryStatsSnapshot is a library's size on a given day
+type LibraryStatsSnapshot struct {
+	LibraryID     int64          `json:"library_id"`
+	Date          string         `json:"date"` // YYYY-MM-DD; the bucket start when bucketed
+	VideoCount    int            `json:"video_count"`
+	TotalBytes    int64          `json:"total_bytes"`
+	TotalDuration float64        `json:"total_duration"` // Seconds
+	Codecs        map[string]int `json:"codecs"`
+	Resolutions   map[string]int `json:"resolutions"`
+}
+
+// LibraryStatsHistory is a series of snapshots for charting
+type LibraryStatsHistory struct {
+	LibraryID int64                  `json:"library_id"`
+	From      string                 `json:"from"`
+	To        string                 `json:"to"`
+	Bucket    string                 `json:"bucket"`
+	Points    []LibraryStatsSnapshot `json:"points"`
+}
+
+// LibrarySettings controls which files a library scan, the browser and the file
+// watcher pick up, and what gets generated for them automatically
+type LibrarySettings struct {
+	LibraryID       int64     `json:"library_id"`
+	IncludePatterns []string  `json:"include_patterns"` // Globs relative to the library root; empty includes everything
+	ExcludePatterns []string  `json:"exclude_patterns"` // Globs matched against the relative path and each path component
+	Extensions      []string  `json:"extensions"`       // Extension allowlist; empty uses the supported video extensions
+	MinFileSize     int64     `json:"min_file_size"`    // Bytes
+	MinDuration     float64   `json:"min_duration"`     // Seconds
+	MaxDepth        int       `json:"max_depth"`        // Directory levels below the root; 0 is unlimited
+	FollowSymlinks  bool      `json:"follow_symlinks"`
+	AutoThumbnails  bool      `json:"auto_thumbnails"`
+	AutoPreviews    bool      `json:"auto_previews"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// LibrarySettingsUpdate represents the settings that can be updated
+type LibrarySettingsUpdate struct {
+	IncludePatterns *[]string `json:"include_patterns,omitempty"`
+	ExcludePatterns *[]string `json:"exclude_patterns,omitempty"`
+	Extensions      *[]string `json:"extensions,omitempty"`
+	MinFileSize     *int64    `json:"min_file_size,omitempty"`
+	MinDuration     *float64  `json:"min_duration,omitempty"`
+	MaxDepth        *int      `json:"max_depth,omitempty"`
+	FollowSymlinks  *bool     `json:"follow_symlinks,omitempty"`
+	AutoThumbnails  *bool     `json:"auto_thumbnails,omitempty"`
+	AutoPreviews    *bool     `json:"auto_previews,omitempty"`
 }
@@ -0,0 +1,34 @@
+package models
+
+// NFO conflict policies
+const (
+	NFOPolicySkip      = "skip"      // Export: leave existing NFO files untouched
+	NFOPolicyOverwrite = "overwrite" // Export: replace files; import: replace video fields
+	NFOPolicyMerge     = "merge"     // Export: keep values already in the file and fill in the rest
+	NFOPolicyFill      = "fill"      // Import: only set fields that are empty in the database
+)
+
+// NFOImportRequest selects videos whose sidecar NFOs should be read
+type NFOImportRequest struct {
+	LibraryID int64   `json:"library_id"`
+	VideoIDs  []int64 `json:"video_ids,omitempty"`
+	Policy    string  `json:"policy,omitempty"` // fill (default) or overwrite
+}
+
+// NFOExportRequest selects videos and performers to write NFO files for
+type NFOExportRequest struct {
+	VideoIDs          []int64 `json:"video_ids,omitempty"`
+	PerformerIDs      []int64 `json:"performer_ids,omitempty"`
+	IncludePerformers bool    `json:"include_performers"` // Also export performers of the selected videos
+	Policy            string  `json:"policy,omitempty"`   // skip (default), overwrite or merge
+}
+
+// NFOResult summarizes an import or export
+type NFOResult struct {
+	Processed int      `json:"processed"`
+	Written   int      `json:"written"`
+	Skipped   int      `json:"skipped"`
+	Failed    int      `json:"failed"`
+	Files     []string `json:"files,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
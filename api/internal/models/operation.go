@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Journaled operation types
+const (
+	OperationTagMerge      = "tag_merge"
+	OperationTagApply      = "tag_apply"
+	OperationPerformerLink = "performer_link"
+	OperationFileMove      = "file_move"
+	OperationVideoBulkEdit = "video_bulk_edit"
+)
+
+// Operation change kinds. Undo deletes added rows, re-inserts deleted rows,
+// puts back updated rows' previous values and moves files back.
+const (
+	ChangeRowAdded   = "row_added"
+	ChangeRowDeleted = "row_deleted"
+	ChangeRowUpdated = "row_updated"
+	ChangeFileMoved  = "file_moved"
+)
+
+// OperationChange is one change an operation made, with what undo needs to
+// reverse it. Row changes carry the row's values, or for an update its
+// identifying columns and the previous values of those changed; file moves
+// carry full paths.
+type OperationChange struct {
+	Kind            string                 `json:"kind"`
+	Table           string                 `json:"table,omitempty"`
+	Row             map[string]interface{} `json:"row,omitempty"`
+	LibraryID       int64                  `json:"library_id,omitempty"`
+	TargetLibraryID int64                  `json:"target_library_id,omitempty"`
+	From            string                 `json:"from,omitempty"`
+	To              string                 `json:"to,omitempty"`
+}
+
+// Operation is a journaled bulk change that can be undone
+type Operation struct {
+	ID          int64             `json:"id"`
+	Type        string            `json:"type"`
+	Summary     string            `json:"summary"`
+	ChangeCount int               `json:"change_count"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UndoneAt    *time.Time        `json:"undone_at,omitempty"`
+	Changes     []OperationChange `json:"changes,omitempty"` // Loaded for a single operation
+}
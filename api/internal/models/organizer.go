@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Organize move statuses. A plan's moves are move, unchanged, conflict or
+// skipped; a run's moves are moved or failed, and reverted or revert_failed
+// once the run is reverted.
+const (
+	OrganizeMove         = "move"
+	OrganizeUnchanged    = "unchanged"
+	OrganizeConflict     = "conflict"
+	OrganizeSkipped      = "skipped"
+	OrganizeMoved        = "moved"
+	OrganizeFailed       = "failed"
+	OrganizeReverted     = "reverted"
+	OrganizeRevertFailed = "revert_failed"
+)
+
+// OrganizeRequest selects videos and the path template that decides where each
+// one should live. The template is relative to the video's library and may use
+// {studio}, {group}, {performers}, {title}, {year}, {date}, {resolution},
+// {filename} and {ext}; {field|fallback} sets the text used when a value is
+// empty, which is otherwise "Unknown".
+type OrganizeRequest struct {
+	Template string            `json:"template" binding:"required"`
+	VideoIDs []int64           `json:"video_ids,omitempty"`
+	Query    *VideoSearchQuery `json:"query,omitempty"` // Organize every video matching a search
+}
+
+// OrganizeMoveItem is one video's move, planned or done. Paths are full paths.
+type OrganizeMoveItem struct {
+	ID        int64  `json:"id,omitempty"`
+	VideoID   int64  `json:"video_id"`
+	LibraryID int64  `json:"library_id"`
+	Title     string `json:"title,omitempty"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"` // Why a move conflicts, was skipped or failed
+}
+
+// OrganizePlan is the dry-run result: every selected video with where it would go
+type OrganizePlan struct {
+	Template  string             `json:"template"`
+	Moves     []OrganizeMoveItem `json:"moves"`
+	ToMove    int                `json:"to_move"`
+	Unchanged int                `json:"unchanged"`
+	Conflicts int                `json:"conflicts"`
+	Skipped   int                `json:"skipped"`
+}
+
+// OrganizeRun is an executed plan, journaled so it can be reverted
+type OrganizeRun struct {
+	ID         int64              `json:"id"`
+	Template   string             `json:"template"`
+	Moved      int                `json:"moved"`
+	Failed     int                `json:"failed"`
+	CreatedAt  time.Time          `json:"created_at"`
+	RevertedAt *time.Time         `json:"reverted_at,omitempty"`
+	Moves      []OrganizeMoveItem `json:"moves,omitempty"` // Loaded for a single run
+}
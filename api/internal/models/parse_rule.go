@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// Filename parse rule pattern types
+const (
+	ParseRuleTypeTemplate = "template" // e.g. "[{studio}] {performers} - {title} ({date})"
+	ParseRuleTypeRegex    = "regex"    // named capture groups: studio, group, performers, title, date, year, month, day
+)
+
+// FilenameParseRule turns a filename into metadata. Rules without a library apply
+// to every library; the first enabled rule that matches (by priority) wins.
+type FilenameParseRule struct {
+	ID            int64     `json:"id" db:"id"`
+	LibraryID     *int64    `json:"library_id,omitempty" db:"library_id"`
+	Name          string    `json:"name" db:"name"`
+	PatternType   string    `json:"pattern_type" db:"pattern_type"`
+	Pattern       string    `json:"pattern" db:"pattern"`
+	Priority      int       `json:"priority" db:"priority"` // Lower runs first
+	Enabled       bool      `json:"enabled" db:"enabled"`
+	CreateMissing bool      `json:"create_missing" db:"create_missing"` // Create unknown studios, groups and performers
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// FilenameParseRuleCreate represents the data needed to create a parse rule
+type FilenameParseRuleCreate struct {
+	LibraryID     *int64 `json:"library_id,omitempty"`
+	Name          string `json:"name" binding:"required"`
+	PatternType   string `json:"pattern_type"`
+	Pattern       string `json:"pattern" binding:"required"`
+	Priority      int    `json:"priority"`
+	Enabled       *bool  `json:"enabled,omitempty"`
+	CreateMissing bool   `json:"create_missing"`
+}
+
+// FilenameParseRuleUpdate represents the data that can be updated
+type FilenameParseRuleUpdate struct {
+	Name          *string `json:"name,omitempty"`
+	PatternType   *string `json:"pattern_type,omitempty"`
+	Pattern       *string `json:"pattern,omitempty"`
+	Priority      *int    `json:"priority,omitempty"`
+	Enabled       *bool   `json:"enabled,omitempty"`
+	CreateMissing *bool   `json:"create_missing,omitempty"`
+}
+
+// ParsedEntity is a studio, group or performer name found in a filename, with the
+// existing record it resolved to
+type ParsedEntity struct {
+	Name string `json:"name"`
+	ID   *int64 `json:"id,omitempty"` // nil when no record exists yet
+}
+
+// FilenameParseResult is what a filename parsed to
+type FilenameParseResult struct {
+	VideoID    int64          `json:"video_id,omitempty"`
+	FilePath   string         `json:"file_path"`
+	Matched    bool           `json:"matched"`
+	RuleID     int64          `json:"rule_id,omitempty"`
+	RuleName   string         `json:"rule_name,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Date       string         `json:"date,omitempty"` // YYYY-MM-DD
+	Studio     *ParsedEntity  `json:"studio,omitempty"`
+	Group      *ParsedEntity  `json:"group,omitempty"`
+	Performers []ParsedEntity `json:"performers,omitempty"`
+}
+
+// FilenameParseDryRunRequest selects the rules and files for a dry run
+type FilenameParseDryRunRequest struct {
+	LibraryID int64                    `json:"library_id" binding:"required"`
+	Rule      *FilenameParseRuleCreate `json:"rule,omitempty"`       // Test an unsaved rule instead of the library's rules
+	FilePaths []string                 `json:"file_paths,omitempty"` // Defaults to the library's videos
+	Limit     int                      `json:"limit,omitempty"`
+}
+
+// FilenameParseApplyRequest selects the videos to apply parse rules to
+type FilenameParseApplyRequest struct {
+	LibraryID     int64   `json:"library_id" binding:"required"`
+	VideoIDs      []int64 `json:"video_ids,omitempty"`      // Defaults to every video in the library
+	CreateMissing *bool   `json:"create_missing,omitempty"` // Overrides the matching rule's setting
+}
@@ -20,6 +20,29 @@ type Performer struct {
 	CreatedAt      time.Time          `json:"created_at"`
 	UpdatedAt      time.Time          `json:"updated_at"`
 	IsLCPCandidate bool               `json:"is_lcp_candidate,omitempty"`
+	Aliases        []PerformerAlias   `json:"aliases,omitempty"`
+	MatchedAlias   string             `json:"matched_alias,omitempty"` // Alias a search matched, when it wasn't the name
+	MatchScore     float64            `json:"match_score,omitempty"`   // Search similarity from 0 to 1
+}
+
+// Alias sources
+const (
+	AliasSourceManual   = "manual"
+	AliasSourceMetadata = "metadata" // Copied from PerformerMetadata.Aliases; replaced when metadata changes
+)
+
+// PerformerAlias is another name a performer is known by
+type PerformerAlias struct {
+	ID          int64     `json:"id"`
+	PerformerID int64     `json:"performer_id"`
+	Alias       string    `json:"alias"`
+	Source      string    `json:"source"` // 'manual' or 'metadata'
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PerformerAliasCreate represents the data needed to add an alias
+type PerformerAliasCreate struct {
+	Alias string `json:"alias" binding:"required"`
 }
 
 // PerformerCreate represents the data needed to create a new performer
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Playlist is a hand-curated, ordered list of videos
+type Playlist struct {
+	ID             int64          `json:"id" db:"id"`
+	Name           string         `json:"name" db:"name"`
+	Description    string         `json:"description,omitempty" db:"description"`
+	CoverVideoID   *int64         `json:"cover_video_id,omitempty" db:"cover_video_id"` // Video whose thumbnail is the cover; the first item's otherwise
+	CoverThumbnail string         `json:"cover_thumbnail,omitempty" db:"-"`
+	ItemCount      int            `json:"item_count" db:"-"`
+	TotalDuration  float64        `json:"total_duration" db:"-"` // Seconds
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+	Items          []PlaylistItem `json:"items,omitempty" db:"-"` // Loaded for a single playlist
+}
+
+// PlaylistItem is one video's place in a playlist
+type PlaylistItem struct {
+	ID         int64     `json:"id" db:"id"`
+	PlaylistID int64     `json:"playlist_id" db:"playlist_id"`
+	VideoID    int64     `json:"video_id" db:"video_id"`
+	Position   int       `json:"position" db:"position"` // 0-based and contiguous
+	AddedAt    time.Time `json:"added_at" db:"added_at"`
+	Video      *Video    `json:"video,omitempty" db:"-"`
+}
+
+// PlaylistCreate represents the data needed to create a playlist
+type PlaylistCreate struct {
+	Name         string `json:"name" binding:"required"`
+	Description  string `json:"description"`
+	CoverVideoID *int64 `json:"cover_video_id"`
+}
+
+// PlaylistUpdate represents the data that can be updated. A cover_video_id of
+// 0 goes back to the first item's thumbnail.
+type PlaylistUpdate struct {
+	Name         *string `json:"name,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	CoverVideoID *int64  `json:"cover_video_id,omitempty"`
+}
+
+// PlaylistAddRequest adds videos by ID, by search, or both. Search results are
+// added in the search's sort order after the listed videos.
+type PlaylistAddRequest struct {
+	VideoIDs []int64           `json:"video_ids"`
+	Query    *VideoSearchQuery `json:"query,omitempty"`
+	Position *int              `json:"position,omitempty"` // Insert before this position; appended when omitted
+}
+
+// PlaylistAddResult reports what an add did
+type PlaylistAddResult struct {
+	Added      int     `json:"added"`
+	Duplicates []int64 `json:"duplicates"` // Videos skipped because they're already in the playlist
+	NotFound   []int64 `json:"not_found"`  // Listed video IDs that don't exist
+}
+
+// PlaylistMoveRequest moves an item to a new position, shifting the items between
+type PlaylistMoveRequest struct {
+	Position *int `json:"position" binding:"required"`
+}
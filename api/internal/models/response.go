@@ -13,18 +13,21 @@ type APIResponse struct {
 
 // PaginatedResponse wraps paginated data
 type PaginatedResponse struct {
-	Success    bool        `json:"success"`
-	Data       interface{} `json:"data"`
-	Pagination Pagination  `json:"pagination"`
-	Timestamp  time.Time   `json:"timestamp"`
+	Success    bool                    `json:"success"`
+	Data       interface{}             `json:"data"`
+	Pagination Pagination              `json:"pagination"`
+	Facets     map[string][]FacetCount `json:"facets,omitempty"` // Counts per facet value across every page, when requested
+	Timestamp  time.Time               `json:"timestamp"`
 }
 
 // Pagination contains pagination metadata
 type Pagination struct {
-	Page       int   `json:"page"`
-	Limit      int   `json:"limit"`
-	Total      int64 `json:"total"`
-	TotalPages int   `json:"total_pages"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"` // Keyset cursor for the following page, when the listing supports one
+	Seed       int64  `json:"seed,omitempty"`        // Seed of a random order, to reproduce it on later pages
 }
 
 // ErrorResponse represents an error response
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// SavedSearch is a named video search that runs live whenever it's opened
+type SavedSearch struct {
+	ID             int64            `json:"id" db:"id"`
+	Name           string           `json:"name" db:"name"`
+	Icon           string           `json:"icon,omitempty" db:"icon"`
+	Pinned         bool             `json:"pinned" db:"pinned"`
+	Query          VideoSearchQuery `json:"query" db:"query"`                                 // Filters and sort; page and limit aren't saved
+	VideoCount     *int             `json:"video_count,omitempty" db:"video_count"`           // Matching videos as of CountUpdatedAt
+	CountUpdatedAt *time.Time       `json:"count_updated_at,omitempty" db:"count_updated_at"` // nil until the first count
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// SavedSearchCreate represents the data needed to create a saved search
+type SavedSearchCreate struct {
+	Name   string           `json:"name" binding:"required"`
+	Icon   string           `json:"icon"`
+	Pinned bool             `json:"pinned"`
+	Query  VideoSearchQuery `json:"query"`
+}
+
+// SavedSearchUpdate represents the data that can be updated
+type SavedSearchUpdate struct {
+	Name   *string           `json:"name,omitempty"`
+	Icon   *string           `json:"icon,omitempty"`
+	Pinned *bool             `json:"pinned,omitempty"`
+	Query  *VideoSearchQuery `json:"query,omitempty"`
+}
@@ -24,6 +24,7 @@ type ScrapedThread struct {
 	LastScrapedAt   time.Time              `json:"last_scraped_at" db:"last_scraped_at"`
 	LastUpdatedAt   time.Time              `json:"last_updated_at" db:"last_updated_at"` // Thread last updated on source
 	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+	Snippet         string                 `json:"snippet,omitempty" db:"-"`             // Best matching fragment, HTML-escaped with matches wrapped in <mark>
 }
 
 // ScrapedThreadMetadata represents additional thread information
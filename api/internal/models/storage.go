@@ -0,0 +1,39 @@
+package models
+
+// Disk space levels
+const (
+	DiskLevelOK       = "ok"
+	DiskLevelWarning  = "warning"
+	DiskLevelCritical = "critical"
+)
+
+// DiskSpaceThresholds decide when low free space raises an alert. A volume is at a
+// level when its free percentage or, if set, its free bytes drop below that level's limit.
+type DiskSpaceThresholds struct {
+	WarningPercent    float64 `json:"warning_percent"`
+	CriticalPercent   float64 `json:"critical_percent"`
+	WarningFreeBytes  int64   `json:"warning_free_bytes"`  // 0 disables the absolute limit
+	CriticalFreeBytes int64   `json:"critical_free_bytes"` // 0 disables the absolute limit
+}
+
+// DiskSpaceThresholdsUpdate represents the thresholds that can be updated
+type DiskSpaceThresholdsUpdate struct {
+	WarningPercent    *float64 `json:"warning_percent,omitempty"`
+	CriticalPercent   *float64 `json:"critical_percent,omitempty"`
+	WarningFreeBytes  *int64   `json:"warning_free_bytes,omitempty"`
+	CriticalFreeBytes *int64   `json:"critical_free_bytes,omitempty"`
+}
+
+// DiskUsage is the capacity of the filesystem holding a library root or asset directory
+type DiskUsage struct {
+	Label         string   `json:"label"` // Library name, or thumbnails/previews/assets
+	Path          string   `json:"path"`
+	LibraryID     *int64   `json:"library_id,omitempty"`
+	TotalBytes    int64    `json:"total_bytes"`
+	FreeBytes     int64    `json:"free_bytes"`
+	FreePercent   float64  `json:"free_percent"`
+	Level         string   `json:"level"`                     // ok, warning, critical
+	BytesPerDay   float64  `json:"bytes_per_day,omitempty"`   // Recent ingestion rate
+	DaysUntilFull *float64 `json:"days_until_full,omitempty"` // At the recent ingestion rate
+	Error         string   `json:"error,omitempty"`
+}
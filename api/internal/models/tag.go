@@ -11,22 +11,29 @@ type Tag struct {
 	Category  string    `json:"category" db:"category"` // regular, zoo, or 3d
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ParentIDs []int64   `json:"parent_ids" db:"-"` // Tags this one implies; a tag may have several parents
+	Aliases   []string  `json:"aliases" db:"-"`    // Other names that resolve to this tag
 }
 
 // TagCreate represents the data needed to create a tag
 type TagCreate struct {
-	Name     string `json:"name" binding:"required"`
-	Color    string `json:"color"`
-	Icon     string `json:"icon"`
-	Category string `json:"category"`
+	Name      string   `json:"name" binding:"required"`
+	Color     string   `json:"color"`
+	Icon      string   `json:"icon"`
+	Category  string   `json:"category"`
+	ParentIDs []int64  `json:"parent_ids,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
 }
 
-// TagUpdate represents the data that can be updated
+// TagUpdate represents the data that can be updated. ParentIDs and Aliases
+// replace the current ones when present; an empty list clears them.
 type TagUpdate struct {
-	Name     *string `json:"name,omitempty"`
-	Color    *string `json:"color,omitempty"`
-	Icon     *string `json:"icon,omitempty"`
-	Category *string `json:"category,omitempty"`
+	Name      *string  `json:"name,omitempty"`
+	Color     *string  `json:"color,omitempty"`
+	Icon      *string  `json:"icon,omitempty"`
+	Category  *string  `json:"category,omitempty"`
+	ParentIDs []int64  `json:"parent_ids,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
 }
 
 // TagMergeRequest represents a request to merge multiple tags
@@ -40,3 +47,10 @@ type TagWithCount struct {
 	Tag
 	VideoCount int `json:"video_count" db:"video_count"`
 }
+
+// TagTreeNode is a tag in the hierarchy with its children. A tag with several
+// parents appears under each of them.
+type TagTreeNode struct {
+	TagWithCount
+	Children []TagTreeNode `json:"children"`
+}
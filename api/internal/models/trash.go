@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TrashItem is a deleted file, and the video it belonged to, waiting in its
+// library's .trash folder to be restored or purged
+type TrashItem struct {
+	ID           int64      `json:"id"`
+	LibraryID    int64      `json:"library_id"`
+	OriginalPath string     `json:"original_path"`
+	TrashPath    string     `json:"trash_path,omitempty"` // Empty when the file was already missing
+	FileSize     int64      `json:"file_size"`
+	VideoID      *int64     `json:"video_id,omitempty"` // Set when a video row was trashed with the file
+	Title        string     `json:"title,omitempty"`
+	DeletedAt    time.Time  `json:"deleted_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"` // When the purge job removes it; nil when retention is off
+}
+
+// TrashSettings control the automatic purge
+type TrashSettings struct {
+	RetentionDays int `json:"retention_days"` // 0 keeps items until they're deleted by hand
+}
+
+// TrashSettingsUpdate represents the trash settings that can be updated
+type TrashSettingsUpdate struct {
+	RetentionDays *int `json:"retention_days,omitempty"`
+}
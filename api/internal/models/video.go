@@ -2,6 +2,12 @@ package models
 
 import "time"
 
+// Video availability status constants
+const (
+	VideoStatusAvailable = "available"
+	VideoStatusMissing   = "missing"
+)
+
 // Video represents a video file with its metadata
 type Video struct {
 	ID            int64      `json:"id" db:"id"`
@@ -29,6 +35,13 @@ type Video struct {
 	PlayCount     int        `json:"play_count" db:"play_count"`
 	ConvertedFrom *int64     `json:"converted_from,omitempty" db:"converted_from"` // ID of original video if this is a conversion
 	ConvertedTo   *int64     `json:"converted_to,omitempty" db:"converted_to"`     // ID of converted video if this was converted
+	Status        string     `json:"status" db:"status"`                           // available, missing
+	MissingSince  *time.Time `json:"missing_since,omitempty" db:"missing_since"`
+	OSHash        string     `json:"oshash,omitempty" db:"oshash"` // OpenSubtitles-style hash of size plus first/last 64KB
+	MD5           string     `json:"md5,omitempty" db:"md5"`       // Full content hash, computed by a background job
+	Unavailable   bool       `json:"unavailable,omitempty" db:"-"` // Library is offline, so the file can't be played right now
+	Highlight     string     `json:"highlight,omitempty" db:"-"`   // HTML-escaped title with search matches wrapped in <mark>, set by full-text search
+	Snippet       string     `json:"snippet,omitempty" db:"-"`     // Best matching fragment across indexed fields, HTML-escaped and marked like Highlight
 
 	// Relationships (loaded separately)
 	Performers []Performer `json:"performers,omitempty"`
@@ -71,6 +84,7 @@ type VideoUpdate struct {
 // VideoSearchQuery represents search parameters
 type VideoSearchQuery struct {
 	Query         string  `json:"query" form:"query"`
+	Q             string  `json:"q" form:"q"` // Structured query, e.g. performer:"Jane Doe" duration>20m -tag:compilation
 	LibraryID     int64   `json:"library_id" form:"library_id"`
 	PerformerID   int64   `json:"performer_id" form:"performer_id"`
 	StudioID      int64   `json:"studio_id" form:"studio_id"`
@@ -90,8 +104,61 @@ type VideoSearchQuery struct {
 	MissingMeta   *bool   `json:"missing_metadata" form:"missing_metadata"`
 	NotInterested *bool   `json:"not_interested" form:"not_interested"`
 	InEditList    *bool   `json:"in_edit_list" form:"in_edit_list"`
-	SortBy        string  `json:"sort_by" form:"sort_by"`       // created_at, duration, play_count, title
+	Status        string  `json:"status" form:"status"`         // available (default), missing, all
+	SortBy        string  `json:"sort_by" form:"sort_by"`       // created_at, updated_at, title, duration, file_size, play_count, rating, last_played_at, bitrate, performer_count, random, relevance
 	SortOrder     string  `json:"sort_order" form:"sort_order"` // asc, desc
+	Seed          int64   `json:"seed,omitempty" form:"seed"`   // Shuffle seed for sort_by=random; the same seed gives the same order
+	Cursor        string  `json:"-" form:"cursor"`              // next_cursor from the previous page; replaces page
 	Page          int     `json:"page" form:"page"`
 	Limit          int     `json:"limit" form:"limit"`
 }
+
+// Related video reason kinds
+const (
+	RelatedReasonPerformer  = "performer"
+	RelatedReasonTag        = "tag"
+	RelatedReasonStudio     = "studio"
+	RelatedReasonGroup      = "group"
+	RelatedReasonFolder     = "folder"
+	RelatedReasonDuration   = "duration"
+	RelatedReasonResolution = "resolution"
+)
+
+// RelatedReason is one thing a related video shares with the source
+type RelatedReason struct {
+	Kind  string   `json:"kind"`            // performer, tag, studio, group, folder, duration, resolution
+	Names []string `json:"names,omitempty"` // Shared performers, tags, studios or groups
+	Score float64  `json:"score"`           // This reason's part of the related score
+}
+
+// RelatedVideo is a video similar to another, with why it was picked
+type RelatedVideo struct {
+	Video
+	RelatedScore float64         `json:"related_score"` // From 0 to 1; 1 shares everything
+	Reasons      []RelatedReason `json:"reasons"`
+}
+
+// VideoPage is one page of a video search
+type VideoPage struct {
+	Videos     []Video `json:"data"`
+	Total      int     `json:"total"`
+	NextCursor string  `json:"next_cursor,omitempty"` // Pass as cursor to get the following page; empty on the last page
+	Seed       int64   `json:"seed,omitempty"`        // Seed used for sort_by=random, to reproduce the order
+}
+
+// Video list facets
+const (
+	FacetTags       = "tags"
+	FacetPerformers = "performers"
+	FacetStudios    = "studios"
+	FacetResolution = "resolution"
+	FacetCodec      = "codec"
+	FacetDuration   = "duration"
+)
+
+// FacetCount is how many filtered videos share one facet value
+type FacetCount struct {
+	ID    *int64 `json:"id,omitempty"` // Tag, performer or studio ID
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// VideoBulkLinks changes one kind of linked record on every selected video.
+// Set replaces the links, and an empty set clears them; otherwise Remove and
+// Add are applied.
+type VideoBulkLinks struct {
+	Add    []int64 `json:"add,omitempty"`
+	Remove []int64 `json:"remove,omitempty"`
+	Set    []int64 `json:"set,omitempty"`
+}
+
+// VideoBulkUpdate selects videos by ID, by search or both, and the changes to
+// make to all of them. Fields left out are not changed.
+type VideoBulkUpdate struct {
+	VideoIDs          []int64           `json:"video_ids,omitempty"`
+	Query             *VideoSearchQuery `json:"query,omitempty"`
+	Tags              *VideoBulkLinks   `json:"tags,omitempty"`
+	Performers        *VideoBulkLinks   `json:"performers,omitempty"`
+	Studio            *VideoBulkLinks   `json:"studio,omitempty"`
+	Group             *VideoBulkLinks   `json:"group,omitempty"`
+	Rating            *int              `json:"rating,omitempty"`
+	IsFavorite        *bool             `json:"is_favorite,omitempty"`
+	IsPinned          *bool             `json:"is_pinned,omitempty"`
+	NotInterested     *bool             `json:"not_interested,omitempty"`
+	InEditList        *bool             `json:"in_edit_list,omitempty"`
+	AppendDescription *string           `json:"append_description,omitempty"` // Added as a new line after any existing description
+}
+
+// VideoBulkResult reports what a bulk edit changed. Updated counts changed
+// videos per field, and added and removed links per relationship, such as
+// "rating" or "tags_added".
+type VideoBulkResult struct {
+	Matched     int            `json:"matched"`
+	NotFound    []int64        `json:"not_found"`
+	Updated     map[string]int `json:"updated"`
+	OperationID int64          `json:"operation_id,omitempty"` // Journal entry that undoes the edit
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/brixen96/video-storage-ai/internal/database"
@@ -19,13 +20,26 @@ type ActivityService struct {
 // WebSocketHub interface for broadcasting updates
 type WebSocketHub interface {
 	BroadcastActivityUpdate(activity *models.Activity)
+	BroadcastActivityTree(tree *models.Activity)
 	BroadcastStatusUpdate(status *models.ActivityStatus)
 	BroadcastSystemEvent(event string)
+	BroadcastLibraryStatus(library *models.Library)
 }
 
 // Global WebSocket hub reference (will be set by API layer)
 var wsHub WebSocketHub
 
+// activityUpdateInterval is the most often a task's item counters are written,
+// and a tree or the overall status is broadcast, while work is in progress
+const activityUpdateInterval = 500 * time.Millisecond
+
+// Activity services are created per caller, so throttling state is shared
+var (
+	itemProgressThrottle = &activityThrottle{last: make(map[int64]time.Time)}
+	treeBroadcasts       = &broadcastCoalescer{last: make(map[int64]time.Time), pending: make(map[int64]bool)}
+	statusBroadcasts     = &broadcastCoalescer{last: make(map[int64]time.Time), pending: make(map[int64]bool)}
+)
+
 // SetWebSocketHub sets the WebSocket hub for broadcasting
 func SetWebSocketHub(hub WebSocketHub) {
 	wsHub = hub
@@ -72,56 +86,150 @@ func (s *ActivityService) Create(create *models.ActivityLogCreate) (*models.Acti
 	return s.GetByID(id)
 }
 
-// GetByID retrieves an activity log by ID
-func (s *ActivityService) GetByID(id int64) (*models.Activity, error) {
-	query := `
-		SELECT id, task_type, status, message, progress, details, started_at, updated_at, completed_at
-		FROM activity_logs
-		WHERE id = ?
-	`
+// activityColumns lists the activity_logs columns read by scanActivity
+const activityColumns = `id, parent_id, task_type, status, message, progress, items_processed, items_total,
+		details, started_at, updated_at, completed_at, error`
+
+// activityScanner is satisfied by both *sql.Row and *sql.Rows
+type activityScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanActivity scans a row selected with activityColumns into an Activity
+func scanActivity(row activityScanner) (*models.Activity, error) {
 	var activity models.Activity
+	var parentID sql.NullInt64
 	var detailsJSON []byte
-	var completedAt sql.NullTime
+	var updatedAt, completedAt sql.NullTime
+	var itemsProcessed, itemsTotal sql.NullInt64
+	var errorMsg sql.NullString
 
-	err := s.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&activity.ID,
+		&parentID,
 		&activity.TaskType,
 		&activity.Status,
 		&activity.Message,
 		&activity.Progress,
+		&itemsProcessed,
+		&itemsTotal,
 		&detailsJSON,
 		&activity.StartedAt,
-		&activity.UpdatedAt,
+		&updatedAt,
 		&completedAt,
+		&errorMsg,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("activity not found")
-	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get activity: %w", err)
+		return nil, err
 	}
 
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		activity.ParentID = &id
+	}
+	activity.ItemsProcessed = int(itemsProcessed.Int64)
+	activity.ItemsTotal = int(itemsTotal.Int64)
+	if updatedAt.Valid {
+		activity.UpdatedAt = updatedAt.Time
+	}
 	if completedAt.Valid {
 		activity.CompletedAt = &completedAt.Time
 	}
+	if errorMsg.Valid {
+		activity.Error = &errorMsg.String
+	}
 
 	// Details is stored as JSON string in the database
 	if len(detailsJSON) > 0 {
 		activity.Details = string(detailsJSON)
 	}
 
+	activity.ComputeThroughput(time.Now())
+
 	return &activity, nil
 }
 
+// GetByID retrieves an activity log by ID
+func (s *ActivityService) GetByID(id int64) (*models.Activity, error) {
+	query := `SELECT ` + activityColumns + ` FROM activity_logs WHERE id = ?`
+
+	activity, err := scanActivity(s.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("activity not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+
+	return activity, nil
+}
+
+// GetChildren retrieves the direct child activities of an activity
+func (s *ActivityService) GetChildren(parentID int64) ([]models.Activity, error) {
+	query := `SELECT ` + activityColumns + ` FROM activity_logs WHERE parent_id = ? ORDER BY id ASC`
+
+	rows, err := s.db.Query(query, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query child activities: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	children := make([]models.Activity, 0)
+	for rows.Next() {
+		child, err := scanActivity(rows)
+		if err != nil {
+			continue
+		}
+		children = append(children, *child)
+	}
+
+	return children, nil
+}
+
+// GetTree retrieves an activity together with all of its descendants
+func (s *ActivityService) GetTree(id int64) (*models.Activity, error) {
+	activity, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := s.GetChildren(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range children {
+		subtree, err := s.GetTree(int64(child.ID))
+		if err != nil {
+			continue
+		}
+		activity.Children = append(activity.Children, *subtree)
+	}
+
+	return activity, nil
+}
+
+// getRootID walks up the parent chain and returns the ID of the top-level activity
+func (s *ActivityService) getRootID(id int64) int64 {
+	current := id
+	// Bound the walk so a corrupted parent chain can't loop forever
+	for depth := 0; depth < 32; depth++ {
+		var parentID sql.NullInt64
+		if err := s.db.QueryRow(`SELECT parent_id FROM activity_logs WHERE id = ?`, current).Scan(&parentID); err != nil || !parentID.Valid {
+			return current
+		}
+		current = parentID.Int64
+	}
+	return current
+}
+
 // GetAll retrieves all activity logs with optional filtering
 func (s *ActivityService) GetAll(status string, taskType string, limit int) ([]models.Activity, error) {
-	query := `
-		SELECT id, task_type, status, message, progress, details, started_at, updated_at, completed_at, error
-		FROM activity_logs
-		WHERE 1=1
-	`
+	query := `SELECT ` + activityColumns + ` FROM activity_logs WHERE 1=1`
 	args := []interface{}{}
 
 	if status != "" {
@@ -152,41 +260,11 @@ func (s *ActivityService) GetAll(status string, taskType string, limit int) ([]m
 
 	var activities []models.Activity
 	for rows.Next() {
-		var activity models.Activity
-		var detailsJSON []byte
-		var completedAt sql.NullTime
-		var errorMsg sql.NullString
-
-		err := rows.Scan(
-			&activity.ID,
-			&activity.TaskType,
-			&activity.Status,
-			&activity.Message,
-			&activity.Progress,
-			&detailsJSON,
-			&activity.StartedAt,
-			&activity.UpdatedAt,
-			&completedAt,
-			&errorMsg,
-		)
+		activity, err := scanActivity(rows)
 		if err != nil {
 			continue
 		}
-
-		if completedAt.Valid {
-			activity.CompletedAt = &completedAt.Time
-		}
-
-		if errorMsg.Valid {
-			activity.Error = &errorMsg.String
-		}
-
-		// Details is stored as JSON string in the database
-		if len(detailsJSON) > 0 {
-			activity.Details = string(detailsJSON)
-		}
-
-		activities = append(activities, activity)
+		activities = append(activities, *activity)
 	}
 
 	return activities, nil
@@ -213,6 +291,16 @@ func (s *ActivityService) Update(id int, update *models.ActivityLogUpdate) (*mod
 		args = append(args, *update.Progress)
 	}
 
+	if update.ItemsProcessed != nil {
+		query += ", items_processed = ?"
+		args = append(args, *update.ItemsProcessed)
+	}
+
+	if update.ItemsTotal != nil {
+		query += ", items_total = ?"
+		args = append(args, *update.ItemsTotal)
+	}
+
 	if update.Details != nil {
 		detailsJSON, err := json.Marshal(update.Details)
 		if err != nil {
@@ -242,19 +330,89 @@ func (s *ActivityService) Update(id int, update *models.ActivityLogUpdate) (*mod
 		return nil, err
 	}
 
+	if activity.Status == models.TaskStatusCompleted || activity.Status == models.TaskStatusFailed {
+		itemProgressThrottle.forget(int64(activity.ID))
+	}
+
+	// Roll the change up into the parent chain
+	if activity.ParentID != nil {
+		if err := s.refreshParentProgress(int64(*activity.ParentID)); err != nil {
+			log.Printf("failed to refresh parent activity %d: %v", *activity.ParentID, err)
+		}
+	}
+
 	// Broadcast the individual activity update
 	if err := s.BroadcastUpdate(activity); err != nil {
 		log.Printf("failed to broadcast activity update: %v", err)
 	}
 
-	// Broadcast the overall status update
-	if err := s.BroadcastStatusUpdate(); err != nil {
-		log.Printf("failed to broadcast status update: %v", err)
+	// Broadcast the whole tree when the activity is part of one. Every child
+	// update lands here, so trees and the status are sent at most once per
+	// interval with whatever state they have by then.
+	if activity.ParentID != nil {
+		rootID := s.getRootID(int64(activity.ID))
+		treeBroadcasts.run(rootID, func() {
+			s.BroadcastTree(rootID)
+		})
 	}
 
+	// Broadcast the overall status update
+	statusBroadcasts.run(0, func() {
+		if err := s.BroadcastStatusUpdate(); err != nil {
+			log.Printf("failed to broadcast status update: %v", err)
+		}
+	})
+
 	return activity, nil
 }
 
+// refreshParentProgress recomputes a parent's progress and item counters from its
+// children, then continues up the chain. Children are weighted by item count once
+// every child knows its total, otherwise each child counts equally.
+func (s *ActivityService) refreshParentProgress(parentID int64) error {
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(SUM(progress), 0),
+		       COALESCE(SUM(items_processed), 0),
+		       COALESCE(SUM(items_total), 0),
+		       COUNT(CASE WHEN COALESCE(items_total, 0) = 0 THEN 1 END)
+		FROM activity_logs
+		WHERE parent_id = ?
+	`
+
+	var childCount, progressSum, processed, total, unknownTotals int
+	if err := s.db.QueryRow(query, parentID).Scan(&childCount, &progressSum, &processed, &total, &unknownTotals); err != nil {
+		return fmt.Errorf("failed to aggregate child activities: %w", err)
+	}
+	if childCount == 0 {
+		return nil
+	}
+
+	progress := progressSum / childCount
+	if unknownTotals == 0 && total > 0 {
+		progress = processed * 100 / total
+	}
+	if progress > 100 {
+		progress = 100
+	}
+
+	_, err := s.db.Exec(`
+		UPDATE activity_logs
+		SET progress = ?, items_processed = ?, items_total = ?, updated_at = ?
+		WHERE id = ? AND status NOT IN (?, ?)
+	`, progress, processed, total, time.Now(), parentID, models.TaskStatusCompleted, models.TaskStatusFailed)
+	if err != nil {
+		return fmt.Errorf("failed to update parent activity: %w", err)
+	}
+
+	var grandParentID sql.NullInt64
+	if err := s.db.QueryRow(`SELECT parent_id FROM activity_logs WHERE id = ?`, parentID).Scan(&grandParentID); err == nil && grandParentID.Valid {
+		return s.refreshParentProgress(grandParentID.Int64)
+	}
+
+	return nil
+}
+
 // Delete deletes an activity log
 func (s *ActivityService) Delete(id int64) error {
 	query := "DELETE FROM activity_logs WHERE id = ?"
@@ -440,11 +598,28 @@ func (s *ActivityService) GetStatsByType() (map[string]int, error) {
 
 // StartTask is a helper to create and start a new task
 func (s *ActivityService) StartTask(taskType, message string, details map[string]interface{}) (*models.Activity, error) {
+	return s.insertTask(nil, taskType, models.TaskStatusRunning, message, details)
+}
+
+// StartChildTask creates a running task nested under an existing parent activity
+func (s *ActivityService) StartChildTask(parentID int, taskType, message string, details map[string]interface{}) (*models.Activity, error) {
+	return s.insertTask(&parentID, taskType, models.TaskStatusRunning, message, details)
+}
+
+// QueueChildTask creates a pending task nested under an existing parent activity.
+// Call BeginTask when the work actually starts so throughput is measured correctly.
+func (s *ActivityService) QueueChildTask(parentID int, taskType, message string, details map[string]interface{}) (*models.Activity, error) {
+	return s.insertTask(&parentID, taskType, models.TaskStatusPending, message, details)
+}
+
+// insertTask inserts a new activity row and broadcasts it
+func (s *ActivityService) insertTask(parentID *int, taskType, status, message string, details map[string]interface{}) (*models.Activity, error) {
 	activity := &models.Activity{
-		TaskType:  taskType,
-		Status:    models.TaskStatusRunning,
-		Message:   message,
-		Progress:  0,
+		ParentID: parentID,
+		TaskType: taskType,
+		Status:   status,
+		Message:  message,
+		Progress: 0,
 	}
 
 	// Marshal details to JSON string
@@ -461,8 +636,8 @@ func (s *ActivityService) StartTask(taskType, message string, details map[string
 	activity.Details = detailsJSON
 
 	query := `
-        INSERT INTO activity_logs (task_type, status, message, details, progress, started_at, updated_at, completed_at, error)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+        INSERT INTO activity_logs (parent_id, task_type, status, message, details, progress, items_processed, items_total, started_at, updated_at, completed_at, error)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
 
 	now := time.Now()
@@ -470,11 +645,14 @@ func (s *ActivityService) StartTask(taskType, message string, details map[string
 
 	result, err := s.db.Exec(
 		query,
+		activity.ParentID,
 		activity.TaskType,
 		activity.Status,
 		activity.Message,
 		detailsJSON,
 		0,   // progress
+		0,   // items_processed
+		0,   // items_total
 		now, // started_at
 		now, // updated_at
 		nil, // completed_at
@@ -495,10 +673,26 @@ func (s *ActivityService) StartTask(taskType, message string, details map[string
 	if err := s.BroadcastUpdate(activity); err != nil {
 		log.Printf("failed to broadcast activity update: %v", err)
 	}
+	if parentID != nil {
+		s.BroadcastTree(id)
+	}
 
 	return s.GetByID(int64(id))
 }
 
+// BeginTask moves a pending task to running and resets its start time
+func (s *ActivityService) BeginTask(id int, message string) error {
+	_, err := s.db.Exec(
+		`UPDATE activity_logs SET status = ?, started_at = ?, updated_at = ? WHERE id = ?`,
+		models.TaskStatusRunning, time.Now(), time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to begin activity: %w", err)
+	}
+
+	return s.UpdateProgress(id, 0, message)
+}
+
 // CompleteTask is a helper to mark a task as completed
 func (s *ActivityService) CompleteTask(id int64, message string) error {
 	status := models.TaskStatusCompleted
@@ -542,6 +736,29 @@ func (s *ActivityService) UpdateProgress(id int, progress int, message string) e
 	return err
 }
 
+// UpdateItemProgress is a helper to update the item counters of a task.
+// Progress is derived from the counters. Tasks report this per item, so counts
+// between the first and last are written at most once per interval.
+func (s *ActivityService) UpdateItemProgress(id int, processed, total int, message string) error {
+	if processed > 0 && processed < total && !itemProgressThrottle.allow(int64(id)) {
+		return nil
+	}
+
+	progress := 0
+	if total > 0 {
+		progress = processed * 100 / total
+	}
+	update := &models.ActivityLogUpdate{
+		Progress:       &progress,
+		ItemsProcessed: &processed,
+		ItemsTotal:     &total,
+		Message:        &message,
+	}
+
+	_, err := s.Update(id, update)
+	return err
+}
+
 // checkAndBroadcastIdle checks if there are any active tasks and broadcasts an "idle" event if not.
 func (s *ActivityService) checkAndBroadcastIdle() {
 	status, err := s.GetStatus()
@@ -568,13 +785,7 @@ func (s *ActivityService) GetTasksByStatus(status string, limit, offset int) ([]
 	}
 
 	// Get tasks
-	query := `
-		SELECT id, task_type, status, message, progress, details, started_at, updated_at, completed_at, error
-		FROM activity_logs
-		WHERE status = ?
-		ORDER BY started_at DESC
-		LIMIT ? OFFSET ?
-	`
+	query := `SELECT ` + activityColumns + ` FROM activity_logs WHERE status = ? ORDER BY started_at DESC LIMIT ? OFFSET ?`
 
 	rows, err := s.db.Query(query, status, limit, offset)
 	if err != nil {
@@ -588,41 +799,11 @@ func (s *ActivityService) GetTasksByStatus(status string, limit, offset int) ([]
 
 	var activities []models.Activity
 	for rows.Next() {
-		var activity models.Activity
-		var detailsJSON []byte
-		var completedAt sql.NullTime
-		var errorMsg sql.NullString
-
-		err := rows.Scan(
-			&activity.ID,
-			&activity.TaskType,
-			&activity.Status,
-			&activity.Message,
-			&activity.Progress,
-			&detailsJSON,
-			&activity.StartedAt,
-			&activity.UpdatedAt,
-			&completedAt,
-			&errorMsg,
-		)
+		activity, err := scanActivity(rows)
 		if err != nil {
 			continue
 		}
-
-		if completedAt.Valid {
-			activity.CompletedAt = &completedAt.Time
-		}
-
-		if errorMsg.Valid {
-			activity.Error = &errorMsg.String
-		}
-
-		// Details is stored as JSON string in the database
-		if len(detailsJSON) > 0 {
-			activity.Details = string(detailsJSON)
-		}
-
-		activities = append(activities, activity)
+		activities = append(activities, *activity)
 	}
 
 	return activities, total, nil
@@ -1014,6 +1195,89 @@ func (s *ActivityService) BroadcastUpdateLog(activityLog *models.ActivityLog) er
 	return nil
 }
 
+// BroadcastTree sends the full activity tree containing the given activity via WebSocket
+func (s *ActivityService) BroadcastTree(id int64) {
+	hub := wsHub
+	if hub == nil {
+		return
+	}
+
+	tree, err := s.GetTree(s.getRootID(id))
+	if err != nil {
+		log.Printf("failed to load activity tree for %d: %v", id, err)
+		return
+	}
+	hub.BroadcastActivityTree(tree)
+}
+
+// activityThrottle lets something happen for each activity at most once per
+// interval
+type activityThrottle struct {
+	mu   sync.Mutex
+	last map[int64]time.Time
+}
+
+// allow reports whether id is due, and if so starts a new interval for it
+func (t *activityThrottle) allow(id int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if time.Since(t.last[id]) < activityUpdateInterval {
+		return false
+	}
+	t.last[id] = time.Now()
+	return true
+}
+
+// forget drops the state kept for a finished activity
+func (t *activityThrottle) forget(id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.last, id)
+}
+
+// broadcastCoalescer runs a broadcast at most once per interval for each key.
+// Calls within an interval are folded into one broadcast at its end, which
+// sends the state as of then.
+type broadcastCoalescer struct {
+	mu      sync.Mutex
+	last    map[int64]time.Time
+	pending map[int64]bool
+}
+
+// run broadcasts now if key's interval has passed, or schedules one for when it does
+func (c *broadcastCoalescer) run(key int64, broadcast func()) {
+	c.mu.Lock()
+	if c.pending[key] {
+		c.mu.Unlock()
+		return
+	}
+
+	wait := activityUpdateInterval - time.Since(c.last[key])
+	if wait <= 0 {
+		// Keys that have been quiet for a whole interval need no state
+		for k, at := range c.last {
+			if time.Since(at) >= activityUpdateInterval {
+				delete(c.last, k)
+			}
+		}
+		c.last[key] = time.Now()
+		c.mu.Unlock()
+		broadcast()
+		return
+	}
+
+	c.pending[key] = true
+	c.mu.Unlock()
+	time.AfterFunc(wait, func() {
+		c.mu.Lock()
+		delete(c.pending, key)
+		c.last[key] = time.Now()
+		c.mu.Unlock()
+		broadcast()
+	})
+}
+
 // BroadcastStatusUpdate sends a real-time status update via WebSocket
 func (s *ActivityService) BroadcastStatusUpdate() error {
 	status, err := s.GetStatus()
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -40,11 +41,15 @@ type AICompanionService struct {
 	eventsProcessed   int64
 	recommendations   map[string]*Recommendation // recommendation_id -> Recommendation
 	lastActivityCheck time.Time                  // Last time we checked activity_logs
+	videoService      *VideoService
+	pendingFiles      map[string]*pendingFile // file_path -> file waiting to settle
+	pendingMu         sync.Mutex
+	diskLevels        map[string]string // path -> last reported disk space level
 }
 
 // CompanionEvent represents an event the AI detected
 type CompanionEvent struct {
-	Type      string                 `json:"type"` // file_added, file_removed, file_modified, insight, notification
+	Type      string                 `json:"type"` // file_added, file_removed, file_modified, file_ingested, insight, notification
 	Source    string                 `json:"source"` // file_watcher, analysis_engine, health_monitor
 	Message   string                 `json:"message"`
 	Data      map[string]interface{} `json:"data,omitempty"`
@@ -65,6 +70,9 @@ func NewAICompanionService() *AICompanionService {
 		recommendations:   make(map[string]*Recommendation),
 		lastActivityCheck: time.Now(),
 		isRunning:         false,
+		videoService:      NewVideoService(NewActivityService(), NewLibraryService(), NewPerformerService()),
+		pendingFiles:      make(map[string]*pendingFile),
+		diskLevels:        make(map[string]string),
 	}
 }
 
@@ -94,6 +102,11 @@ func (s *AICompanionService) Start() error {
 	go s.performPeriodicAnalysis()
 	go s.monitorActivityLogs()
 	go s.MonitorConsoleLogsForErrors()
+	go s.monitorLibraryAvailability()
+	go s.recordLibraryStats()
+	go s.monitorDiskSpace()
+	go s.refreshSavedSearchCounts()
+	go s.purgeExpiredTrash()
 
 	log.Println("✅ AI Companion Service started successfully")
 	return nil
@@ -113,6 +126,14 @@ func (s *AICompanionService) Stop() error {
 	// Cancel context
 	s.cancel()
 
+	// Drop files still waiting to settle
+	s.pendingMu.Lock()
+	for path, pending := range s.pendingFiles {
+		pending.timer.Stop()
+		delete(s.pendingFiles, path)
+	}
+	s.pendingMu.Unlock()
+
 	// Close all file watchers
 	for libID, watcher := range s.watchers {
 		if err := watcher.Close(); err != nil {
@@ -195,30 +216,86 @@ func (s *AICompanionService) startFileWatchers() error {
 	return nil
 }
 
-// addLibraryWatcher adds a file watcher for a specific library
+// fileSettleInterval is how long a new file's size must stay unchanged before it's ingested
+const fileSettleInterval = 3 * time.Second
+
+// pendingFile tracks a file that is still being written before ingestion
+type pendingFile struct {
+	libraryID   int64
+	libraryName string
+	lastSize    int64
+	timer       *time.Timer
+}
+
+// addLibraryWatcher adds a recursive file watcher for a specific library. The
+// tree is walked before taking the lock, as a large library takes a while.
 func (s *AICompanionService) addLibraryWatcher(id int64, name, path string) error {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return fmt.Errorf("failed to create watcher: %w", err)
 	}
 
-	// Add the library path to the watcher
-	if err := watcher.Add(path); err != nil {
+	// fsnotify isn't recursive, so every directory in the library gets its own watch
+	if err := s.watchTree(watcher, path, s.scanFilter(id)); err != nil {
 		watcher.Close()
 		return fmt.Errorf("failed to watch path: %w", err)
 	}
 
 	libKey := fmt.Sprintf("%d", id)
+	s.mu.Lock()
 	s.watchers[libKey] = watcher
+	s.mu.Unlock()
 
 	// Start monitoring this watcher
-	go s.watchLibrary(watcher, name, path)
+	go s.watchLibrary(watcher, id, name, path)
 
 	return nil
 }
 
+// scanFilter loads the library's scan filter, or nil if it can't be loaded
+func (s *AICompanionService) scanFilter(libraryID int64) *LibraryScanFilter {
+	library, err := s.videoService.libraryService.GetByID(libraryID)
+	if err != nil {
+		return nil
+	}
+	filter, err := s.videoService.libraryService.GetScanFilter(library)
+	if err != nil {
+		log.Printf("Failed to load settings for library %d: %v", libraryID, err)
+		return nil
+	}
+	return filter
+}
+
+// watchTree adds root and every directory below it that the library's settings
+// don't exclude to the watcher
+func (s *AICompanionService) watchTree(watcher *fsnotify.Watcher, root string, filter *LibraryScanFilter) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// The root must be watchable; unreadable subdirectories are skipped
+			if path == root {
+				return err
+			}
+			log.Printf("Skipping unreadable path %s: %v", path, err)
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if filter != nil && filter.SkipDir(path) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			if path == root {
+				return err
+			}
+			log.Printf("Failed to watch directory %s: %v", path, err)
+		}
+		return nil
+	})
+}
+
 // watchLibrary monitors file system events for a library
-func (s *AICompanionService) watchLibrary(watcher *fsnotify.Watcher, libraryName, libraryPath string) {
+func (s *AICompanionService) watchLibrary(watcher *fsnotify.Watcher, libraryID int64, libraryName, libraryPath string) {
 	for {
 		select {
 		case <-s.ctx.Done():
@@ -227,7 +304,7 @@ func (s *AICompanionService) watchLibrary(watcher *fsnotify.Watcher, libraryName
 			if !ok {
 				return
 			}
-			s.handleFileEvent(event, libraryName, libraryPath)
+			s.handleFileEvent(watcher, event, libraryID, libraryName)
 		case err, ok := <-watcher.Errors:
 			if !ok {
 				return
@@ -238,15 +315,46 @@ func (s *AICompanionService) watchLibrary(watcher *fsnotify.Watcher, libraryName
 }
 
 // handleFileEvent processes file system events
-func (s *AICompanionService) handleFileEvent(event fsnotify.Event, libraryName, libraryPath string) {
-	// Filter for video files only
-	ext := strings.ToLower(filepath.Ext(event.Name))
-	videoExts := map[string]bool{
-		".mp4": true, ".mkv": true, ".avi": true, ".mov": true,
-		".wmv": true, ".flv": true, ".webm": true, ".m4v": true,
+func (s *AICompanionService) handleFileEvent(watcher *fsnotify.Watcher, event fsnotify.Event, libraryID int64, libraryName string) {
+	filter := s.scanFilter(libraryID)
+
+	// New directories are watched too, and anything already copied into them is
+	// picked up since it was created before the watch existed
+	if event.Op&fsnotify.Create == fsnotify.Create {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if filter != nil && filter.SkipDir(event.Name) {
+				return
+			}
+			if err := s.watchTree(watcher, event.Name, filter); err != nil {
+				log.Printf("Failed to watch new directory %s: %v", event.Name, err)
+			}
+			s.queueExistingFiles(event.Name, libraryID, libraryName, filter)
+			return
+		}
 	}
 
-	if !videoExts[ext] {
+	// A removed or renamed directory takes its videos with it; the new location
+	// shows up as a create event and re-associates them by fingerprint. Other
+	// files, like subtitles or partial downloads, have nothing to mark.
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 && !IsSupportedVideoFile(event.Name) {
+		hasVideos, err := s.videoService.hasVideosUnder(event.Name)
+		if err != nil {
+			log.Printf("Failed to check %s for videos: %v", event.Name, err)
+			return
+		}
+		if hasVideos {
+			s.markPathMissing(event.Name, libraryID)
+		}
+		return
+	}
+
+	// Filter for video files the library's settings accept; size and duration
+	// limits are checked once the file has settled
+	if filter != nil {
+		if !filter.MatchFile(event.Name, -1) {
+			return
+		}
+	} else if !IsSupportedVideoFile(event.Name) {
 		return
 	}
 
@@ -270,31 +378,145 @@ func (s *AICompanionService) handleFileEvent(event fsnotify.Event, libraryName,
 		return // Ignore other events
 	}
 
-	// Emit event
-	s.emitEvent(CompanionEvent{
-		Type:   eventType,
-		Source: "file_watcher",
-		Message: message,
-		Data: map[string]interface{}{
-			"library":   libraryName,
-			"file_path": event.Name,
-			"operation": event.Op.String(),
-		},
-		Severity:  "info",
-		Timestamp: time.Now(),
+	// Writes are only interesting while a new file is still being copied
+	if eventType == "file_modified" {
+		if video, err := s.videoService.GetByFilePath(event.Name); err == nil && video.Status != models.VideoStatusMissing {
+			return
+		}
+	} else {
+		s.emitEvent(CompanionEvent{
+			Type:    eventType,
+			Source:  "file_watcher",
+			Message: message,
+			Data: map[string]interface{}{
+				"library":   libraryName,
+				"file_path": event.Name,
+				"operation": event.Op.String(),
+			},
+			Severity:  "info",
+			Timestamp: time.Now(),
+		})
+	}
+
+	switch eventType {
+	case "file_added", "file_modified":
+		s.queueFile(event.Name, libraryID, libraryName)
+	case "file_removed", "file_renamed":
+		s.cancelPendingFile(event.Name)
+		s.markPathMissing(event.Name, libraryID)
+	}
+}
+
+// queueExistingFiles queues every video file below dir for ingestion
+func (s *AICompanionService) queueExistingFiles(dir string, libraryID int64, libraryName string, filter *LibraryScanFilter) {
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if filter != nil && filter.SkipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if (filter != nil && filter.MatchFile(path, -1)) || (filter == nil && IsSupportedVideoFile(path)) {
+			s.queueFile(path, libraryID, libraryName)
+		}
+		return nil
 	})
+	if err != nil {
+		log.Printf("Failed to walk new directory %s: %v", dir, err)
+	}
+}
+
+// queueFile schedules a file for ingestion once it stops growing. Repeated events
+// for the same file push the check back instead of queueing it twice.
+func (s *AICompanionService) queueFile(filePath string, libraryID int64, libraryName string) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
 
-	// Autonomous decision: If new file, trigger analysis after short delay
-	if eventType == "file_added" {
-		go s.analyzeNewFile(event.Name, libraryName)
+	if pending, ok := s.pendingFiles[filePath]; ok {
+		pending.timer.Reset(fileSettleInterval)
+		return
 	}
+
+	pending := &pendingFile{
+		libraryID:   libraryID,
+		libraryName: libraryName,
+		lastSize:    -1,
+	}
+	pending.timer = time.AfterFunc(fileSettleInterval, func() {
+		s.checkPendingFile(filePath)
+	})
+	s.pendingFiles[filePath] = pending
+}
+
+// cancelPendingFile drops a queued file that went away before it settled
+func (s *AICompanionService) cancelPendingFile(filePath string) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	if pending, ok := s.pendingFiles[filePath]; ok {
+		pending.timer.Stop()
+		delete(s.pendingFiles, filePath)
+	}
+}
+
+// checkPendingFile ingests a queued file if its size hasn't changed since the last check
+func (s *AICompanionService) checkPendingFile(filePath string) {
+	s.pendingMu.Lock()
+	pending, ok := s.pendingFiles[filePath]
+	if !ok {
+		s.pendingMu.Unlock()
+		return
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil || s.ctx.Err() != nil {
+		delete(s.pendingFiles, filePath)
+		s.pendingMu.Unlock()
+		return
+	}
+
+	if info.Size() != pending.lastSize {
+		pending.lastSize = info.Size()
+		pending.timer.Reset(fileSettleInterval)
+		s.pendingMu.Unlock()
+		return
+	}
+
+	delete(s.pendingFiles, filePath)
+	s.pendingMu.Unlock()
+
+	s.analyzeNewFile(filePath, pending.libraryID, pending.libraryName)
 }
 
-// analyzeNewFile performs autonomous analysis on a new file
-func (s *AICompanionService) analyzeNewFile(filePath, libraryName string) {
-	// Wait a bit to ensure file is fully written
-	time.Sleep(5 * time.Second)
+// markPathMissing keeps the database in sync with a file or directory that disappeared.
+// Nothing is marked when the whole library went offline, since an unmount shows up
+// as its directories being removed.
+func (s *AICompanionService) markPathMissing(path string, libraryID int64) {
+	library, err := s.videoService.libraryService.GetByID(libraryID)
+	if err != nil {
+		log.Printf("Failed to load library %d: %v", libraryID, err)
+		return
+	}
+	if err := s.videoService.libraryService.EnsureOnline(library); err != nil {
+		log.Printf("Not marking %s missing: %v", path, err)
+		return
+	}
 
+	count, err := s.videoService.MarkPathMissing(path)
+	if err != nil {
+		log.Printf("Failed to mark %s missing: %v", path, err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Marked %d video(s) missing under %s", count, path)
+	}
+}
+
+// analyzeNewFile ingests a settled file through the video service
+func (s *AICompanionService) analyzeNewFile(filePath string, libraryID int64, libraryName string) {
 	// Emit analysis event
 	s.emitEvent(CompanionEvent{
 		Type:    "insight",
@@ -308,7 +530,41 @@ func (s *AICompanionService) analyzeNewFile(filePath, libraryName string) {
 		Timestamp: time.Now(),
 	})
 
-	// TODO: Trigger actual analysis (metadata extraction, performer detection, etc.)
+	video, added, err := s.videoService.IngestFile(libraryID, filePath)
+	if err == nil && video == nil {
+		log.Printf("Skipped %s: excluded by library settings", filePath)
+		return
+	}
+	if err != nil {
+		s.emitEvent(CompanionEvent{
+			Type:    "notification",
+			Source:  "analysis_engine",
+			Message: fmt.Sprintf("Failed to ingest %s: %v", filepath.Base(filePath), err),
+			Data: map[string]interface{}{
+				"file_path": filePath,
+				"library":   libraryName,
+			},
+			Severity:  "error",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	if !added {
+		return // Already in the library
+	}
+
+	s.emitEvent(CompanionEvent{
+		Type:    "file_ingested",
+		Source:  "analysis_engine",
+		Message: fmt.Sprintf("Added %s to %s", filepath.Base(filePath), libraryName),
+		Data: map[string]interface{}{
+			"video_id":  video.ID,
+			"file_path": filePath,
+			"library":   libraryName,
+		},
+		Severity:  "info",
+		Timestamp: time.Now(),
+	})
 }
 
 // processEvents processes events and broadcasts to subscribers
@@ -510,6 +766,227 @@ func (s *AICompanionService) checkLibraryHealth() {
 	}
 }
 
+// libraryStatusInterval is how often library roots are probed for availability
+const libraryStatusInterval = 1 * time.Minute
+
+// monitorLibraryAvailability periodically checks that every library root is reachable
+func (s *AICompanionService) monitorLibraryAvailability() {
+	ticker := time.NewTicker(libraryStatusInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkLibraryAvailability()
+		}
+	}
+}
+
+// checkLibraryAvailability reports libraries that went offline or came back, and
+// re-watches and rescans returning libraries to catch up on changes made meanwhile
+func (s *AICompanionService) checkLibraryAvailability() {
+	changed, err := s.videoService.libraryService.CheckAllStatuses()
+	if err != nil {
+		log.Printf("Failed to check library availability: %v", err)
+		return
+	}
+
+	for _, lib := range changed {
+		if lib.Status == models.LibraryStatusOffline {
+			s.emitEvent(CompanionEvent{
+				Type:    "library_offline",
+				Source:  "health_monitor",
+				Message: fmt.Sprintf("Library %s is offline: %s can't be read", lib.Name, lib.Path),
+				Data: map[string]interface{}{
+					"library_id": lib.ID,
+					"library":    lib.Name,
+					"path":       lib.Path,
+				},
+				Severity:  "warning",
+				Timestamp: time.Now(),
+			})
+			continue
+		}
+
+		s.emitEvent(CompanionEvent{
+			Type:    "library_online",
+			Source:  "health_monitor",
+			Message: fmt.Sprintf("Library %s is back online", lib.Name),
+			Data: map[string]interface{}{
+				"library_id": lib.ID,
+				"library":    lib.Name,
+				"path":       lib.Path,
+			},
+			Severity:  "info",
+			Timestamp: time.Now(),
+		})
+
+		// The old watches died with the mount
+		s.mu.Lock()
+		libKey := fmt.Sprintf("%d", lib.ID)
+		watcher, ok := s.watchers[libKey]
+		delete(s.watchers, libKey)
+		s.mu.Unlock()
+		if ok {
+			if err := watcher.Close(); err != nil {
+				log.Printf("Error closing watcher for library %s: %v", lib.Name, err)
+			}
+		}
+		if err := s.addLibraryWatcher(lib.ID, lib.Name, lib.Path); err != nil {
+			log.Printf("Warning: Failed to re-watch library '%s': %v", lib.Name, err)
+		}
+
+		go func(id int64, name string) {
+			if err := s.videoService.ScanLibrary(id); err != nil {
+				log.Printf("Failed to rescan library %s after it came back online: %v", name, err)
+			}
+		}(lib.ID, lib.Name)
+	}
+}
+
+// recordLibraryStats keeps today's library_stats_history rows current. Snapshots
+// are upserted per day, so refreshing hourly leaves each day's final numbers behind.
+func (s *AICompanionService) recordLibraryStats() {
+	libraryService := s.videoService.libraryService
+	if err := libraryService.SnapshotAll(); err != nil {
+		log.Printf("Failed to record library stats: %v", err)
+	}
+
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := libraryService.SnapshotAll(); err != nil {
+				log.Printf("Failed to record library stats: %v", err)
+			}
+		}
+	}
+}
+
+// refreshSavedSearchCounts periodically recounts saved searches so their badges stay current
+func (s *AICompanionService) refreshSavedSearchCounts() {
+	savedSearchService := NewSavedSearchService()
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := savedSearchService.RefreshAllCounts(); err != nil {
+				log.Printf("Failed to refresh saved search counts: %v", err)
+			}
+		}
+	}
+}
+
+// purgeExpiredTrash periodically deletes trash items older than the retention period
+func (s *AICompanionService) purgeExpiredTrash() {
+	trashService := NewTrashService()
+	purge := func() {
+		purged, err := trashService.PurgeExpired()
+		if err != nil {
+			log.Printf("Failed to purge trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d expired trash items", purged)
+		}
+	}
+	purge()
+
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			purge()
+		}
+	}
+}
+
+// monitorDiskSpace periodically checks free space on library and asset filesystems
+func (s *AICompanionService) monitorDiskSpace() {
+	s.checkDiskSpace()
+
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkDiskSpace()
+		}
+	}
+}
+
+// checkDiskSpace emits a warning or critical event when a filesystem crosses a
+// threshold, and an info event once it recovers. Levels are remembered so a full
+// disk isn't reported every check.
+func (s *AICompanionService) checkDiskSpace() {
+	usages, err := s.videoService.libraryService.GetDiskUsage()
+	if err != nil {
+		log.Printf("Failed to check disk space: %v", err)
+		return
+	}
+
+	for _, usage := range usages {
+		if usage.Error != "" {
+			continue
+		}
+
+		s.mu.Lock()
+		previous, seen := s.diskLevels[usage.Path]
+		s.diskLevels[usage.Path] = usage.Level
+		s.mu.Unlock()
+		if usage.Level == previous || (!seen && usage.Level == models.DiskLevelOK) {
+			continue
+		}
+
+		data := map[string]interface{}{
+			"label":        usage.Label,
+			"path":         usage.Path,
+			"free_bytes":   usage.FreeBytes,
+			"total_bytes":  usage.TotalBytes,
+			"free_percent": usage.FreePercent,
+		}
+		if usage.LibraryID != nil {
+			data["library_id"] = *usage.LibraryID
+		}
+
+		message := fmt.Sprintf("Disk space for %s is back to normal: %.1f%% free", usage.Label, usage.FreePercent)
+		severity := "info"
+		if usage.Level != models.DiskLevelOK {
+			severity = usage.Level
+			message = fmt.Sprintf("Low disk space for %s: %.2f GB free (%.1f%%)",
+				usage.Label, float64(usage.FreeBytes)/(1024*1024*1024), usage.FreePercent)
+			if usage.DaysUntilFull != nil {
+				data["days_until_full"] = *usage.DaysUntilFull
+				message += fmt.Sprintf(", full in about %.0f days at the current ingestion rate", *usage.DaysUntilFull)
+			}
+		}
+
+		s.emitEvent(CompanionEvent{
+			Type:      "disk_space",
+			Source:    "health_monitor",
+			Message:   message,
+			Data:      data,
+			Severity:  severity,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
 // performPeriodicAnalysis runs automated analysis tasks
 func (s *AICompanionService) performPeriodicAnalysis() {
 	ticker := time.NewTicker(6 * time.Hour)
@@ -970,52 +1447,76 @@ func (s *AICompanionService) AnalyzeStorageUsage() (string, error) {
 		response.WriteString(fmt.Sprintf("- %s: %d videos, %.2f GB\n", name, count, float64(size)/(1024*1024*1024)))
 	}
 
+	if growth, err := s.videoService.libraryService.growthSince(time.Now().AddDate(0, 0, -30), 0); err == nil && growth != nil {
+		response.WriteString(fmt.Sprintf("\nGrowth over the last %.0f days: %+d videos, %+.2f GB\n",
+			growth.Days, growth.EndCount-growth.StartCount, float64(growth.EndBytes-growth.StartBytes)/(1024*1024*1024)))
+	}
+
 	return response.String(), nil
 }
 
 // ================== Predictive Analytics & Insights ==================
 
-// PredictLibraryGrowth analyzes library growth trends and predicts future growth
+// PredictLibraryGrowth analyzes library growth trends and predicts future growth.
+// Daily library snapshots are used when there are at least two days of history;
+// otherwise growth is estimated from when videos were added.
 func (s *AICompanionService) PredictLibraryGrowth() (string, error) {
-	// Get video creation timestamps
-	query := `
-		SELECT DATE(created_at) as date, COUNT(*) as count
-		FROM videos
-		WHERE created_at >= DATE('now', '-30 days')
-		GROUP BY DATE(created_at)
-		ORDER BY date DESC
-	`
+	// Get total videos
+	var totalVideos int
+	var totalBytes int64
+	s.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(file_size), 0) FROM videos WHERE COALESCE(status, 'available') = ?",
+		models.VideoStatusAvailable).Scan(&totalVideos, &totalBytes)
 
-	rows, err := s.db.Query(query)
+	growth, err := s.videoService.libraryService.growthSince(time.Now().AddDate(0, 0, -90), 0)
 	if err != nil {
 		return "", err
 	}
-	defer rows.Close()
 
-	dailyCounts := []int{}
-	for rows.Next() {
-		var date string
-		var count int
-		if err := rows.Scan(&date, &count); err != nil {
-			continue
+	var avgPerDay, bytesPerDay float64
+	source := "library snapshots"
+	if growth != nil {
+		avgPerDay = growth.VideosPerDay()
+		bytesPerDay = growth.BytesPerDay()
+		source = fmt.Sprintf("%.0f days of library snapshots", growth.Days)
+	} else {
+		// Get video creation timestamps
+		query := `
+			SELECT DATE(created_at) as date, COUNT(*) as count, COALESCE(SUM(file_size), 0) as size
+			FROM videos
+			WHERE created_at >= DATE('now', '-30 days')
+			GROUP BY DATE(created_at)
+			ORDER BY date DESC
+		`
+
+		rows, err := s.db.Query(query)
+		if err != nil {
+			return "", err
 		}
-		dailyCounts = append(dailyCounts, count)
-	}
+		defer rows.Close()
 
-	if len(dailyCounts) == 0 {
-		return "📊 Not enough data to predict library growth. Add more videos over time to see trends!", nil
-	}
+		days, total := 0, 0
+		var size int64
+		for rows.Next() {
+			var date string
+			var count int
+			var bytes int64
+			if err := rows.Scan(&date, &count, &bytes); err != nil {
+				continue
+			}
+			days++
+			total += count
+			size += bytes
+		}
 
-	// Calculate average daily growth
-	total := 0
-	for _, count := range dailyCounts {
-		total += count
-	}
-	avgPerDay := float64(total) / float64(len(dailyCounts))
+		if days == 0 {
+			return "📊 Not enough data to predict library growth. Add more videos over time to see trends!", nil
+		}
 
-	// Get total videos
-	var totalVideos int
-	s.db.QueryRow("SELECT COUNT(*) FROM videos").Scan(&totalVideos)
+		// Calculate average daily growth
+		avgPerDay = float64(total) / float64(days)
+		bytesPerDay = float64(size) / float64(days)
+		source = "recently added videos"
+	}
 
 	// Predict next 30 days
 	predicted30Days := int(avgPerDay * 30)
@@ -1023,11 +1524,14 @@ func (s *AICompanionService) PredictLibraryGrowth() (string, error) {
 
 	var response strings.Builder
 	response.WriteString("📈 Library Growth Prediction:\n\n")
-	response.WriteString(fmt.Sprintf("Current Library Size: %d videos\n", totalVideos))
-	response.WriteString(fmt.Sprintf("Average Daily Growth: %.1f videos/day\n\n", avgPerDay))
+	response.WriteString(fmt.Sprintf("Current Library Size: %d videos, %.2f GB\n", totalVideos, float64(totalBytes)/(1024*1024*1024)))
+	response.WriteString(fmt.Sprintf("Average Daily Growth: %.1f videos/day, %.2f GB/day (from %s)\n\n",
+		avgPerDay, bytesPerDay/(1024*1024*1024), source))
 	response.WriteString("Predictions:\n")
-	response.WriteString(fmt.Sprintf("• In 30 days: ~%d videos (+%d)\n", totalVideos+predicted30Days, predicted30Days))
-	response.WriteString(fmt.Sprintf("• In 90 days: ~%d videos (+%d)\n", totalVideos+predicted90Days, predicted90Days))
+	response.WriteString(fmt.Sprintf("• In 30 days: ~%d videos (%+d), ~%.2f GB\n",
+		totalVideos+predicted30Days, predicted30Days, (float64(totalBytes)+bytesPerDay*30)/(1024*1024*1024)))
+	response.WriteString(fmt.Sprintf("• In 90 days: ~%d videos (%+d), ~%.2f GB\n",
+		totalVideos+predicted90Days, predicted90Days, (float64(totalBytes)+bytesPerDay*90)/(1024*1024*1024)))
 
 	if avgPerDay > 10 {
 		response.WriteString("\n🚀 Your library is growing rapidly! Consider scheduling regular maintenance tasks.")
@@ -1035,6 +1539,8 @@ func (s *AICompanionService) PredictLibraryGrowth() (string, error) {
 		response.WriteString("\n📊 Steady growth detected. Your library is expanding at a healthy pace.")
 	} else if avgPerDay > 0 {
 		response.WriteString("\n🌱 Slow but steady growth. Perfect for manageable library maintenance.")
+	} else if avgPerDay < 0 {
+		response.WriteString("\n📉 Your library has been shrinking. Check for cleanups or missing drives.")
 	}
 
 	return response.String(), nil
@@ -49,25 +49,45 @@ var commonPrefixes = []string{
 
 // AutoLinkPerformers analyzes video filenames and suggests performer links
 func (s *AIService) AutoLinkPerformers(videoIDs []int64, autoApply bool) ([]PerformerLinkSuggestion, error) {
+	suggestions, applied, err := s.autoLinkPerformers(videoIDs, autoApply)
+	if err != nil {
+		return nil, err
+	}
+	s.journal(models.OperationPerformerLink, fmt.Sprintf("Auto-linked %d performers to videos", len(applied)), applied)
+	return suggestions, nil
+}
+
+// LinkPerformersOnIngest links high-confidence performer matches to newly
+// added videos. Like other automatic ingest steps it isn't journaled, so
+// ingesting many files can't push user operations out of the journal.
+func (s *AIService) LinkPerformersOnIngest(videoIDs []int64) error {
+	_, _, err := s.autoLinkPerformers(videoIDs, true)
+	return err
+}
+
+// autoLinkPerformers finds performer matches for videos, linking the
+// high-confidence ones if autoApply is set, and returns the links it made
+func (s *AIService) autoLinkPerformers(videoIDs []int64, autoApply bool) ([]PerformerLinkSuggestion, []models.OperationChange, error) {
 	log.Printf("Starting auto-link analysis for %d videos (auto-apply: %v)", len(videoIDs), autoApply)
 
 	// Get all performers
 	performers, err := s.getAllPerformers()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get performers: %w", err)
+		return nil, nil, fmt.Errorf("failed to get performers: %w", err)
 	}
 
 	if len(performers) == 0 {
-		return []PerformerLinkSuggestion{}, nil
+		return []PerformerLinkSuggestion{}, nil, nil
 	}
 
 	// Get videos to analyze
 	videos, err := s.getVideosForAnalysis(videoIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get videos: %w", err)
+		return nil, nil, fmt.Errorf("failed to get videos: %w", err)
 	}
 
 	suggestions := []PerformerLinkSuggestion{}
+	var applied []models.OperationChange
 
 	for _, video := range videos {
 		matches := s.findPerformerMatches(video, performers)
@@ -83,13 +103,13 @@ func (s *AIService) AutoLinkPerformers(videoIDs []int64, autoApply bool) ([]Perf
 
 			// Auto-apply if requested and confidence is high
 			if autoApply {
-				s.applyHighConfidenceMatches(video.ID, matches)
+				applied = append(applied, s.applyHighConfidenceMatches(video.ID, matches)...)
 			}
 		}
 	}
 
 	log.Printf("Found %d videos with performer matches", len(suggestions))
-	return suggestions, nil
+	return suggestions, applied, nil
 }
 
 // findPerformerMatches finds all potential performer matches for a video
@@ -106,6 +126,13 @@ func (s *AIService) findPerformerMatches(video models.Video, performers []models
 	for _, performer := range performers {
 		confidence, matchType := s.calculateMatch(cleanedName, performer.Name)
 
+		// A performer's aliases count as much as their name
+		for _, alias := range performer.Aliases {
+			if aliasConfidence, aliasType := s.calculateMatch(cleanedName, alias.Alias); aliasConfidence > confidence {
+				confidence, matchType = aliasConfidence, aliasType
+			}
+		}
+
 		if confidence >= 0.6 { // Minimum 60% confidence
 			match := PerformerMatch{
 				VideoID:       video.ID,
@@ -157,10 +184,14 @@ func (s *AIService) cleanFilename(filename string) string {
 	return filename
 }
 
-// calculateMatch calculates the confidence score for a performer match
+// calculateMatch calculates the confidence score for a performer match. Case,
+// accents and punctuation are ignored.
 func (s *AIService) calculateMatch(filename, performerName string) (float64, string) {
-	filenameLower := strings.ToLower(filename)
-	performerLower := strings.ToLower(performerName)
+	filenameLower := normalizePerformerName(filename)
+	performerLower := normalizePerformerName(performerName)
+	if performerLower == "" {
+		return 0.0, "none"
+	}
 
 	// Exact match (case-insensitive)
 	if strings.Contains(filenameLower, performerLower) {
@@ -168,7 +199,7 @@ func (s *AIService) calculateMatch(filename, performerName string) (float64, str
 	}
 
 	// Split performer name into parts
-	nameParts := strings.Fields(performerName)
+	nameParts := strings.Fields(performerLower)
 	if len(nameParts) < 2 {
 		return 0.0, "none"
 	}
@@ -176,9 +207,10 @@ func (s *AIService) calculateMatch(filename, performerName string) (float64, str
 	firstName := strings.ToLower(nameParts[0])
 	lastName := strings.ToLower(nameParts[len(nameParts)-1])
 
-	// Check for first + last name match
-	hasFirst := strings.Contains(filenameLower, firstName)
-	hasLast := strings.Contains(filenameLower, lastName)
+	// Check for first + last name match. Initials, as in aliases like "Jane D",
+	// would match almost any filename.
+	hasFirst := len(firstName) > 2 && strings.Contains(filenameLower, firstName)
+	hasLast := len(lastName) > 2 && strings.Contains(filenameLower, lastName)
 
 	if hasFirst && hasLast {
 		// Both names present - high confidence
@@ -211,7 +243,9 @@ func (s *AIService) calculateMatch(filename, performerName string) (float64, str
 }
 
 // applyHighConfidenceMatches automatically applies matches with high confidence
-func (s *AIService) applyHighConfidenceMatches(videoID int64, matches []PerformerMatch) {
+// and returns the links it added
+func (s *AIService) applyHighConfidenceMatches(videoID int64, matches []PerformerMatch) []models.OperationChange {
+	var applied []models.OperationChange
 	appliedCount := 0
 	skippedLowConfidence := 0
 	skippedAlreadyExists := 0
@@ -232,6 +266,7 @@ func (s *AIService) applyHighConfidenceMatches(videoID int64, matches []Performe
 			} else {
 				log.Printf("Auto-linked performer '%s' to video %d (confidence: %.2f)", match.PerformerName, videoID, match.Confidence)
 				appliedCount++
+				applied = append(applied, linkChange(models.ChangeRowAdded, "video_performers", videoID, match.PerformerID))
 			}
 		} else {
 			skippedLowConfidence++
@@ -242,15 +277,19 @@ func (s *AIService) applyHighConfidenceMatches(videoID int64, matches []Performe
 		log.Printf("Video %d: Applied %d links, Skipped %d (low confidence), Skipped %d (already exists)",
 			videoID, appliedCount, skippedLowConfidence, skippedAlreadyExists)
 	}
+	return applied
 }
 
 // ApplySuggestions applies selected performer links
 func (s *AIService) ApplySuggestions(suggestions []PerformerMatch) error {
+	var applied []models.OperationChange
+	var applyErr error
 	for _, match := range suggestions {
 		// Check if link already exists
 		exists, err := s.performerLinkExists(match.VideoID, match.PerformerID)
 		if err != nil {
-			return fmt.Errorf("failed to check existing link: %w", err)
+			applyErr = fmt.Errorf("failed to check existing link: %w", err)
+			break
 		}
 		if exists {
 			continue
@@ -259,15 +298,25 @@ func (s *AIService) ApplySuggestions(suggestions []PerformerMatch) error {
 		// Create the link
 		err = s.linkPerformerToVideo(match.VideoID, match.PerformerID)
 		if err != nil {
-			return fmt.Errorf("failed to link performer %d to video %d: %w", match.PerformerID, match.VideoID, err)
+			applyErr = fmt.Errorf("failed to link performer %d to video %d: %w", match.PerformerID, match.VideoID, err)
+			break
 		}
+		applied = append(applied, linkChange(models.ChangeRowAdded, "video_performers", match.VideoID, match.PerformerID))
 	}
 
-	return nil
+	s.journal(models.OperationPerformerLink, fmt.Sprintf("Applied %d performer links", len(applied)), applied)
+	return applyErr
 }
 
 // Helper functions
 
+// journal records the links or tags an AI action applied so they can be undone
+func (s *AIService) journal(opType, summary string, changes []models.OperationChange) {
+	if _, err := journalOperation(s.db, opType, summary, changes); err != nil {
+		log.Printf("Failed to journal %s: %v", opType, err)
+	}
+}
+
 func (s *AIService) getAllPerformers() ([]models.Performer, error) {
 	query := `SELECT id, name FROM performers ORDER BY name`
 	rows, err := s.db.Query(query)
@@ -277,15 +326,36 @@ func (s *AIService) getAllPerformers() ([]models.Performer, error) {
 	defer rows.Close()
 
 	performers := []models.Performer{}
+	index := make(map[int64]int)
 	for rows.Next() {
 		var p models.Performer
 		if err := rows.Scan(&p.ID, &p.Name); err != nil {
 			return nil, err
 		}
+		index[p.ID] = len(performers)
 		performers = append(performers, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	aliasRows, err := s.db.Query(`SELECT id, performer_id, alias, source FROM performer_aliases`)
+	if err != nil {
+		return nil, err
+	}
+	defer aliasRows.Close()
+
+	for aliasRows.Next() {
+		var a models.PerformerAlias
+		if err := aliasRows.Scan(&a.ID, &a.PerformerID, &a.Alias, &a.Source); err != nil {
+			return nil, err
+		}
+		if i, ok := index[a.PerformerID]; ok {
+			performers[i].Aliases = append(performers[i].Aliases, a)
+		}
+	}
 
-	return performers, nil
+	return performers, aliasRows.Err()
 }
 
 func (s *AIService) getVideosForAnalysis(videoIDs []int64) ([]models.Video, error) {
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// autoTagBatchSize is how many video IDs one rule query matches at a time
+const autoTagBatchSize = 500
+
+// defaultAutoTagPreviewLimit is how many videos a preview lists by default
+const defaultAutoTagPreviewLimit = 100
+
+// AutoTagService stores auto-tag rules and applies them to videos
+type AutoTagService struct {
+	db              *sql.DB
+	activityService *ActivityService
+}
+
+// NewAutoTagService creates a new auto-tag service
+func NewAutoTagService() *AutoTagService {
+	return &AutoTagService{
+		db:              database.GetDB(),
+		activityService: NewActivityService(),
+	}
+}
+
+// autoTagRuleColumns lists the columns scanAutoTagRule expects, in order
+const autoTagRuleColumns = `id, name, condition, tag_ids, priority, enabled, on_ingest, created_at, updated_at`
+
+// scanAutoTagRule reads a rule from a row selected with autoTagRuleColumns
+func scanAutoTagRule(row interface{ Scan(...interface{}) error }) (*models.AutoTagRule, error) {
+	var rule models.AutoTagRule
+	var tagIDs string
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Condition, &tagIDs, &rule.Priority,
+		&rule.Enabled, &rule.OnIngest, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tagIDs), &rule.TagIDs); err != nil {
+		return nil, fmt.Errorf("failed to parse rule tags: %w", err)
+	}
+	return &rule, nil
+}
+
+// GetRules retrieves every auto-tag rule in the order they run
+func (s *AutoTagService) GetRules() ([]models.AutoTagRule, error) {
+	rows, err := s.db.Query(`SELECT ` + autoTagRuleColumns + ` FROM auto_tag_rules ORDER BY priority ASC, id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query auto-tag rules: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	rules := []models.AutoTagRule{}
+	for rows.Next() {
+		rule, err := scanAutoTagRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan auto-tag rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetRule retrieves an auto-tag rule by ID
+func (s *AutoTagService) GetRule(id int64) (*models.AutoTagRule, error) {
+	rule, err := scanAutoTagRule(s.db.QueryRow(`SELECT `+autoTagRuleColumns+` FROM auto_tag_rules WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("auto-tag rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query auto-tag rule: %w", err)
+	}
+	return rule, nil
+}
+
+// validateRule checks a rule's condition compiles and its tags exist, and
+// drops duplicate tags
+func (s *AutoTagService) validateRule(rule *models.AutoTagRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	rule.Condition = strings.TrimSpace(rule.Condition)
+	if _, err := compileVideoQuery(rule.Condition, time.Now()); err != nil {
+		return err
+	}
+
+	seen := make(map[int64]bool, len(rule.TagIDs))
+	tagIDs := []int64{}
+	for _, tagID := range rule.TagIDs {
+		if !seen[tagID] {
+			seen[tagID] = true
+			tagIDs = append(tagIDs, tagID)
+		}
+	}
+	if len(tagIDs) == 0 {
+		return fmt.Errorf("at least one tag is required")
+	}
+	found, err := queryIDs(s.db, `SELECT id FROM tags WHERE id IN (?`+strings.Repeat(",?", len(tagIDs)-1)+`)`, idArgs(tagIDs)...)
+	if err != nil {
+		return fmt.Errorf("failed to query tags: %w", err)
+	}
+	if len(found) != len(tagIDs) {
+		return fmt.Errorf("tag not found")
+	}
+	rule.TagIDs = tagIDs
+	return nil
+}
+
+// CreateRule validates and stores a new auto-tag rule
+func (s *AutoTagService) CreateRule(create *models.AutoTagRuleCreate) (*models.AutoTagRule, error) {
+	rule := &models.AutoTagRule{
+		Name:      create.Name,
+		Condition: create.Condition,
+		TagIDs:    create.TagIDs,
+		Priority:  create.Priority,
+		Enabled:   true,
+		OnIngest:  true,
+	}
+	if create.Enabled != nil {
+		rule.Enabled = *create.Enabled
+	}
+	if create.OnIngest != nil {
+		rule.OnIngest = *create.OnIngest
+	}
+	if err := s.validateRule(rule); err != nil {
+		return nil, err
+	}
+	tagIDs, err := json.Marshal(rule.TagIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rule tags: %w", err)
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec(`
+		INSERT INTO auto_tag_rules (name, condition, tag_ids, priority, enabled, on_ingest, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.Name, rule.Condition, string(tagIDs), rule.Priority, rule.Enabled, rule.OnIngest, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auto-tag rule: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	return s.GetRule(id)
+}
+
+// UpdateRule updates an existing auto-tag rule
+func (s *AutoTagService) UpdateRule(id int64, update *models.AutoTagRuleUpdate) (*models.AutoTagRule, error) {
+	rule, err := s.GetRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		rule.Name = *update.Name
+	}
+	if update.Condition != nil {
+		rule.Condition = *update.Condition
+	}
+	if update.TagIDs != nil {
+		rule.TagIDs = update.TagIDs
+	}
+	if update.Priority != nil {
+		rule.Priority = *update.Priority
+	}
+	if update.Enabled != nil {
+		rule.Enabled = *update.Enabled
+	}
+	if update.OnIngest != nil {
+		rule.OnIngest = *update.OnIngest
+	}
+	if err := s.validateRule(rule); err != nil {
+		return nil, err
+	}
+	tagIDs, err := json.Marshal(rule.TagIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rule tags: %w", err)
+	}
+
+	rule.UpdatedAt = time.Now()
+	_, err = s.db.Exec(`
+		UPDATE auto_tag_rules
+		SET name = ?, condition = ?, tag_ids = ?, priority = ?, enabled = ?, on_ingest = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.Name, rule.Condition, string(tagIDs), rule.Priority, rule.Enabled, rule.OnIngest, rule.UpdatedAt, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update auto-tag rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule deletes an auto-tag rule
+func (s *AutoTagService) DeleteRule(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM auto_tag_rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete auto-tag rule: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("auto-tag rule not found")
+	}
+	return nil
+}
+
+// SelectRules returns the requested rules, or every enabled rule. It fails if
+// any requested rule doesn't exist.
+func (s *AutoTagService) SelectRules(ruleIDs []int64) ([]models.AutoTagRule, error) {
+	rules, err := s.GetRules()
+	if err != nil {
+		return nil, err
+	}
+	if len(ruleIDs) == 0 {
+		var enabled []models.AutoTagRule
+		for _, rule := range rules {
+			if rule.Enabled {
+				enabled = append(enabled, rule)
+			}
+		}
+		return enabled, nil
+	}
+
+	wanted := make(map[int64]bool, len(ruleIDs))
+	for _, id := range ruleIDs {
+		wanted[id] = true
+	}
+	var selected []models.AutoTagRule
+	for _, rule := range rules {
+		if wanted[rule.ID] {
+			selected = append(selected, rule)
+			delete(wanted, rule.ID)
+		}
+	}
+	for id := range wanted {
+		return nil, fmt.Errorf("auto-tag rule %d not found", id)
+	}
+	return selected, nil
+}
+
+// ruleMatches returns the tags a rule would add to each video, only looking at
+// videoIDs when any are given. Tags a video already has are left out, as are
+// missing videos unless the condition asks for them.
+func (s *AutoTagService) ruleMatches(rule models.AutoTagRule, videoIDs []int64) (map[int64][]int64, error) {
+	condition, err := compileVideoQuery(rule.Condition, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	// A rule's tags bring their ancestors
+	tagIDs, err := tagsWithAncestors(s.db, rule.TagIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+
+	base := fmt.Sprintf(`
+		SELECT v.id, t.id FROM videos v
+		JOIN tags t ON t.id IN (?%s)
+		WHERE (%s)
+		AND NOT EXISTS (SELECT 1 FROM video_tags vt WHERE vt.video_id = v.id AND vt.tag_id = t.id)
+	`, strings.Repeat(",?", len(tagIDs)-1), condition.where)
+	baseArgs := append(idArgs(tagIDs), condition.args...)
+	if !condition.filtersStatus {
+		base += ` AND COALESCE(v.status, 'available') != ?`
+		baseArgs = append(baseArgs, models.VideoStatusMissing)
+	}
+
+	matches := make(map[int64][]int64)
+	collect := func(query string, args []interface{}) error {
+		rows, err := s.db.Query(query+` ORDER BY v.id, t.id`, args...)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate rule %q: %w", rule.Name, err)
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				log.Printf("failed to close rows: %v", err)
+			}
+		}()
+		for rows.Next() {
+			var videoID, tagID int64
+			if err := rows.Scan(&videoID, &tagID); err != nil {
+				return fmt.Errorf("failed to scan rule match: %w", err)
+			}
+			matches[videoID] = append(matches[videoID], tagID)
+		}
+		return rows.Err()
+	}
+
+	if len(videoIDs) == 0 {
+		return matches, collect(base, baseArgs)
+	}
+	for start := 0; start < len(videoIDs); start += autoTagBatchSize {
+		batch := videoIDs[start:min(start+autoTagBatchSize, len(videoIDs))]
+		args := append(append([]interface{}{}, baseArgs...), idArgs(batch)...)
+		if err := collect(base+` AND v.id IN (?`+strings.Repeat(",?", len(batch)-1)+`)`, args); err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+// evaluate runs rules against the videos as they are now, so one rule's tags
+// don't trigger another in the same run, and returns the videos that would
+// change in ID order
+func (s *AutoTagService) evaluate(rules []models.AutoTagRule, videoIDs []int64) ([]models.AutoTagMatch, error) {
+	byVideo := make(map[int64]*models.AutoTagMatch)
+	for _, rule := range rules {
+		matches, err := s.ruleMatches(rule, videoIDs)
+		if err != nil {
+			return nil, err
+		}
+		for videoID, tagIDs := range matches {
+			match := byVideo[videoID]
+			if match == nil {
+				match = &models.AutoTagMatch{VideoID: videoID}
+				byVideo[videoID] = match
+			}
+			match.Rules = append(match.Rules, rule.Name)
+			for _, tagID := range tagIDs {
+				if !slices.Contains(match.TagIDs, tagID) {
+					match.TagIDs = append(match.TagIDs, tagID)
+				}
+			}
+		}
+	}
+
+	matches := make([]models.AutoTagMatch, 0, len(byVideo))
+	for _, match := range byVideo {
+		matches = append(matches, *match)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].VideoID < matches[j].VideoID })
+	return matches, nil
+}
+
+// describeMatches fills in video titles, paths and tag names for a preview
+func (s *AutoTagService) describeMatches(matches []models.AutoTagMatch) error {
+	tagNames := make(map[int64]string)
+	rows, err := s.db.Query(`SELECT id, name FROM tags`)
+	if err != nil {
+		return fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tagNames[id] = name
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query tags: %w", err)
+	}
+
+	for i := range matches {
+		match := &matches[i]
+		if err := s.db.QueryRow(`SELECT COALESCE(title, ''), file_path FROM videos WHERE id = ?`, match.VideoID).
+			Scan(&match.Title, &match.FilePath); err != nil {
+			return fmt.Errorf("failed to query video %d: %w", match.VideoID, err)
+		}
+		match.Tags = make([]string, len(match.TagIDs))
+		for j, tagID := range match.TagIDs {
+			match.Tags[j] = tagNames[tagID]
+		}
+	}
+	return nil
+}
+
+// Preview lists the videos a run would tag, without changing anything
+func (s *AutoTagService) Preview(req *models.AutoTagRunRequest) (*models.AutoTagPreview, error) {
+	rules, err := s.SelectRules(req.RuleIDs)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.evaluate(rules, req.VideoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.AutoTagPreview{Total: len(matches), Videos: []models.AutoTagMatch{}}
+	for _, match := range matches {
+		preview.TagsAdded += len(match.TagIDs)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultAutoTagPreviewLimit
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	if err := s.describeMatches(matches); err != nil {
+		return nil, err
+	}
+	preview.Videos = matches
+	return preview, nil
+}
+
+// Run applies rules from SelectRules to videoIDs, or to every video, as a
+// tracked activity. The tags added are journaled as one operation so the run
+// can be undone.
+func (s *AutoTagService) Run(rules []models.AutoTagRule, videoIDs []int64) (*models.AutoTagResult, error) {
+	activity, err := s.activityService.StartTask("auto_tagging",
+		fmt.Sprintf("Applying %d auto-tag rules", len(rules)),
+		map[string]interface{}{"rule_count": len(rules), "video_count": len(videoIDs)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create activity: %w", err)
+	}
+
+	matches, err := s.evaluate(rules, videoIDs)
+	if err != nil {
+		_ = s.activityService.FailTask(activity.ID, err.Error())
+		return nil, err
+	}
+	if err := s.activityService.UpdateProgress(activity.ID, 50, fmt.Sprintf("Tagging %d videos", len(matches))); err != nil {
+		log.Printf("Failed to update progress: %v", err)
+	}
+
+	result, err := s.apply(matches, true)
+	if err != nil {
+		_ = s.activityService.FailTask(activity.ID, err.Error())
+		return nil, err
+	}
+	return result, s.activityService.CompleteTask(int64(activity.ID),
+		fmt.Sprintf("Auto-tagging complete: %d tags added to %d videos", result.TagsAdded, result.VideosTagged))
+}
+
+// ApplyOnIngest applies the enabled rules marked for ingest to newly added
+// videos. It isn't journaled: a scan tags many videos one at a time, and its
+// entries would push user operations out of the journal.
+func (s *AutoTagService) ApplyOnIngest(videoIDs []int64) error {
+	if len(videoIDs) == 0 {
+		return nil
+	}
+	rules, err := s.SelectRules(nil)
+	if err != nil {
+		return err
+	}
+	var ingest []models.AutoTagRule
+	for _, rule := range rules {
+		if rule.OnIngest {
+			ingest = append(ingest, rule)
+		}
+	}
+	if len(ingest) == 0 {
+		return nil
+	}
+
+	matches, err := s.evaluate(ingest, videoIDs)
+	if err != nil {
+		return err
+	}
+	_, err = s.apply(matches, false)
+	return err
+}
+
+// apply adds the matched tags in one transaction, journaling them if asked to
+func (s *AutoTagService) apply(matches []models.AutoTagMatch, journal bool) (*models.AutoTagResult, error) {
+	result := &models.AutoTagResult{}
+	if len(matches) == 0 {
+		return result, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back auto-tagging: %v", err)
+		}
+	}()
+
+	var changes []models.OperationChange
+	for _, match := range matches {
+		links := make([]linkPair, len(match.TagIDs))
+		for i, tagID := range match.TagIDs {
+			links[i] = linkPair{match.VideoID, tagID}
+		}
+		added, err := insertVideoTags(tx, links)
+		if err != nil {
+			return nil, err
+		}
+		if len(added) > 0 {
+			changes = appendLinkChanges(changes, models.ChangeRowAdded, "video_tags", added)
+			result.TagsAdded += len(added)
+			result.VideosTagged++
+		}
+	}
+
+	if journal {
+		summary := fmt.Sprintf("Auto-tagged %d videos by rules", result.VideosTagged)
+		if result.OperationID, err = journalOperation(tx, models.OperationTagApply, summary, changes); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit auto-tagging: %w", err)
+	}
+	return result, nil
+}
@@ -38,6 +38,9 @@ func (s *BrowseService) BrowseLibrary(libraryID int64, relativePath string, extr
 	if err != nil {
 		return nil, fmt.Errorf("library not found: %w", err)
 	}
+	if err := s.libraryService.EnsureOnline(library); err != nil {
+		return nil, err
+	}
 
 	// Construct full path
 	fullPath := filepath.Join(library.Path, relativePath)
@@ -60,6 +63,12 @@ func (s *BrowseService) BrowseLibrary(libraryID int64, relativePath string, extr
 		return nil, fmt.Errorf("path is not a directory")
 	}
 
+	// Hide whatever the library's settings keep out of the index
+	filter, err := s.libraryService.GetScanFilter(library)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load library settings: %w", err)
+	}
+
 	// Read directory contents
 	entries, err := os.ReadDir(fullPath)
 	if err != nil {
@@ -71,9 +80,8 @@ func (s *BrowseService) BrowseLibrary(libraryID int64, relativePath string, extr
 	videoFileMap := make(map[string]bool)
 	for _, entry := range entries {
 		if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
-			ext := strings.ToLower(filepath.Ext(entry.Name()))
-			if isVideoFile(ext) {
-				itemFullPath := filepath.Join(fullPath, entry.Name())
+			itemFullPath := filepath.Join(fullPath, entry.Name())
+			if filter.MatchFile(itemFullPath, -1) {
 				videoFilePaths = append(videoFilePaths, itemFullPath)
 				videoFileMap[itemFullPath] = true
 			}
@@ -103,6 +111,10 @@ func (s *BrowseService) BrowseLibrary(libraryID int64, relativePath string, extr
 		// Get full path for this item
 		itemFullPath := filepath.Join(fullPath, entry.Name())
 
+		if entry.IsDir() && filter.SkipDir(itemFullPath) {
+			continue
+		}
+
 		item := models.BrowseItem{
 			Name:     entry.Name(),
 			Path:     filepath.Join(relativePath, entry.Name()),
@@ -117,7 +129,7 @@ func (s *BrowseService) BrowseLibrary(libraryID int64, relativePath string, extr
 			item.Type = "folder"
 		} else {
 			ext := strings.ToLower(filepath.Ext(entry.Name()))
-			if isVideoFile(ext) {
+			if filter.MatchFile(itemFullPath, itemInfo.Size()) {
 				item.Type = "video"
 				item.Extension = ext
 
@@ -151,6 +163,10 @@ func (s *BrowseService) BrowseLibrary(libraryID int64, relativePath string, extr
 				// Extract metadata if requested
 				if extractMetadata {
 					if metadata, err := s.mediaService.ExtractMetadata(itemFullPath); err == nil {
+						if !filter.MatchDuration(metadata.Duration) {
+							continue
+						}
+
 						item.Duration = metadata.Duration
 						item.Width = metadata.Width
 						item.Height = metadata.Height
@@ -175,7 +191,8 @@ func (s *BrowseService) BrowseLibrary(libraryID int64, relativePath string, extr
 						expectedThumbnail := s.mediaService.GetThumbnailPath(thumbnailConfig)
 						if expectedThumbnail != nil {
 							// Check if thumbnail exists
-							if !s.mediaService.ThumbnailExists(expectedThumbnail.FullPath) {
+							thumbnailExists := s.mediaService.ThumbnailExists(expectedThumbnail.FullPath)
+							if !thumbnailExists && filter.Settings.AutoThumbnails {
 								// Set thumbnail path immediately (will show placeholder until generated)
 								item.Thumbnail = expectedThumbnail.URLPath
 
@@ -212,7 +229,7 @@ func (s *BrowseService) BrowseLibrary(libraryID int64, relativePath string, extr
 										}
 									}
 								}(thumbnailConfig, entry.Name())
-							} else {
+							} else if thumbnailExists {
 								// Thumbnail already exists
 								item.Thumbnail = expectedThumbnail.URLPath
 							}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// GetByHash retrieves every video whose oshash or full MD5 matches hash. More than
+// one result means the same content is stored at several paths.
+func (s *VideoService) GetByHash(hash string) ([]models.Video, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+
+	rows, err := s.db.Query(`SELECT id FROM videos WHERE oshash = ? OR md5 = ? ORDER BY id`, hash, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query videos by hash: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan video id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	videos := []models.Video{}
+	for _, id := range ids {
+		video, err := s.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		videos = append(videos, *video)
+	}
+	return videos, nil
+}
+
+// hashJob is a video that still needs its full content hash
+type hashJob struct {
+	id       int64
+	filePath string
+	osHash   string
+}
+
+// ComputeContentHashes reads every available video without a full MD5 end to end
+// and stores it, backfilling the oshash along the way. libraryID 0 covers all libraries.
+func (s *VideoService) ComputeContentHashes(libraryID int64) error {
+	query := `
+		SELECT id, file_path, COALESCE(oshash, '')
+		FROM videos
+		WHERE COALESCE(status, 'available') = ? AND (md5 IS NULL OR md5 = '')
+	`
+	args := []interface{}{models.VideoStatusAvailable}
+	if libraryID > 0 {
+		query += " AND library_id = ?"
+		args = append(args, libraryID)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query videos to hash: %w", err)
+	}
+	var jobs []hashJob
+	for rows.Next() {
+		var job hashJob
+		if err := rows.Scan(&job.id, &job.filePath, &job.osHash); err != nil {
+			log.Printf("Error scanning video to hash: %v", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+		return fmt.Errorf("error iterating videos to hash: %w", err)
+	}
+	if err := rows.Close(); err != nil {
+		log.Printf("failed to close rows: %v", err)
+	}
+
+	activity, err := s.activityService.StartTask("content_hashing",
+		fmt.Sprintf("Computing content hashes for %d videos", len(jobs)),
+		map[string]interface{}{"library_id": libraryID, "total_videos": len(jobs)})
+	if err != nil {
+		return fmt.Errorf("failed to create activity: %w", err)
+	}
+
+	hashed, failed := 0, 0
+	for i, job := range jobs {
+		if job.osHash == "" {
+			if fp, err := computeFingerprint(job.filePath); err == nil {
+				if err := s.saveFingerprint(job.id, fp); err != nil {
+					log.Printf("Failed to backfill fingerprint for video %d: %v", job.id, err)
+				}
+			}
+		}
+
+		sum, err := fileMD5(job.filePath)
+		if err != nil {
+			log.Printf("Failed to hash video %d: %v", job.id, err)
+			failed++
+		} else if _, err := s.db.Exec(`UPDATE videos SET md5 = ? WHERE id = ?`, sum, job.id); err != nil {
+			log.Printf("Failed to save hash for video %d: %v", job.id, err)
+			failed++
+		} else {
+			hashed++
+		}
+
+		msg := fmt.Sprintf("Hashed %d/%d (Failed: %d)\nCurrent: %s", i+1, len(jobs), failed, filepath.Base(job.filePath))
+		if err := s.activityService.UpdateItemProgress(activity.ID, i+1, len(jobs), msg); err != nil {
+			log.Printf("Failed to update progress: %v", err)
+		}
+	}
+
+	return s.activityService.CompleteTask(int64(activity.ID),
+		fmt.Sprintf("Content hashing complete: %d hashed, %d failed", hashed, failed))
+}
+
+// fileMD5 streams a whole file through MD5
+func fileMD5(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Printf("failed to close %s: %v", path, err)
+		}
+	}()
+
+	hasher := md5.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
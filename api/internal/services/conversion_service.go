@@ -96,7 +96,7 @@ func (s *ConversionService) ConvertVideoToMP4(videoID int64) (*models.Video, err
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		errMsg := fmt.Sprintf("FFmpeg conversion failed: %v\nOutput: %s", err, string(output))
-		log.Printf(errMsg)
+		log.Print(errMsg)
 
 		if activity != nil {
 			s.activityService.FailTask(int(activity.ID), errMsg)
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package services
+
+import "syscall"
+
+// diskCapacity returns the total size and the space available to this process on
+// the filesystem holding path
+func diskCapacity(path string) (total, free int64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	blockSize := int64(stat.Bsize)
+	return int64(stat.Blocks) * blockSize, int64(stat.Bavail) * blockSize, nil
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package services
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// diskCapacity returns the total size and the space available to this process on
+// the volume holding path
+func diskCapacity(path string) (total, free int64, err error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	var available, totalBytes, totalFree uint64
+	ok, _, callErr := procGetDiskFreeSpaceEx.Call(
+		uintptr(unsafe.Pointer(name)),
+		uintptr(unsafe.Pointer(&available)),
+		uintptr(unsafe.Pointer(&totalBytes)),
+		uintptr(unsafe.Pointer(&totalFree)),
+	)
+	if ok == 0 {
+		return 0, 0, callErr
+	}
+	return int64(totalBytes), int64(available), nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// diskThresholdsKey is the app_settings key holding the disk space thresholds
+const diskThresholdsKey = "disk_space_thresholds"
+
+// defaultDiskThresholds apply until thresholds are configured
+var defaultDiskThresholds = models.DiskSpaceThresholds{
+	WarningPercent:  10,
+	CriticalPercent: 5,
+}
+
+// ingestionWindowDays is how far back the ingestion rate behind days-until-full looks
+const ingestionWindowDays = 30
+
+// GetDiskThresholds returns the configured disk space thresholds
+func (s *LibraryService) GetDiskThresholds() (*models.DiskSpaceThresholds, error) {
+	thresholds := defaultDiskThresholds
+
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM app_settings WHERE key = ?`, diskThresholdsKey).Scan(&value)
+	if err == sql.ErrNoRows {
+		return &thresholds, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load disk thresholds: %w", err)
+	}
+	if err := json.Unmarshal([]byte(value), &thresholds); err != nil {
+		return nil, fmt.Errorf("failed to parse disk thresholds: %w", err)
+	}
+	return &thresholds, nil
+}
+
+// UpdateDiskThresholds validates and stores new disk space thresholds
+func (s *LibraryService) UpdateDiskThresholds(update *models.DiskSpaceThresholdsUpdate) (*models.DiskSpaceThresholds, error) {
+	thresholds, err := s.GetDiskThresholds()
+	if err != nil {
+		return nil, err
+	}
+
+	if update.WarningPercent != nil {
+		thresholds.WarningPercent = *update.WarningPercent
+	}
+	if update.CriticalPercent != nil {
+		thresholds.CriticalPercent = *update.CriticalPercent
+	}
+	if update.WarningFreeBytes != nil {
+		thresholds.WarningFreeBytes = *update.WarningFreeBytes
+	}
+	if update.CriticalFreeBytes != nil {
+		thresholds.CriticalFreeBytes = *update.CriticalFreeBytes
+	}
+
+	if thresholds.WarningPercent < 0 || thresholds.WarningPercent > 100 ||
+		thresholds.CriticalPercent < 0 || thresholds.CriticalPercent > 100 {
+		return nil, fmt.Errorf("percentages must be between 0 and 100")
+	}
+	if thresholds.CriticalPercent > thresholds.WarningPercent {
+		return nil, fmt.Errorf("critical_percent must not exceed warning_percent")
+	}
+	if thresholds.WarningFreeBytes < 0 || thresholds.CriticalFreeBytes < 0 {
+		return nil, fmt.Errorf("free byte limits must not be negative")
+	}
+	if thresholds.WarningFreeBytes > 0 && thresholds.CriticalFreeBytes > thresholds.WarningFreeBytes {
+		return nil, fmt.Errorf("critical_free_bytes must not exceed warning_free_bytes")
+	}
+
+	value, err := json.Marshal(thresholds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode disk thresholds: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO app_settings (key, value, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, diskThresholdsKey, string(value), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to save disk thresholds: %w", err)
+	}
+	return thresholds, nil
+}
+
+// diskLevel classifies free space against the thresholds
+func diskLevel(thresholds *models.DiskSpaceThresholds, free int64, freePercent float64) string {
+	switch {
+	case freePercent < thresholds.CriticalPercent,
+		thresholds.CriticalFreeBytes > 0 && free < thresholds.CriticalFreeBytes:
+		return models.DiskLevelCritical
+	case freePercent < thresholds.WarningPercent,
+		thresholds.WarningFreeBytes > 0 && free < thresholds.WarningFreeBytes:
+		return models.DiskLevelWarning
+	default:
+		return models.DiskLevelOK
+	}
+}
+
+// probeDisk fills in the capacity of the filesystem holding usage.Path
+func probeDisk(usage *models.DiskUsage, thresholds *models.DiskSpaceThresholds) {
+	total, free, err := diskCapacity(usage.Path)
+	if err != nil {
+		usage.Error = err.Error()
+		return
+	}
+	usage.TotalBytes = total
+	usage.FreeBytes = free
+	if total > 0 {
+		usage.FreePercent = float64(free) / float64(total) * 100
+	}
+	usage.Level = diskLevel(thresholds, free, usage.FreePercent)
+}
+
+// ingestionRate is the average number of bytes added to a library per day recently
+func (s *LibraryService) ingestionRate(libraryID int64) (float64, error) {
+	var bytes int64
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(file_size), 0) FROM videos
+		WHERE library_id = ? AND created_at >= ?
+	`, libraryID, time.Now().AddDate(0, 0, -ingestionWindowDays)).Scan(&bytes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query ingestion rate: %w", err)
+	}
+	return float64(bytes) / ingestionWindowDays, nil
+}
+
+// LibraryDiskUsage probes the filesystem holding a library's root and projects
+// when it will fill up at the library's recent ingestion rate. Offline libraries
+// aren't probed, since statfs on a dead network mount can hang.
+func (s *LibraryService) LibraryDiskUsage(library *models.Library, thresholds *models.DiskSpaceThresholds) *models.DiskUsage {
+	libraryID := library.ID
+	usage := &models.DiskUsage{Label: library.Name, Path: library.Path, LibraryID: &libraryID}
+	if library.Status == models.LibraryStatusOffline {
+		usage.Error = ErrLibraryOffline.Error()
+		return usage
+	}
+
+	probeDisk(usage, thresholds)
+	if usage.Error != "" {
+		return usage
+	}
+
+	rate, err := s.ingestionRate(library.ID)
+	if err != nil {
+		usage.Error = err.Error()
+		return usage
+	}
+	usage.BytesPerDay = rate
+	if rate > 0 {
+		days := float64(usage.FreeBytes) / rate
+		usage.DaysUntilFull = &days
+	}
+	return usage
+}
+
+// GetDiskUsage probes every library root plus the thumbnail, preview and asset directories
+func (s *LibraryService) GetDiskUsage() ([]models.DiskUsage, error) {
+	thresholds, err := s.GetDiskThresholds()
+	if err != nil {
+		return nil, err
+	}
+	libraries, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	usages := []models.DiskUsage{}
+	for i := range libraries {
+		usages = append(usages, *s.LibraryDiskUsage(&libraries[i], thresholds))
+	}
+
+	assetsDir := os.Getenv("ASSETS_BASE_DIR")
+	if assetsDir == "" {
+		assetsDir = "assets"
+	}
+	for _, dir := range []struct{ label, path string }{
+		{"thumbnails", videoThumbnailDir()},
+		{"previews", videoPreviewDir()},
+		{"assets", assetsDir},
+	} {
+		usage := models.DiskUsage{Label: dir.label, Path: dir.path}
+		probeDisk(&usage, thresholds)
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
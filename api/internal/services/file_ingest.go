@@ -0,0 +1,226 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// IsSupportedVideoFile reports whether path has one of the supported video extensions
+func IsSupportedVideoFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, supportedExt := range SupportedVideoExtensions {
+		if ext == supportedExt {
+			return true
+		}
+	}
+	return false
+}
+
+// newVideoCreate extracts metadata for a file and builds the record to insert along
+// with the thumbnail config for its expected hierarchical thumbnail
+func newVideoCreate(mediaService *MediaService, library *models.Library, filePath string, size int64) (*models.VideoCreate, ThumbnailConfig) {
+	metadata, err := mediaService.ExtractMetadata(filePath)
+	if err != nil {
+		// If metadata extraction fails, still create the video with basic info
+		metadata = &VideoMetadata{
+			Duration: 0,
+			Size:     size,
+		}
+	}
+
+	resolution := ""
+	if metadata.Width > 0 && metadata.Height > 0 {
+		resolution = fmt.Sprintf("%dx%d", metadata.Width, metadata.Height)
+	}
+
+	thumbnailConfig := ThumbnailConfig{
+		LibraryID:     library.ID,
+		LibraryPath:   library.Path,
+		VideoFilePath: filePath,
+		Duration:      metadata.Duration,
+		ThumbnailDir:  videoThumbnailDir(),
+	}
+
+	thumbnailPath := ""
+	if expected := mediaService.GetThumbnailPath(thumbnailConfig); expected != nil {
+		thumbnailPath = expected.RelativePath
+	}
+
+	return &models.VideoCreate{
+		LibraryID:     library.ID,
+		Title:         filepath.Base(filePath),
+		FilePath:      filePath,
+		FileSize:      metadata.Size,
+		Duration:      metadata.Duration,
+		Codec:         metadata.Codec,
+		Resolution:    resolution,
+		Bitrate:       metadata.Bitrate,
+		FPS:           metadata.FrameRate,
+		ThumbnailPath: thumbnailPath,
+	}, thumbnailConfig
+}
+
+// IngestFile brings a single file into a library outside of a full scan. A known
+// path is simply marked available again, a file matching a missing video is treated
+// as a move, and anything else is added with its thumbnail, preview and performer links.
+// It returns a nil video when the library's settings exclude the file, and
+// reports whether the video was added or moved rather than already known.
+func (s *VideoService) IngestFile(libraryID int64, filePath string) (*models.Video, bool, error) {
+	library, err := s.libraryService.GetByID(libraryID)
+	if err != nil {
+		return nil, false, fmt.Errorf("library not found: %w", err)
+	}
+
+	filter, err := s.libraryService.GetScanFilter(library)
+	if err != nil {
+		return nil, false, err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if !filter.MatchFile(filePath, info.Size()) {
+		return nil, false, nil
+	}
+
+	if existing, err := s.GetByFilePath(filePath); err == nil && existing != nil {
+		if existing.Status == models.VideoStatusMissing {
+			if err := s.setVideoStatus(existing.ID, models.VideoStatusAvailable); err != nil {
+				return nil, false, err
+			}
+			existing.Status = models.VideoStatusAvailable
+			existing.MissingSince = nil
+		}
+		return existing, false, nil
+	}
+
+	fp, err := computeFingerprint(filePath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	missing, err := s.loadMissingVideos()
+	if err != nil {
+		log.Printf("Failed to load missing videos for move detection: %v", err)
+	}
+	if row := newMoveCandidates(missing).claim(fp); row != nil {
+		if err := s.applyMove(row, library, filePath, fp); err != nil {
+			return nil, false, err
+		}
+		moved, err := s.GetByID(row.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		return moved, true, nil
+	}
+
+	mediaService := NewMediaService()
+	create, thumbnailConfig := newVideoCreate(mediaService, library, filePath, fp.Size)
+	if !filter.MatchDuration(create.Duration) {
+		return nil, false, nil
+	}
+	if !filter.Settings.AutoThumbnails {
+		create.ThumbnailPath = ""
+	}
+
+	video, err := s.Create(create)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := s.saveFingerprint(video.ID, fp); err != nil {
+		log.Printf("Failed to save fingerprint for video %d: %v", video.ID, err)
+	}
+
+	if parseRules, err := NewFilenameParserService().LoadRuleSet(libraryID); err != nil {
+		log.Printf("Failed to load filename parse rules: %v", err)
+	} else if err := parseRules.ApplyToVideo(video.ID, filePath); err != nil {
+		log.Printf("Failed to apply filename parse rules to video %d: %v", video.ID, err)
+	}
+	if _, err := NewNFOService().ImportVideo(video.ID, models.NFOPolicyFill); err != nil {
+		log.Printf("Failed to import NFO for video %d: %v", video.ID, err)
+	}
+
+	if filter.Settings.AutoThumbnails {
+		if thumb, err := mediaService.GenerateThumbnailHierarchical(thumbnailConfig); err != nil {
+			log.Printf("Failed to generate thumbnail for video ID %d: %v", video.ID, err)
+		} else if err := s.updateVideoThumbnailPath(video.ID, thumb.RelativePath); err == nil {
+			video.ThumbnailPath = thumb.RelativePath
+		}
+	}
+
+	if filter.Settings.AutoPreviews && video.Duration > 0 {
+		preview, err := mediaService.GeneratePreviewStoryboard(PreviewConfig{
+			LibraryID:      library.ID,
+			LibraryPath:    library.Path,
+			VideoFilePath:  filePath,
+			Duration:       video.Duration,
+			PreviewDir:     videoPreviewDir(),
+			FrameCount:     10,
+			ThumbnailWidth: 320,
+		})
+		if err != nil {
+			log.Printf("Failed to generate preview for video ID %d: %v", video.ID, err)
+		} else if err := s.updateVideoPreviewPath(video.ID, preview.RelativePath); err == nil {
+			video.PreviewPath = preview.RelativePath
+		}
+	}
+
+	if err := NewAIService().LinkPerformersOnIngest([]int64{video.ID}); err != nil {
+		log.Printf("Failed to auto-link performers for video %d: %v", video.ID, err)
+	}
+	// Rules can match on linked performers, so they run after the links are made
+	if err := NewAutoTagService().ApplyOnIngest([]int64{video.ID}); err != nil {
+		log.Printf("Failed to apply auto-tag rules to video %d: %v", video.ID, err)
+	}
+
+	// Reload so parsed title, date and links are included
+	if reloaded, err := s.GetByID(video.ID); err == nil {
+		return reloaded, true, nil
+	}
+	return video, true, nil
+}
+
+// hasVideosUnder reports whether any video is indexed at path or inside it.
+// Paths inside it sort between path plus a separator and path plus the next
+// character, so the file_path index answers this without a table scan.
+func (s *VideoService) hasVideosUnder(path string) (bool, error) {
+	path = filepath.Clean(path)
+	var exists bool
+	err := s.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM videos WHERE file_path = ? OR (file_path >= ? AND file_path < ?))
+	`, path, path+string(os.PathSeparator), path+string(os.PathSeparator+1)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for videos under %s: %w", path, err)
+	}
+	return exists, nil
+}
+
+// MarkPathMissing marks the video at path, or every video below it when path was a
+// directory, as missing. Rows are kept so a later create event can re-associate them.
+func (s *VideoService) MarkPathMissing(path string) (int, error) {
+	path = filepath.Clean(path)
+	prefix := path + string(os.PathSeparator)
+
+	result, err := s.db.Exec(`
+		UPDATE videos SET status = ?, missing_since = COALESCE(missing_since, ?), updated_at = ?
+		WHERE COALESCE(status, 'available') != ?
+		AND (file_path = ? OR substr(file_path, 1, length(?)) = ?)
+	`, models.VideoStatusMissing, time.Now(), time.Now(), models.VideoStatusMissing,
+		path, prefix, prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark videos missing: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(affected), nil
+}
@@ -1,21 +1,37 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+	"github.com/brixen96/video-storage-ai/internal/models"
 )
 
-// FileService handles file operations
+// FileService handles file operations. Moves and renames keep the videos table
+// and the thumbnail and preview asset trees in step with the disk; deletes go
+// through the trash.
 type FileService struct {
+	db             *sql.DB
 	libraryService *LibraryService
+	mediaService   *MediaService
+	trashService   *TrashService
 }
 
 // NewFileService creates a new file service
 func NewFileService() *FileService {
 	return &FileService{
+		db:             database.GetDB(),
 		libraryService: NewLibraryService(),
+		mediaService:   NewMediaService(),
+		trashService:   NewTrashService(),
 	}
 }
 
@@ -53,17 +69,11 @@ func (s *FileService) MoveFile(libraryID int64, sourcePath, destPath string) err
 		return fmt.Errorf("destination file already exists")
 	}
 
-	// Create destination directory if it doesn't exist
-	destDir := filepath.Dir(destFullPath)
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
-	}
-
-	// Move file
-	if err := os.Rename(sourceFullPath, destFullPath); err != nil {
-		return fmt.Errorf("failed to move file: %w", err)
+	if err := s.relocate(library, library, sourceFullPath, destFullPath); err != nil {
+		return err
 	}
-
+	s.journalMove(library, library, sourceFullPath, destFullPath,
+		fmt.Sprintf("Moved %s to %s", sourcePath, destPath))
 	return nil
 }
 
@@ -89,6 +99,11 @@ func (s *FileService) RenameFile(libraryID int64, filePath, newName string) erro
 		return fmt.Errorf("file does not exist")
 	}
 
+	// The new name can't point into another folder
+	if newName != filepath.Base(newName) || newName == "." || newName == ".." {
+		return fmt.Errorf("new name must not contain path separators")
+	}
+
 	// Construct new path
 	dir := filepath.Dir(fullPath)
 	newPath := filepath.Join(dir, newName)
@@ -98,15 +113,15 @@ func (s *FileService) RenameFile(libraryID int64, filePath, newName string) erro
 		return fmt.Errorf("file with new name already exists")
 	}
 
-	// Rename file
-	if err := os.Rename(fullPath, newPath); err != nil {
-		return fmt.Errorf("failed to rename file: %w", err)
+	if err := s.relocate(library, library, fullPath, newPath); err != nil {
+		return err
 	}
-
+	s.journalMove(library, library, fullPath, newPath,
+		fmt.Sprintf("Renamed %s to %s", filepath.Base(fullPath), newName))
 	return nil
 }
 
-// DeleteFile deletes a file from a library
+// DeleteFile moves a file from a library into its trash
 func (s *FileService) DeleteFile(libraryID int64, filePath string) error {
 	// Get library
 	library, err := s.libraryService.GetByID(libraryID)
@@ -134,8 +149,8 @@ func (s *FileService) DeleteFile(libraryID int64, filePath string) error {
 		return fmt.Errorf("cannot delete directories")
 	}
 
-	// Delete file
-	if err := os.Remove(fullPath); err != nil {
+	// Deleted files go to the library's trash, with their video, until purged
+	if _, err := s.trashService.TrashFile(library, fullPath); err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
 
@@ -173,8 +188,7 @@ func (s *FileService) MoveFileAcrossLibraries(sourceLibraryID int64, sourcePath
 	}
 
 	// Check if source exists
-	sourceInfo, err := os.Stat(sourceFullPath)
-	if os.IsNotExist(err) {
+	if _, err := os.Stat(sourceFullPath); os.IsNotExist(err) {
 		return fmt.Errorf("source file or folder does not exist")
 	}
 
@@ -188,17 +202,89 @@ func (s *FileService) MoveFileAcrossLibraries(sourceLibraryID int64, sourcePath
 		return fmt.Errorf("destination file or folder already exists")
 	}
 
+	if err := s.relocate(sourceLibrary, targetLibrary, sourceFullPath, targetFullPath); err != nil {
+		return err
+	}
+	s.journalMove(sourceLibrary, targetLibrary, sourceFullPath, targetFullPath,
+		fmt.Sprintf("Moved %s from %s to %s", sourcePath, sourceLibrary.Name, targetLibrary.Name))
+	return nil
+}
+
+// Moves relocate refuses, which retrying won't change
+var (
+	errMoveSourceMissing     = errors.New("source file or folder does not exist")
+	errMoveDestinationExists = errors.New("destination file or folder already exists")
+)
+
+// relocate moves a file or folder between full paths, in one library or from
+// one library to another, and carries the videos under it along
+func (s *FileService) relocate(source, target *models.Library, from, to string) error {
+	info, err := os.Stat(from)
+	if err != nil {
+		return errMoveSourceMissing
+	}
+	move := func(from, to string) error { return movePath(from, to, info.IsDir()) }
+	if existing, err := os.Stat(to); err == nil {
+		// A case-only rename on a case-insensitive filesystem finds the source itself
+		if !os.SameFile(info, existing) {
+			return errMoveDestinationExists
+		}
+		move = renameCase
+	}
+
 	// Create destination directory if it doesn't exist
-	targetDir := filepath.Dir(targetFullPath)
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return fmt.Errorf("failed to create target directory: %w", err)
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
 	// Move file or folder
+	if err := move(from, to); err != nil {
+		return err
+	}
+
+	return s.syncMovedVideos(source, target, from, to, func() error {
+		return move(to, from)
+	})
+}
+
+// renameCase renames a file or folder to a name differing only in case. It goes
+// through a temporary name, since some filesystems ignore a direct rename that
+// only changes case.
+func renameCase(from, to string) error {
+	temp := fmt.Sprintf("%s.%d.tmp", from, time.Now().UnixNano())
+	if err := os.Rename(from, temp); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", from, err)
+	}
+	if err := os.Rename(temp, to); err != nil {
+		if restoreErr := os.Rename(temp, from); restoreErr != nil {
+			log.Printf("Failed to restore %s from %s: %v", from, temp, restoreErr)
+		}
+		return fmt.Errorf("failed to rename %s: %w", from, err)
+	}
+	return nil
+}
+
+// journalMove records a finished move so it can be undone
+func (s *FileService) journalMove(source, target *models.Library, from, to, summary string) {
+	change := models.OperationChange{
+		Kind:            models.ChangeFileMoved,
+		LibraryID:       source.ID,
+		TargetLibraryID: target.ID,
+		From:            from,
+		To:              to,
+	}
+	if _, err := journalOperation(s.db, models.OperationFileMove, summary, []models.OperationChange{change}); err != nil {
+		log.Printf("Failed to journal move of %s: %v", from, err)
+	}
+}
+
+// movePath moves a file or folder, copying and deleting it when a rename isn't
+// possible because source and destination are on different filesystems
+func movePath(sourceFullPath, targetFullPath string, isDir bool) error {
 	// Try os.Rename first (works if on same filesystem)
 	if err := os.Rename(sourceFullPath, targetFullPath); err != nil {
 		// If rename fails (likely different filesystem), copy and delete
-		if sourceInfo.IsDir() {
+		if isDir {
 			// For directories, we need to copy recursively
 			if err := copyDir(sourceFullPath, targetFullPath); err != nil {
 				return fmt.Errorf("failed to copy directory: %w", err)
@@ -323,3 +409,105 @@ func (s *FileService) CopyFile(libraryID int64, sourcePath, destPath string) err
 
 	return nil
 }
+
+// movedVideo is a video row under a moved path, with its assets before and after
+type movedVideo struct {
+	id                         int64
+	oldPath, newPath           string
+	thumbnailPath, previewPath string
+	relocated                  RelocatedAssets
+}
+
+// videosUnderPath finds the videos at path, or inside it when path is a folder
+func (s *FileService) videosUnderPath(path string) ([]movedVideo, error) {
+	prefix := path + string(filepath.Separator)
+	rows, err := s.db.Query(`
+		SELECT id, file_path, COALESCE(thumbnail_path, ''), COALESCE(preview_path, '')
+		FROM videos
+		WHERE file_path = ? OR substr(file_path, 1, ?) = ?
+	`, path, utf8.RuneCountInString(prefix), prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query videos: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var videos []movedVideo
+	for rows.Next() {
+		var video movedVideo
+		if err := rows.Scan(&video.id, &video.oldPath, &video.thumbnailPath, &video.previewPath); err != nil {
+			return nil, fmt.Errorf("failed to scan video: %w", err)
+		}
+		videos = append(videos, video)
+	}
+	return videos, rows.Err()
+}
+
+// syncMovedVideos points the videos under a moved file or folder at their new
+// paths and library, carrying their thumbnails and previews along. When the
+// database can't be updated, undo puts the file back and the assets follow it.
+func (s *FileService) syncMovedVideos(source, target *models.Library, oldPath, newPath string, undo func() error) error {
+	videos, err := s.videosUnderPath(oldPath)
+	if err == nil {
+		for i := range videos {
+			video := &videos[i]
+			video.newPath = newPath + strings.TrimPrefix(video.oldPath, oldPath)
+			video.relocated = s.mediaService.RelocateVideoAssets(
+				AssetLocation{LibraryID: target.ID, LibraryPath: target.Path, FilePath: video.newPath},
+				video.thumbnailPath,
+				video.previewPath,
+			)
+		}
+		err = s.updateMovedVideos(target.ID, videos)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if undoErr := undo(); undoErr != nil {
+		log.Printf("Failed to move %s back after database error: %v", newPath, undoErr)
+	}
+	for _, video := range videos {
+		s.mediaService.RelocateVideoAssets(
+			AssetLocation{LibraryID: source.ID, LibraryPath: source.Path, FilePath: video.oldPath},
+			video.relocated.ThumbnailPath,
+			video.relocated.PreviewPath,
+		)
+	}
+	return err
+}
+
+// updateMovedVideos saves the new locations of moved videos in one transaction
+func (s *FileService) updateMovedVideos(libraryID int64, videos []movedVideo) error {
+	if len(videos) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back video path update: %v", err)
+		}
+	}()
+
+	now := time.Now()
+	for _, video := range videos {
+		_, err := tx.Exec(`
+			UPDATE videos SET file_path = ?, library_id = ?, thumbnail_path = ?, preview_path = ?, updated_at = ?
+			WHERE id = ?
+		`, video.newPath, libraryID, video.relocated.ThumbnailPath, video.relocated.PreviewPath, now, video.id)
+		if err != nil {
+			return fmt.Errorf("failed to update path of video %d: %w", video.id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit video path update: %w", err)
+	}
+	return nil
+}
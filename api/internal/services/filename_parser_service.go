@@ -0,0 +1,695 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// FilenameParserService turns filenames into titles, dates, studios, groups and
+// performers using user-defined templates or regexes
+type FilenameParserService struct {
+	db               *sql.DB
+	studioService    *StudioService
+	groupService     *GroupService
+	performerService *PerformerService
+	activityService  *ActivityService
+}
+
+// NewFilenameParserService creates a new filename parser service
+func NewFilenameParserService() *FilenameParserService {
+	return &FilenameParserService{
+		db:               database.GetDB(),
+		studioService:    NewStudioService(),
+		groupService:     NewGroupService(),
+		performerService: NewPerformerService(),
+		activityService:  NewActivityService(),
+	}
+}
+
+// parseRuleFields are the capture names templates and regexes may use
+var parseRuleFields = map[string]string{
+	"studio":     `.+?`,
+	"group":      `.+?`,
+	"performers": `.+?`,
+	"title":      `.+?`,
+	"date":       `\d{4}[-._ /]\d{1,2}[-._ /]\d{1,2}|\d{2}[-._ /]\d{2}[-._ /]\d{2}|\d{8}`,
+	"year":       `\d{4}`,
+	"month":      `\d{1,2}`,
+	"day":        `\d{1,2}`,
+}
+
+var (
+	templatePlaceholder = regexp.MustCompile(`\{(\w+|\*)\}`)
+	whitespaceRun       = regexp.MustCompile(`\s+`)
+	performerSeparator  = regexp.MustCompile(`(?i)\s*(?:,|&|\+|\band\b)\s*`)
+)
+
+// templateToRegex converts a template like "[{studio}] {performers} - {title} ({date})"
+// into an anchored regex with named groups. {ignore} or {*} skips any text, and
+// whitespace in the template matches any amount of whitespace.
+func templateToRegex(template string) (string, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	seen := make(map[string]bool)
+	last := 0
+	for _, loc := range templatePlaceholder.FindAllStringSubmatchIndex(template, -1) {
+		b.WriteString(literalToRegex(template[last:loc[0]]))
+		last = loc[1]
+
+		name := strings.ToLower(template[loc[2]:loc[3]])
+		if name == "ignore" || name == "*" {
+			b.WriteString(`.*?`)
+			continue
+		}
+		fieldPattern, ok := parseRuleFields[name]
+		if !ok {
+			return "", fmt.Errorf("unknown placeholder {%s}", name)
+		}
+		if seen[name] {
+			return "", fmt.Errorf("placeholder {%s} used more than once", name)
+		}
+		seen[name] = true
+		fmt.Fprintf(&b, "(?P<%s>%s)", name, fieldPattern)
+	}
+	b.WriteString(literalToRegex(template[last:]))
+	b.WriteString("$")
+
+	if len(seen) == 0 {
+		return "", fmt.Errorf("template has no placeholders")
+	}
+	return b.String(), nil
+}
+
+// literalToRegex escapes template text between placeholders
+func literalToRegex(literal string) string {
+	parts := whitespaceRun.Split(literal, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, `\s*`)
+}
+
+// compileParseRule compiles a rule's pattern, case-insensitively
+func compileParseRule(patternType, pattern string) (*regexp.Regexp, error) {
+	expr := pattern
+	switch patternType {
+	case models.ParseRuleTypeTemplate, "":
+		var err error
+		if expr, err = templateToRegex(pattern); err != nil {
+			return nil, err
+		}
+	case models.ParseRuleTypeRegex:
+	default:
+		return nil, fmt.Errorf("unknown pattern type: %s", patternType)
+	}
+
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	known := false
+	for _, name := range re.SubexpNames() {
+		if _, ok := parseRuleFields[name]; ok {
+			known = true
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("pattern has no studio, group, performers, title or date captures")
+	}
+	return re, nil
+}
+
+const parseRuleColumns = `id, library_id, name, pattern_type, pattern, COALESCE(priority, 0),
+	COALESCE(enabled, 1), COALESCE(create_missing, 0), created_at, updated_at`
+
+// scanParseRule scans a row selected with parseRuleColumns
+func scanParseRule(row interface{ Scan(...interface{}) error }) (*models.FilenameParseRule, error) {
+	var rule models.FilenameParseRule
+	var libraryID sql.NullInt64
+	err := row.Scan(&rule.ID, &libraryID, &rule.Name, &rule.PatternType, &rule.Pattern, &rule.Priority,
+		&rule.Enabled, &rule.CreateMissing, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if libraryID.Valid {
+		rule.LibraryID = &libraryID.Int64
+	}
+	return &rule, nil
+}
+
+// GetRules retrieves the rules that apply to a library, including global rules,
+// in evaluation order. libraryID 0 returns every rule.
+func (s *FilenameParserService) GetRules(libraryID int64) ([]models.FilenameParseRule, error) {
+	query := `SELECT ` + parseRuleColumns + ` FROM filename_parse_rules`
+	var args []interface{}
+	if libraryID > 0 {
+		query += ` WHERE library_id IS NULL OR library_id = ?`
+		args = append(args, libraryID)
+	}
+	// Library-specific rules win ties over global ones
+	query += ` ORDER BY priority ASC, library_id IS NULL ASC, id ASC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query parse rules: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	rules := []models.FilenameParseRule{}
+	for rows.Next() {
+		rule, err := scanParseRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan parse rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetRule retrieves a parse rule by ID
+func (s *FilenameParserService) GetRule(id int64) (*models.FilenameParseRule, error) {
+	rule, err := scanParseRule(s.db.QueryRow(`SELECT `+parseRuleColumns+` FROM filename_parse_rules WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("parse rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query parse rule: %w", err)
+	}
+	return rule, nil
+}
+
+// CreateRule validates and stores a new parse rule
+func (s *FilenameParserService) CreateRule(create *models.FilenameParseRuleCreate) (*models.FilenameParseRule, error) {
+	patternType := create.PatternType
+	if patternType == "" {
+		patternType = models.ParseRuleTypeTemplate
+	}
+	if _, err := compileParseRule(patternType, create.Pattern); err != nil {
+		return nil, err
+	}
+
+	enabled := true
+	if create.Enabled != nil {
+		enabled = *create.Enabled
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec(`
+		INSERT INTO filename_parse_rules (library_id, name, pattern_type, pattern, priority, enabled, create_missing, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, create.LibraryID, create.Name, patternType, create.Pattern, create.Priority, enabled, create.CreateMissing, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parse rule: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	return s.GetRule(id)
+}
+
+// UpdateRule updates an existing parse rule
+func (s *FilenameParserService) UpdateRule(id int64, update *models.FilenameParseRuleUpdate) (*models.FilenameParseRule, error) {
+	rule, err := s.GetRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		rule.Name = *update.Name
+	}
+	if update.PatternType != nil {
+		rule.PatternType = *update.PatternType
+	}
+	if update.Pattern != nil {
+		rule.Pattern = *update.Pattern
+	}
+	if update.Priority != nil {
+		rule.Priority = *update.Priority
+	}
+	if update.Enabled != nil {
+		rule.Enabled = *update.Enabled
+	}
+	if update.CreateMissing != nil {
+		rule.CreateMissing = *update.CreateMissing
+	}
+	if _, err := compileParseRule(rule.PatternType, rule.Pattern); err != nil {
+		return nil, err
+	}
+
+	rule.UpdatedAt = time.Now()
+	_, err = s.db.Exec(`
+		UPDATE filename_parse_rules
+		SET name = ?, pattern_type = ?, pattern = ?, priority = ?, enabled = ?, create_missing = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.Name, rule.PatternType, rule.Pattern, rule.Priority, rule.Enabled, rule.CreateMissing, rule.UpdatedAt, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update parse rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule deletes a parse rule
+func (s *FilenameParserService) DeleteRule(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM filename_parse_rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete parse rule: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("parse rule not found")
+	}
+	return nil
+}
+
+// compiledParseRule pairs a rule with its compiled pattern
+type compiledParseRule struct {
+	rule models.FilenameParseRule
+	re   *regexp.Regexp
+}
+
+// ParseRuleSet is the ordered set of enabled rules for one library
+type ParseRuleSet struct {
+	service *FilenameParserService
+	rules   []compiledParseRule
+}
+
+// LoadRuleSet compiles the enabled rules that apply to a library
+func (s *FilenameParserService) LoadRuleSet(libraryID int64) (*ParseRuleSet, error) {
+	rules, err := s.GetRules(libraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &ParseRuleSet{service: s}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		re, err := compileParseRule(rule.PatternType, rule.Pattern)
+		if err != nil {
+			log.Printf("Skipping invalid parse rule %d (%s): %v", rule.ID, rule.Name, err)
+			continue
+		}
+		set.rules = append(set.rules, compiledParseRule{rule: rule, re: re})
+	}
+	return set, nil
+}
+
+// Empty reports whether the set has no rules to evaluate
+func (rs *ParseRuleSet) Empty() bool {
+	return len(rs.rules) == 0
+}
+
+// Parse evaluates the rules against a file's name and resolves the studio, group
+// and performers it names against existing records
+func (rs *ParseRuleSet) Parse(filePath string) (*models.FilenameParseResult, *models.FilenameParseRule) {
+	result := &models.FilenameParseResult{FilePath: filePath}
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+
+	for _, compiled := range rs.rules {
+		match := compiled.re.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		captures := make(map[string]string)
+		for i, field := range compiled.re.SubexpNames() {
+			if field != "" && match[i] != "" {
+				captures[strings.ToLower(field)] = strings.TrimSpace(match[i])
+			}
+		}
+
+		result.Matched = true
+		result.RuleID = compiled.rule.ID
+		result.RuleName = compiled.rule.Name
+		result.Title = cleanParsedTitle(captures["title"])
+		result.Date = normalizeParsedDate(captures["date"], captures["year"], captures["month"], captures["day"])
+		if studio := captures["studio"]; studio != "" {
+			result.Studio = &models.ParsedEntity{Name: studio}
+		}
+		if group := captures["group"]; group != "" {
+			result.Group = &models.ParsedEntity{Name: group}
+		}
+		for _, performer := range performerSeparator.Split(captures["performers"], -1) {
+			if performer = strings.TrimSpace(performer); performer != "" {
+				result.Performers = append(result.Performers, models.ParsedEntity{Name: performer})
+			}
+		}
+
+		rs.service.resolveEntities(result)
+		rule := compiled.rule
+		return result, &rule
+	}
+
+	return result, nil
+}
+
+// cleanParsedTitle turns dot- or underscore-separated titles into words
+func cleanParsedTitle(title string) string {
+	if !strings.Contains(title, " ") {
+		title = strings.NewReplacer(".", " ", "_", " ").Replace(title)
+	}
+	return strings.Trim(whitespaceRun.ReplaceAllString(title, " "), " -")
+}
+
+// normalizeParsedDate turns a captured date, or year/month/day captures, into
+// YYYY-MM-DD. Unparseable dates are dropped rather than stored half-formed.
+func normalizeParsedDate(date, year, month, day string) string {
+	if date == "" {
+		if year == "" {
+			return ""
+		}
+		if month == "" {
+			month = "1"
+		}
+		if day == "" {
+			day = "1"
+		}
+		date = year + "-" + month + "-" + day
+	}
+
+	normalized := strings.NewReplacer(".", "-", "_", "-", " ", "-", "/", "-").Replace(date)
+	for _, layout := range []string{"2006-1-2", "20060102", "06-01-02", "02-01-2006"} {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	// Fall back to the year alone
+	if y, err := strconv.Atoi(year); err == nil && y > 1900 {
+		return fmt.Sprintf("%04d-01-01", y)
+	}
+	return ""
+}
+
+// resolveEntities looks up existing records for the parsed names, case-insensitively
+func (s *FilenameParserService) resolveEntities(result *models.FilenameParseResult) {
+	lookup := func(query string, args ...interface{}) *int64 {
+		var id int64
+		if err := s.db.QueryRow(query, args...).Scan(&id); err != nil {
+			return nil
+		}
+		return &id
+	}
+
+	if result.Studio != nil {
+		result.Studio.ID = lookup(`SELECT id FROM studios WHERE name = ? COLLATE NOCASE LIMIT 1`, result.Studio.Name)
+	}
+	if result.Group != nil {
+		if result.Studio != nil && result.Studio.ID != nil {
+			result.Group.ID = lookup(`SELECT id FROM groups WHERE name = ? COLLATE NOCASE AND studio_id = ? LIMIT 1`,
+				result.Group.Name, *result.Studio.ID)
+		} else {
+			result.Group.ID = lookup(`SELECT id FROM groups WHERE name = ? COLLATE NOCASE LIMIT 1`, result.Group.Name)
+		}
+	}
+	for i := range result.Performers {
+		// Performers are also known by their aliases
+		if id, err := findPerformerIDByName(s.db, result.Performers[i].Name); err == nil {
+			result.Performers[i].ID = &id
+		}
+	}
+}
+
+// ApplyResult writes a parse result to a video: title and date are replaced when
+// parsed, the studio and group replace existing links, and performers are added.
+// Unknown studios, groups and performers are created when createMissing is set.
+// The video's fields and links change in one transaction.
+func (s *FilenameParserService) ApplyResult(videoID int64, result *models.FilenameParseResult, createMissing bool) error {
+	if !result.Matched {
+		return nil
+	}
+
+	// Records are created up front; they're shared, not part of the video
+	if result.Studio != nil && result.Studio.ID == nil && createMissing {
+		studio, err := s.studioService.Create(&models.StudioCreate{Name: result.Studio.Name})
+		if err != nil {
+			return fmt.Errorf("failed to create studio: %w", err)
+		}
+		result.Studio.ID = &studio.ID
+	}
+	// Groups belong to a studio, so one can only be created once the studio is known
+	if result.Group != nil && result.Group.ID == nil && createMissing && result.Studio != nil && result.Studio.ID != nil {
+		group, err := s.groupService.Create(&models.GroupCreate{StudioID: *result.Studio.ID, Name: result.Group.Name})
+		if err != nil {
+			return fmt.Errorf("failed to create group: %w", err)
+		}
+		result.Group.ID = &group.ID
+	}
+	for i := range result.Performers {
+		performer := &result.Performers[i]
+		if performer.ID == nil && createMissing {
+			created, err := s.performerService.Create(&models.PerformerCreate{Name: performer.Name})
+			if err != nil {
+				return fmt.Errorf("failed to create performer: %w", err)
+			}
+			performer.ID = &created.ID
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back parsed metadata for video %d: %v", videoID, err)
+		}
+	}()
+
+	_, err = tx.Exec(`
+		UPDATE videos
+		SET title = COALESCE(NULLIF(?, ''), title), date = COALESCE(NULLIF(?, ''), date), updated_at = ?
+		WHERE id = ?
+	`, result.Title, result.Date, time.Now(), videoID)
+	if err != nil {
+		return fmt.Errorf("failed to update video: %w", err)
+	}
+
+	if result.Studio != nil && result.Studio.ID != nil {
+		if _, err := tx.Exec(`DELETE FROM video_studios WHERE video_id = ?`, videoID); err != nil {
+			return fmt.Errorf("failed to clear studios: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO video_studios (video_id, studio_id) VALUES (?, ?)`, videoID, *result.Studio.ID); err != nil {
+			return fmt.Errorf("failed to add studio: %w", err)
+		}
+	}
+
+	if result.Group != nil && result.Group.ID != nil {
+		if _, err := tx.Exec(`DELETE FROM video_groups WHERE video_id = ?`, videoID); err != nil {
+			return fmt.Errorf("failed to clear groups: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO video_groups (video_id, group_id) VALUES (?, ?)`, videoID, *result.Group.ID); err != nil {
+			return fmt.Errorf("failed to add group: %w", err)
+		}
+	}
+
+	for _, performer := range result.Performers {
+		if performer.ID == nil {
+			continue
+		}
+		if err := linkVideoPerformer(tx, videoID, *performer.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit parsed metadata: %w", err)
+	}
+	return nil
+}
+
+// linkVideoPerformer adds a performer to a video unless already linked, keeping the
+// performer's video count and master tags in step like VideoService.Update does.
+// q may be a transaction.
+func linkVideoPerformer(q tagQuerier, videoID, performerID int64) error {
+	res, err := q.Exec(`INSERT OR IGNORE INTO video_performers (video_id, performer_id) VALUES (?, ?)`, videoID, performerID)
+	if err != nil {
+		return fmt.Errorf("failed to add performer: %w", err)
+	}
+	if added, _ := res.RowsAffected(); added == 0 {
+		return nil
+	}
+
+	if _, err := q.Exec(`UPDATE performers SET video_count = video_count + 1 WHERE id = ?`, performerID); err != nil {
+		return fmt.Errorf("failed to increment video count: %w", err)
+	}
+	tagIDs, err := queryIDs(q, `SELECT tag_id FROM performer_tags WHERE performer_id = ?`, performerID)
+	if err != nil {
+		return fmt.Errorf("failed to query master tags: %w", err)
+	}
+	links := make([]linkPair, len(tagIDs))
+	for i, tagID := range tagIDs {
+		links[i] = linkPair{videoID, tagID}
+	}
+	if _, err := insertVideoTags(q, links); err != nil {
+		return fmt.Errorf("failed to apply master tags: %w", err)
+	}
+	return nil
+}
+
+// ApplyToVideo parses a video's filename with the set and applies the result using
+// the matching rule's create-missing setting. It's a no-op when no rule matches.
+func (rs *ParseRuleSet) ApplyToVideo(videoID int64, filePath string) error {
+	if rs.Empty() {
+		return nil
+	}
+	result, rule := rs.Parse(filePath)
+	if rule == nil {
+		return nil
+	}
+	return rs.service.ApplyResult(videoID, result, rule.CreateMissing)
+}
+
+// parseTarget is a video (or bare file) to run the rules against
+type parseTarget struct {
+	videoID  int64
+	filePath string
+}
+
+// libraryTargets lists a library's available videos, or just the given IDs
+func (s *FilenameParserService) libraryTargets(libraryID int64, videoIDs []int64, limit int) ([]parseTarget, error) {
+	query := `SELECT id, file_path FROM videos WHERE library_id = ? AND COALESCE(status, 'available') = ?`
+	args := []interface{}{libraryID, models.VideoStatusAvailable}
+	if len(videoIDs) > 0 {
+		query += ` AND id IN (?` + strings.Repeat(", ?", len(videoIDs)-1) + `)`
+		for _, id := range videoIDs {
+			args = append(args, id)
+		}
+	}
+	query += ` ORDER BY file_path`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query videos: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var targets []parseTarget
+	for rows.Next() {
+		var target parseTarget
+		if err := rows.Scan(&target.videoID, &target.filePath); err != nil {
+			return nil, fmt.Errorf("failed to scan video: %w", err)
+		}
+		targets = append(targets, target)
+	}
+	return targets, rows.Err()
+}
+
+// DryRun shows what each file would parse to without changing anything
+func (s *FilenameParserService) DryRun(req *models.FilenameParseDryRunRequest) ([]models.FilenameParseResult, error) {
+	var set *ParseRuleSet
+	if req.Rule != nil {
+		patternType := req.Rule.PatternType
+		if patternType == "" {
+			patternType = models.ParseRuleTypeTemplate
+		}
+		re, err := compileParseRule(patternType, req.Rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		set = &ParseRuleSet{service: s, rules: []compiledParseRule{{
+			rule: models.FilenameParseRule{Name: req.Rule.Name, PatternType: patternType, Pattern: req.Rule.Pattern},
+			re:   re,
+		}}}
+	} else {
+		var err error
+		if set, err = s.LoadRuleSet(req.LibraryID); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var targets []parseTarget
+	if len(req.FilePaths) > 0 {
+		for _, filePath := range req.FilePaths {
+			targets = append(targets, parseTarget{filePath: filePath})
+		}
+	} else {
+		var err error
+		if targets, err = s.libraryTargets(req.LibraryID, nil, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	results := []models.FilenameParseResult{}
+	for _, target := range targets {
+		result, _ := set.Parse(target.filePath)
+		result.VideoID = target.videoID
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// ApplyToLibrary parses and applies the rules to a library's videos, tracked as an activity
+func (s *FilenameParserService) ApplyToLibrary(req *models.FilenameParseApplyRequest) error {
+	set, err := s.LoadRuleSet(req.LibraryID)
+	if err != nil {
+		return err
+	}
+	targets, err := s.libraryTargets(req.LibraryID, req.VideoIDs, 0)
+	if err != nil {
+		return err
+	}
+
+	activity, err := s.activityService.StartTask("filename_parsing",
+		fmt.Sprintf("Parsing filenames of %d videos", len(targets)),
+		map[string]interface{}{"library_id": req.LibraryID, "total_videos": len(targets)})
+	if err != nil {
+		return fmt.Errorf("failed to create activity: %w", err)
+	}
+
+	applied, unmatched, failed := 0, 0, 0
+	for i, target := range targets {
+		result, rule := set.Parse(target.filePath)
+		if rule == nil {
+			unmatched++
+		} else {
+			createMissing := rule.CreateMissing
+			if req.CreateMissing != nil {
+				createMissing = *req.CreateMissing
+			}
+			if err := s.ApplyResult(target.videoID, result, createMissing); err != nil {
+				log.Printf("Failed to apply parsed metadata to video %d: %v", target.videoID, err)
+				failed++
+			} else {
+				applied++
+			}
+		}
+
+		msg := fmt.Sprintf("Parsed %d/%d (Applied: %d, Unmatched: %d)", i+1, len(targets), applied, unmatched)
+		if err := s.activityService.UpdateItemProgress(activity.ID, i+1, len(targets), msg); err != nil {
+			log.Printf("Failed to update progress: %v", err)
+		}
+	}
+
+	return s.activityService.CompleteTask(int64(activity.ID),
+		fmt.Sprintf("Filename parsing complete: %d applied, %d unmatched, %d failed", applied, unmatched, failed))
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// Search matches are wrapped in <mark> in highlights and snippets. FTS wraps
+// them in control characters first, so the text can be HTML-escaped before the
+// tags go in.
+const (
+	searchMarkOpen  = "\x02"
+	searchMarkClose = "\x03"
+	searchEllipsis  = "…"
+)
+
+// searchMarkReplacer turns FTS match markers into HTML
+var searchMarkReplacer = strings.NewReplacer(searchMarkOpen, "<mark>", searchMarkClose, "</mark>")
+
+// markSearchMatches escapes highlighted FTS text for HTML and marks its matches
+func markSearchMatches(text string) string {
+	return searchMarkReplacer.Replace(html.EscapeString(text))
+}
+
+// searchSnippetTokens is roughly how many words a snippet spans
+const searchSnippetTokens = 12
+
+// videoSearchRank weights title matches highest, then performers, then studios and
+// tags, with description and path last. Weights follow the videos_fts column order.
+const videoSearchRank = "bm25(videos_fts, 10.0, 2.0, 1.0, 5.0, 3.0, 3.0)"
+
+// buildFTSQuery turns free text into an FTS5 MATCH expression. Quoted phrases
+// match exactly; every other word matches as a prefix, so "ali ros" finds
+// "Alice Rose". Terms are ANDed. Returns "" when the input has no searchable words.
+func buildFTSQuery(input string) string {
+	var terms []string
+	tokens := func(text string) []string {
+		return strings.FieldsFunc(text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+	}
+
+	for i, part := range strings.Split(input, `"`) {
+		words := tokens(part)
+		if len(words) == 0 {
+			continue
+		}
+		// Odd parts sit between quotes
+		if i%2 == 1 {
+			terms = append(terms, `"`+strings.Join(words, " ")+`"`)
+			continue
+		}
+		for _, word := range words {
+			terms = append(terms, `"`+word+`"*`)
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+// attachSearchHighlights fills in the title highlight and best snippet for a page of search results
+func (s *VideoService) attachSearchHighlights(videos []models.Video, match string) {
+	if len(videos) == 0 {
+		return
+	}
+
+	placeholders := make([]string, len(videos))
+	args := []interface{}{searchMarkOpen, searchMarkClose, searchMarkOpen, searchMarkClose, searchEllipsis, searchSnippetTokens, match}
+	index := make(map[int64]int, len(videos))
+	for i, video := range videos {
+		placeholders[i] = "?"
+		args = append(args, video.ID)
+		index[video.ID] = i
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT rowid, highlight(videos_fts, 0, ?, ?), snippet(videos_fts, -1, ?, ?, ?, ?)
+		FROM videos_fts
+		WHERE videos_fts MATCH ? AND rowid IN (%s)
+	`, strings.Join(placeholders, ",")), args...)
+	if err != nil {
+		log.Printf("Warning: Failed to load search highlights: %v", err)
+		return
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var id int64
+		var highlight, snippet string
+		if err := rows.Scan(&id, &highlight, &snippet); err != nil {
+			log.Printf("Warning: Failed to scan search highlight: %v", err)
+			return
+		}
+		if i, ok := index[id]; ok {
+			videos[i].Highlight = markSearchMatches(highlight)
+			videos[i].Snippet = markSearchMatches(snippet)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// snapshotDateLayout is how snapshot dates are stored and accepted in queries
+const snapshotDateLayout = "2006-01-02"
+
+// distribution counts available videos in a library grouped by column
+func (s *LibraryService) distribution(libraryID int64, column string) (map[string]int, error) {
+	rows, err := s.db.Query(`
+		SELECT COALESCE(NULLIF(`+column+`, ''), 'unknown'), COUNT(*)
+		FROM videos
+		WHERE library_id = ? AND COALESCE(status, 'available') = ?
+		GROUP BY 1
+	`, libraryID, models.VideoStatusAvailable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s distribution: %w", column, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var count int
+		if err := rows.Scan(&key, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan %s distribution: %w", column, err)
+		}
+		counts[key] = count
+	}
+	return counts, rows.Err()
+}
+
+// TakeSnapshot records today's statistics for a library, replacing any snapshot
+// already taken today so the row reflects the end of the day
+func (s *LibraryService) TakeSnapshot(libraryID int64) (*models.LibraryStatsSnapshot, error) {
+	snapshot := &models.LibraryStatsSnapshot{
+		LibraryID: libraryID,
+		Date:      time.Now().Format(snapshotDateLayout),
+	}
+
+	err := s.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(file_size), 0), COALESCE(SUM(duration), 0)
+		FROM videos
+		WHERE library_id = ? AND COALESCE(status, 'available') = ?
+	`, libraryID, models.VideoStatusAvailable).Scan(&snapshot.VideoCount, &snapshot.TotalBytes, &snapshot.TotalDuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query library totals: %w", err)
+	}
+
+	if snapshot.Codecs, err = s.distribution(libraryID, "codec"); err != nil {
+		return nil, err
+	}
+	if snapshot.Resolutions, err = s.distribution(libraryID, "resolution"); err != nil {
+		return nil, err
+	}
+
+	codecs, _ := json.Marshal(snapshot.Codecs)
+	resolutions, _ := json.Marshal(snapshot.Resolutions)
+	_, err = s.db.Exec(`
+		INSERT INTO library_stats_history
+			(library_id, snapshot_date, video_count, total_bytes, total_duration, codec_distribution, resolution_distribution, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(library_id, snapshot_date) DO UPDATE SET
+			video_count = excluded.video_count,
+			total_bytes = excluded.total_bytes,
+			total_duration = excluded.total_duration,
+			codec_distribution = excluded.codec_distribution,
+			resolution_distribution = excluded.resolution_distribution,
+			created_at = excluded.created_at
+	`, libraryID, snapshot.Date, snapshot.VideoCount, snapshot.TotalBytes, snapshot.TotalDuration,
+		string(codecs), string(resolutions), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to save library snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// SnapshotAll records today's statistics for every library
+func (s *LibraryService) SnapshotAll() error {
+	libraries, err := s.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, lib := range libraries {
+		if _, err := s.TakeSnapshot(lib.ID); err != nil {
+			log.Printf("Failed to snapshot library %s: %v", lib.Name, err)
+		}
+	}
+	return nil
+}
+
+// bucketStart returns the first day of the bucket a date falls in. Weeks start on Monday.
+func bucketStart(date time.Time, bucket string) time.Time {
+	switch bucket {
+	case models.StatsBucketWeek:
+		offset := (int(date.Weekday()) + 6) % 7
+		return date.AddDate(0, 0, -offset)
+	case models.StatsBucketMonth:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	default:
+		return date
+	}
+}
+
+// GetStatsHistory returns a library's snapshots between from and to, inclusive.
+// Snapshots are levels rather than increments, so each bucket reports the last
+// snapshot taken within it.
+func (s *LibraryService) GetStatsHistory(libraryID int64, from, to time.Time, bucket string) (*models.LibraryStatsHistory, error) {
+	switch bucket {
+	case "":
+		bucket = models.StatsBucketDay
+	case models.StatsBucketDay, models.StatsBucketWeek, models.StatsBucketMonth:
+	default:
+		return nil, fmt.Errorf("invalid bucket: %s (use day, week or month)", bucket)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+
+	history := &models.LibraryStatsHistory{
+		LibraryID: libraryID,
+		From:      from.Format(snapshotDateLayout),
+		To:        to.Format(snapshotDateLayout),
+		Bucket:    bucket,
+		Points:    []models.LibraryStatsSnapshot{},
+	}
+
+	rows, err := s.db.Query(`
+		SELECT snapshot_date, video_count, total_bytes, total_duration,
+		       COALESCE(codec_distribution, '{}'), COALESCE(resolution_distribution, '{}')
+		FROM library_stats_history
+		WHERE library_id = ? AND snapshot_date >= ? AND snapshot_date <= ?
+		ORDER BY snapshot_date ASC
+	`, libraryID, history.From, history.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var date time.Time
+		var codecs, resolutions string
+		point := models.LibraryStatsSnapshot{LibraryID: libraryID}
+		if err := rows.Scan(&date, &point.VideoCount, &point.TotalBytes, &point.TotalDuration, &codecs, &resolutions); err != nil {
+			return nil, fmt.Errorf("failed to scan stats snapshot: %w", err)
+		}
+		if err := json.Unmarshal([]byte(codecs), &point.Codecs); err != nil {
+			point.Codecs = map[string]int{}
+		}
+		if err := json.Unmarshal([]byte(resolutions), &point.Resolutions); err != nil {
+			point.Resolutions = map[string]int{}
+		}
+		point.Date = bucketStart(date, bucket).Format(snapshotDateLayout)
+
+		// Rows are in date order, so a later snapshot in the same bucket replaces the earlier one
+		if n := len(history.Points); n > 0 && history.Points[n-1].Date == point.Date {
+			history.Points[n-1] = point
+		} else {
+			history.Points = append(history.Points, point)
+		}
+	}
+	return history, rows.Err()
+}
+
+// libraryGrowth is the change in total library size over a span of snapshots
+type libraryGrowth struct {
+	Days       float64
+	StartCount int
+	EndCount   int
+	StartBytes int64
+	EndBytes   int64
+}
+
+// VideosPerDay is the average number of videos added per day
+func (g *libraryGrowth) VideosPerDay() float64 {
+	return float64(g.EndCount-g.StartCount) / g.Days
+}
+
+// BytesPerDay is the average number of bytes added per day
+func (g *libraryGrowth) BytesPerDay() float64 {
+	return float64(g.EndBytes-g.StartBytes) / g.Days
+}
+
+// growthSince compares the earliest and latest snapshot days since a date, across
+// every library or one when libraryID is set. Each library is measured from its own
+// first snapshot in the window, so one added or removed meanwhile doesn't count as
+// growth or shrinkage. Returns nil without at least two snapshot days to compare.
+func (s *LibraryService) growthSince(since time.Time, libraryID int64) (*libraryGrowth, error) {
+	query := `
+		SELECT library_id, snapshot_date, video_count, total_bytes
+		FROM library_stats_history
+		WHERE snapshot_date >= ?
+	`
+	args := []interface{}{since.Format(snapshotDateLayout)}
+	if libraryID > 0 {
+		query += " AND library_id = ?"
+		args = append(args, libraryID)
+	}
+	query += " ORDER BY snapshot_date ASC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	// Each library's first and last snapshot in the window
+	type snapshot struct {
+		count int
+		bytes int64
+	}
+	starts := make(map[int64]snapshot)
+	ends := make(map[int64]snapshot)
+	var first, last time.Time
+	for rows.Next() {
+		var id int64
+		var date time.Time
+		var snap snapshot
+		if err := rows.Scan(&id, &date, &snap.count, &snap.bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan stats snapshot: %w", err)
+		}
+		if _, ok := starts[id]; !ok {
+			starts[id] = snap
+		}
+		ends[id] = snap
+		if first.IsZero() {
+			first = date
+		}
+		last = date
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	growth := &libraryGrowth{}
+	for id, start := range starts {
+		growth.StartCount += start.count
+		growth.StartBytes += start.bytes
+		growth.EndCount += ends[id].count
+		growth.EndBytes += ends[id].bytes
+	}
+
+	if !last.After(first) {
+		return nil, nil
+	}
+	growth.Days = last.Sub(first).Hours() / 24
+	return growth, nil
+}
@@ -24,10 +24,32 @@ func NewLibraryService() *LibraryService {
 	}
 }
 
+// libraryColumns are the columns scanLibraryRow expects
+const libraryColumns = `id, name, path, primary_lib, created_at, updated_at,
+		COALESCE(status, 'online'), status_checked_at, offline_since`
+
+// scanLibraryRow scans a row selected with libraryColumns
+func scanLibraryRow(row interface{ Scan(...interface{}) error }) (*models.Library, error) {
+	var lib models.Library
+	var checkedAt, offlineSince sql.NullTime
+	err := row.Scan(&lib.ID, &lib.Name, &lib.Path, &lib.Primary, &lib.CreatedAt, &lib.UpdatedAt,
+		&lib.Status, &checkedAt, &offlineSince)
+	if err != nil {
+		return nil, err
+	}
+	if checkedAt.Valid {
+		lib.StatusCheckedAt = &checkedAt.Time
+	}
+	if offlineSince.Valid {
+		lib.OfflineSince = &offlineSince.Time
+	}
+	return &lib, nil
+}
+
 // GetAll retrieves all libraries
 func (s *LibraryService) GetAll() ([]models.Library, error) {
 	query := `
-		SELECT id, name, path, primary_lib, created_at, updated_at
+		SELECT ` + libraryColumns + `
 		FROM libraries
 		ORDER BY primary_lib DESC, name ASC
 	`
@@ -44,12 +66,11 @@ func (s *LibraryService) GetAll() ([]models.Library, error) {
 
 	var libraries []models.Library
 	for rows.Next() {
-		var lib models.Library
-		err := rows.Scan(&lib.ID, &lib.Name, &lib.Path, &lib.Primary, &lib.CreatedAt, &lib.UpdatedAt)
+		lib, err := scanLibraryRow(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan library: %w", err)
 		}
-		libraries = append(libraries, lib)
+		libraries = append(libraries, *lib)
 	}
 
 	return libraries, nil
@@ -58,15 +79,12 @@ func (s *LibraryService) GetAll() ([]models.Library, error) {
 // GetByID retrieves a library by ID
 func (s *LibraryService) GetByID(id int64) (*models.Library, error) {
 	query := `
-		SELECT id, name, path, primary_lib, created_at, updated_at
+		SELECT ` + libraryColumns + `
 		FROM libraries
 		WHERE id = ?
 	`
 
-	var lib models.Library
-	err := s.db.QueryRow(query, id).Scan(
-		&lib.ID, &lib.Name, &lib.Path, &lib.Primary, &lib.CreatedAt, &lib.UpdatedAt,
-	)
+	lib, err := scanLibraryRow(s.db.QueryRow(query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("library not found")
@@ -75,21 +93,18 @@ func (s *LibraryService) GetByID(id int64) (*models.Library, error) {
 		return nil, fmt.Errorf("failed to query library: %w", err)
 	}
 
-	return &lib, nil
+	return lib, nil
 }
 
 // GetByName retrieves a library by name
 func (s *LibraryService) GetByName(name string) (*models.Library, error) {
 	query := `
-		SELECT id, name, path, primary_lib, created_at, updated_at
+		SELECT ` + libraryColumns + `
 		FROM libraries
 		WHERE name = ?
 	`
 
-	var lib models.Library
-	err := s.db.QueryRow(query, name).Scan(
-		&lib.ID, &lib.Name, &lib.Path, &lib.Primary, &lib.CreatedAt, &lib.UpdatedAt,
-	)
+	lib, err := scanLibraryRow(s.db.QueryRow(query, name))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("library not found")
@@ -98,22 +113,19 @@ func (s *LibraryService) GetByName(name string) (*models.Library, error) {
 		return nil, fmt.Errorf("failed to query library: %w", err)
 	}
 
-	return &lib, nil
+	return lib, nil
 }
 
 // GetPrimary retrieves the primary library
 func (s *LibraryService) GetPrimary() (*models.Library, error) {
 	query := `
-		SELECT id, name, path, primary_lib, created_at, updated_at
+		SELECT ` + libraryColumns + `
 		FROM libraries
 		WHERE primary_lib = 1
 		LIMIT 1
 	`
 
-	var lib models.Library
-	err := s.db.QueryRow(query).Scan(
-		&lib.ID, &lib.Name, &lib.Path, &lib.Primary, &lib.CreatedAt, &lib.UpdatedAt,
-	)
+	lib, err := scanLibraryRow(s.db.QueryRow(query))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("no primary library found")
@@ -122,7 +134,7 @@ func (s *LibraryService) GetPrimary() (*models.Library, error) {
 		return nil, fmt.Errorf("failed to query primary library: %w", err)
 	}
 
-	return &lib, nil
+	return lib, nil
 }
 
 // Create creates a new library
@@ -236,12 +248,32 @@ func (s *LibraryService) Delete(id int64) error {
 		return err
 	}
 
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back library delete: %v", err)
+		}
+	}()
+
+	// Trash items can't be restored or purged without their library; their
+	// files stay in the library's trash folder like the rest of its files
+	if _, err := tx.Exec(`DELETE FROM trash_items WHERE library_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete library trash items: %w", err)
+	}
+
 	query := `DELETE FROM libraries WHERE id = ?`
-	_, err = s.db.Exec(query, id)
+	_, err = tx.Exec(query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete library: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit library delete: %w", err)
+	}
+	cacheLibraryStatus(id, false)
 	return nil
 }
 
@@ -264,6 +296,19 @@ func (s *LibraryService) GetWithStats(id int64) (*models.LibraryWithStats, error
 	}
 	stats.VideoCount = videoCount
 
+	if err := s.db.QueryRow(`SELECT COALESCE(SUM(file_size), 0) FROM videos WHERE library_id = ?`, id).Scan(&stats.TotalSize); err != nil {
+		stats.TotalSize = 0
+	}
+
+	// Get disk capacity
+	if thresholds, err := s.GetDiskThresholds(); err == nil {
+		usage := s.LibraryDiskUsage(library, thresholds)
+		stats.DiskTotalBytes = usage.TotalBytes
+		stats.DiskFreeBytes = usage.FreeBytes
+		stats.DiskLevel = usage.Level
+		stats.DaysUntilFull = usage.DaysUntilFull
+	}
+
 	return stats, nil
 }
 
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// defaultLibrarySettings returns the settings used by libraries that were never configured
+func defaultLibrarySettings(libraryID int64) *models.LibrarySettings {
+	return &models.LibrarySettings{
+		LibraryID:       libraryID,
+		IncludePatterns: []string{},
+		ExcludePatterns: []string{},
+		Extensions:      []string{},
+		AutoThumbnails:  true,
+		AutoPreviews:    true,
+	}
+}
+
+// GetSettings retrieves the scan settings for a library, falling back to defaults
+func (s *LibraryService) GetSettings(libraryID int64) (*models.LibrarySettings, error) {
+	if _, err := s.GetByID(libraryID); err != nil {
+		return nil, err
+	}
+
+	settings := defaultLibrarySettings(libraryID)
+	var includeJSON, excludeJSON, extensionsJSON string
+	var updatedAt sql.NullTime
+
+	err := s.db.QueryRow(`
+		SELECT COALESCE(include_patterns, '[]'), COALESCE(exclude_patterns, '[]'), COALESCE(extensions, '[]'),
+			COALESCE(min_file_size, 0), COALESCE(min_duration, 0), COALESCE(max_depth, 0),
+			COALESCE(follow_symlinks, 0), COALESCE(auto_thumbnails, 1), COALESCE(auto_previews, 1), updated_at
+		FROM library_settings
+		WHERE library_id = ?
+	`, libraryID).Scan(
+		&includeJSON, &excludeJSON, &extensionsJSON,
+		&settings.MinFileSize, &settings.MinDuration, &settings.MaxDepth,
+		&settings.FollowSymlinks, &settings.AutoThumbnails, &settings.AutoPreviews, &updatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query library settings: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(includeJSON), &settings.IncludePatterns); err != nil {
+		log.Printf("Invalid include patterns for library %d: %v", libraryID, err)
+	}
+	if err := json.Unmarshal([]byte(excludeJSON), &settings.ExcludePatterns); err != nil {
+		log.Printf("Invalid exclude patterns for library %d: %v", libraryID, err)
+	}
+	if err := json.Unmarshal([]byte(extensionsJSON), &settings.Extensions); err != nil {
+		log.Printf("Invalid extensions for library %d: %v", libraryID, err)
+	}
+	if updatedAt.Valid {
+		settings.UpdatedAt = updatedAt.Time
+	}
+
+	return settings, nil
+}
+
+// UpdateSettings applies an update to a library's scan settings
+func (s *LibraryService) UpdateSettings(libraryID int64, update *models.LibrarySettingsUpdate) (*models.LibrarySettings, error) {
+	settings, err := s.GetSettings(libraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.IncludePatterns != nil {
+		settings.IncludePatterns = cleanPatterns(*update.IncludePatterns)
+	}
+	if update.ExcludePatterns != nil {
+		settings.ExcludePatterns = cleanPatterns(*update.ExcludePatterns)
+	}
+	if update.Extensions != nil {
+		settings.Extensions = normalizeExtensions(*update.Extensions)
+	}
+	if update.MinFileSize != nil {
+		if *update.MinFileSize < 0 {
+			return nil, fmt.Errorf("min_file_size cannot be negative")
+		}
+		settings.MinFileSize = *update.MinFileSize
+	}
+	if update.MinDuration != nil {
+		if *update.MinDuration < 0 {
+			return nil, fmt.Errorf("min_duration cannot be negative")
+		}
+		settings.MinDuration = *update.MinDuration
+	}
+	if update.MaxDepth != nil {
+		if *update.MaxDepth < 0 {
+			return nil, fmt.Errorf("max_depth cannot be negative")
+		}
+		settings.MaxDepth = *update.MaxDepth
+	}
+	if update.FollowSymlinks != nil {
+		settings.FollowSymlinks = *update.FollowSymlinks
+	}
+	if update.AutoThumbnails != nil {
+		settings.AutoThumbnails = *update.AutoThumbnails
+	}
+	if update.AutoPreviews != nil {
+		settings.AutoPreviews = *update.AutoPreviews
+	}
+
+	for _, pattern := range append(append([]string{}, settings.IncludePatterns...), settings.ExcludePatterns...) {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	includeJSON, _ := json.Marshal(settings.IncludePatterns)
+	excludeJSON, _ := json.Marshal(settings.ExcludePatterns)
+	extensionsJSON, _ := json.Marshal(settings.Extensions)
+	settings.UpdatedAt = time.Now()
+
+	_, err = s.db.Exec(`
+		INSERT INTO library_settings (library_id, include_patterns, exclude_patterns, extensions,
+			min_file_size, min_duration, max_depth, follow_symlinks, auto_thumbnails, auto_previews, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(library_id) DO UPDATE SET
+			include_patterns = excluded.include_patterns,
+			exclude_patterns = excluded.exclude_patterns,
+			extensions = excluded.extensions,
+			min_file_size = excluded.min_file_size,
+			min_duration = excluded.min_duration,
+			max_depth = excluded.max_depth,
+			follow_symlinks = excluded.follow_symlinks,
+			auto_thumbnails = excluded.auto_thumbnails,
+			auto_previews = excluded.auto_previews,
+			updated_at = excluded.updated_at
+	`, libraryID, string(includeJSON), string(excludeJSON), string(extensionsJSON),
+		settings.MinFileSize, settings.MinDuration, settings.MaxDepth,
+		settings.FollowSymlinks, settings.AutoThumbnails, settings.AutoPreviews, settings.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save library settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// GetScanFilter builds the file filter for a library from its settings
+func (s *LibraryService) GetScanFilter(library *models.Library) (*LibraryScanFilter, error) {
+	settings, err := s.GetSettings(library.ID)
+	if err != nil {
+		return nil, err
+	}
+	return NewLibraryScanFilter(library.Path, settings), nil
+}
+
+// cleanPatterns trims patterns, drops empty ones and normalizes separators
+func cleanPatterns(patterns []string) []string {
+	cleaned := []string{}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(filepath.ToSlash(pattern))
+		pattern = strings.Trim(pattern, "/")
+		if pattern != "" {
+			cleaned = append(cleaned, pattern)
+		}
+	}
+	return cleaned
+}
+
+// normalizeExtensions lowercases extensions and makes sure they start with a dot
+func normalizeExtensions(extensions []string) []string {
+	normalized := []string{}
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		normalized = append(normalized, ext)
+	}
+	return normalized
+}
+
+// LibraryScanFilter decides which directories and files of a library are indexed
+type LibraryScanFilter struct {
+	Settings   *models.LibrarySettings
+	root       string
+	extensions map[string]bool
+	include    []string
+	exclude    []string
+}
+
+// NewLibraryScanFilter creates a filter for the library rooted at root
+func NewLibraryScanFilter(root string, settings *models.LibrarySettings) *LibraryScanFilter {
+	extensions := settings.Extensions
+	if len(extensions) == 0 {
+		extensions = SupportedVideoExtensions
+	}
+
+	f := &LibraryScanFilter{
+		Settings:   settings,
+		root:       filepath.Clean(root),
+		extensions: make(map[string]bool, len(extensions)),
+	}
+	for _, ext := range normalizeExtensions(extensions) {
+		f.extensions[ext] = true
+	}
+	for _, pattern := range cleanPatterns(settings.IncludePatterns) {
+		f.include = append(f.include, strings.ToLower(pattern))
+	}
+	for _, pattern := range cleanPatterns(settings.ExcludePatterns) {
+		f.exclude = append(f.exclude, strings.ToLower(pattern))
+	}
+	return f
+}
+
+// relativeSegments returns the lowercased path components of p below the library root
+func (f *LibraryScanFilter) relativeSegments(p string) []string {
+	rel, err := filepath.Rel(f.root, filepath.Clean(p))
+	if err != nil || rel == "." {
+		return nil
+	}
+	return strings.Split(strings.ToLower(filepath.ToSlash(rel)), "/")
+}
+
+// SkipDir reports whether a directory should not be descended into
+func (f *LibraryScanFilter) SkipDir(dir string) bool {
+	segments := f.relativeSegments(dir)
+	if len(segments) == 0 {
+		return false
+	}
+	if segments[0] == trashDirName {
+		return true
+	}
+	if f.Settings.MaxDepth > 0 && len(segments) > f.Settings.MaxDepth {
+		return true
+	}
+	return matchesAnyPattern(f.exclude, segments)
+}
+
+// IsVideoExtension reports whether the file has an allowed extension
+func (f *LibraryScanFilter) IsVideoExtension(name string) bool {
+	return f.extensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// MatchFile reports whether a file should be indexed, based on everything known
+// without probing it. A negative size skips the minimum size check.
+func (f *LibraryScanFilter) MatchFile(filePath string, size int64) bool {
+	if !f.IsVideoExtension(filePath) {
+		return false
+	}
+	if size >= 0 && size < f.Settings.MinFileSize {
+		return false
+	}
+
+	segments := f.relativeSegments(filePath)
+	if len(segments) == 0 || segments[0] == trashDirName {
+		return false
+	}
+	if f.Settings.MaxDepth > 0 && len(segments)-1 > f.Settings.MaxDepth {
+		return false
+	}
+	if matchesAnyPattern(f.exclude, segments) {
+		return false
+	}
+	return len(f.include) == 0 || matchesAnyPattern(f.include, segments)
+}
+
+// MatchDuration reports whether a probed duration passes the minimum. Unknown
+// durations pass so files ffprobe can't read aren't silently dropped.
+func (f *LibraryScanFilter) MatchDuration(duration float64) bool {
+	return duration <= 0 || duration >= f.Settings.MinDuration
+}
+
+// Walk calls fn for every file in the library that passes MatchFile, honoring the
+// depth limit, excluded directories and the follow-symlinks setting
+func (f *LibraryScanFilter) Walk(fn func(filePath string, info os.FileInfo)) error {
+	visited := make(map[string]bool)
+	return f.walkDir(f.root, visited, fn)
+}
+
+func (f *LibraryScanFilter) walkDir(dir string, visited map[string]bool, fn func(string, os.FileInfo)) error {
+	// Symlinked directories can loop back on themselves
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		if visited[real] {
+			return nil
+		}
+		visited[real] = true
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if dir == f.root {
+			return err
+		}
+		log.Printf("Skipping unreadable directory %s: %v", dir, err)
+		return nil
+	}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !f.Settings.FollowSymlinks {
+				continue
+			}
+			if info, err = os.Stat(entryPath); err != nil {
+				continue
+			}
+			// Links back into the library would index the same files twice
+			if info.IsDir() && f.containsRealPath(entryPath) {
+				continue
+			}
+		}
+
+		if info.IsDir() {
+			if f.SkipDir(entryPath) {
+				continue
+			}
+			if err := f.walkDir(entryPath, visited, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if f.MatchFile(entryPath, info.Size()) {
+			fn(entryPath, info)
+		}
+	}
+
+	return nil
+}
+
+// containsRealPath reports whether p resolves to a location inside the library root
+func (f *LibraryScanFilter) containsRealPath(p string) bool {
+	realRoot, err := filepath.EvalSymlinks(f.root)
+	if err != nil {
+		return false
+	}
+	real, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(realRoot, real)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// matchesAnyPattern reports whether any pattern matches the relative path. Patterns
+// without a slash match any single component, so "_trash" excludes that folder
+// anywhere; patterns with a slash match the path or one of its ancestors, with
+// "**" standing for any number of components.
+func matchesAnyPattern(patterns []string, segments []string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			for _, segment := range segments {
+				if ok, _ := path.Match(pattern, segment); ok {
+					return true
+				}
+			}
+			continue
+		}
+
+		patternSegments := strings.Split(pattern, "/")
+		for i := 1; i <= len(segments); i++ {
+			if matchSegments(patternSegments, segments[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchSegments matches path components against glob components with "**" support
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// ErrLibraryOffline is returned when an operation needs a library root that isn't reachable
+var ErrLibraryOffline = errors.New("library is offline")
+
+// libraryProbeTimeout bounds how long a hung network mount can block a status check
+const libraryProbeTimeout = 10 * time.Second
+
+// offlineLibraries caches the IDs of libraries last seen offline. Every video
+// listing needs them, while they only change when a status check flips one.
+var offlineLibraries struct {
+	sync.Mutex
+	ids map[int64]bool // nil until loaded; replaced, never modified, once handed out
+}
+
+// cacheLibraryStatus records a library's status in the offline cache, if loaded
+func cacheLibraryStatus(libraryID int64, offline bool) {
+	offlineLibraries.Lock()
+	defer offlineLibraries.Unlock()
+
+	if offlineLibraries.ids == nil || offlineLibraries.ids[libraryID] == offline {
+		return
+	}
+	ids := make(map[int64]bool, len(offlineLibraries.ids)+1)
+	for id := range offlineLibraries.ids {
+		if id != libraryID {
+			ids[id] = true
+		}
+	}
+	if offline {
+		ids[libraryID] = true
+	}
+	offlineLibraries.ids = ids
+}
+
+// probeLibraryRoot checks that a library root is a readable directory. A root that
+// is empty while the database still has videos for it is treated as an unmounted
+// drive's bare mount point.
+func probeLibraryRoot(path string, expectFiles bool) error {
+	done := make(chan error, 1)
+	go func() {
+		dir, err := os.Open(path)
+		if err != nil {
+			done <- err
+			return
+		}
+		defer func() {
+			if err := dir.Close(); err != nil {
+				log.Printf("failed to close %s: %v", path, err)
+			}
+		}()
+
+		info, err := dir.Stat()
+		if err != nil {
+			done <- err
+			return
+		}
+		if !info.IsDir() {
+			done <- fmt.Errorf("%s is not a directory", path)
+			return
+		}
+		if _, err := dir.Readdirnames(1); err == io.EOF {
+			if expectFiles {
+				done <- fmt.Errorf("%s is empty; the drive may be unmounted", path)
+				return
+			}
+		} else if err != nil {
+			done <- err
+			return
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(libraryProbeTimeout):
+		return fmt.Errorf("timed out reading %s", path)
+	}
+}
+
+// CheckStatus probes a library's root, stores the result and broadcasts a
+// library_status event when the library went offline or came back. The library
+// is updated in place; the returned bool reports whether the status changed.
+func (s *LibraryService) CheckStatus(library *models.Library) (bool, error) {
+	var hasVideos bool
+	err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM videos WHERE library_id = ? AND COALESCE(status, 'available') = ?)`,
+		library.ID, models.VideoStatusAvailable).Scan(&hasVideos)
+	if err != nil {
+		return false, fmt.Errorf("failed to count library videos: %w", err)
+	}
+
+	status := models.LibraryStatusOnline
+	if err := probeLibraryRoot(library.Path, hasVideos); err != nil {
+		status = models.LibraryStatusOffline
+		if library.Status != models.LibraryStatusOffline {
+			log.Printf("Library %s is offline: %v", library.Name, err)
+		}
+	}
+
+	now := time.Now()
+	changed := status != library.Status
+	if status == models.LibraryStatusOffline && (changed || library.OfflineSince == nil) {
+		library.OfflineSince = &now
+	} else if status == models.LibraryStatusOnline {
+		library.OfflineSince = nil
+	}
+	library.Status = status
+	library.StatusCheckedAt = &now
+
+	_, err = s.db.Exec(`UPDATE libraries SET status = ?, status_checked_at = ?, offline_since = ? WHERE id = ?`,
+		library.Status, library.StatusCheckedAt, library.OfflineSince, library.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update library status: %w", err)
+	}
+	cacheLibraryStatus(library.ID, library.Status == models.LibraryStatusOffline)
+
+	if changed && wsHub != nil {
+		wsHub.BroadcastLibraryStatus(library)
+	}
+	return changed, nil
+}
+
+// CheckAllStatuses probes every library and returns the ones whose status changed
+func (s *LibraryService) CheckAllStatuses() ([]models.Library, error) {
+	libraries, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []models.Library
+	for i := range libraries {
+		didChange, err := s.CheckStatus(&libraries[i])
+		if err != nil {
+			log.Printf("Failed to check status of library %s: %v", libraries[i].Name, err)
+			continue
+		}
+		if didChange {
+			changed = append(changed, libraries[i])
+		}
+	}
+	return changed, nil
+}
+
+// EnsureOnline re-probes a library and returns an ErrLibraryOffline error when its
+// root can't be read, so scans and cleanup never mistake an unmounted drive for
+// deleted files
+func (s *LibraryService) EnsureOnline(library *models.Library) error {
+	if _, err := s.CheckStatus(library); err != nil {
+		return err
+	}
+	if library.Status == models.LibraryStatusOffline {
+		return fmt.Errorf("%w: %s (%s)", ErrLibraryOffline, library.Name, library.Path)
+	}
+	return nil
+}
+
+// OfflineLibraryIDs returns the libraries last seen offline. The map is shared
+// and must not be modified.
+func (s *LibraryService) OfflineLibraryIDs() (map[int64]bool, error) {
+	offlineLibraries.Lock()
+	defer offlineLibraries.Unlock()
+	if offlineLibraries.ids != nil {
+		return offlineLibraries.ids, nil
+	}
+
+	rows, err := s.db.Query(`SELECT id FROM libraries WHERE status = ?`, models.LibraryStatusOffline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query offline libraries: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	offline := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan library id: %w", err)
+		}
+		offline[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	offlineLibraries.ids = offline
+	return offline, nil
+}
+
+// markUnavailable flags videos whose library is offline, so listings keep working
+// while the drive is unmounted
+func (s *VideoService) markUnavailable(videos []models.Video) {
+	offline, err := s.libraryService.OfflineLibraryIDs()
+	if err != nil {
+		log.Printf("Warning: Failed to load offline libraries: %v", err)
+		return
+	}
+	if len(offline) == 0 {
+		return
+	}
+	for i := range videos {
+		videos[i].Unavailable = offline[videos[i].LibraryID]
+	}
+}
@@ -0,0 +1,518 @@
+package services
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// partialHashChunkSize is how much of the head and tail of a file goes into its partial hash
+const partialHashChunkSize = 64 * 1024
+
+// fileFingerprint identifies a file's content independently of its path
+type fileFingerprint struct {
+	Size        int64
+	ModifiedAt  time.Time
+	PartialHash string
+	OSHash      string
+}
+
+// indexedVideo is the subset of a video row needed to diff a library against disk
+type indexedVideo struct {
+	ID            int64
+	LibraryID     int64
+	LibraryPath   string
+	FilePath      string
+	FileSize      int64
+	ModifiedAt    *time.Time
+	PartialHash   string
+	OSHash        string
+	ThumbnailPath string
+	PreviewPath   string
+	Status        string
+}
+
+// SyncResult summarizes what an incremental library scan changed
+type SyncResult struct {
+	Added     int `json:"added"`
+	Moved     int `json:"moved"`
+	Restored  int `json:"restored"`
+	Missing   int `json:"missing"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
+}
+
+// computeFingerprint stats a file and hashes its size plus the first and last 64KB.
+// Reading only the edges keeps this cheap on network shares while still telling
+// apart files that merely share a size. The same reads produce the file's oshash.
+func computeFingerprint(path string) (*fileFingerprint, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Printf("failed to close %s: %v", path, err)
+		}
+	}()
+
+	head := make([]byte, partialHashChunkSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read head of %s: %w", path, err)
+	}
+	head = head[:n]
+
+	tail := head
+	if info.Size() > partialHashChunkSize {
+		tail = make([]byte, partialHashChunkSize)
+		if _, err := file.Seek(-partialHashChunkSize, io.SeekEnd); err != nil {
+			return nil, fmt.Errorf("failed to seek in %s: %w", path, err)
+		}
+		n, err = io.ReadFull(file, tail)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return nil, fmt.Errorf("failed to read tail of %s: %w", path, err)
+		}
+		tail = tail[:n]
+	}
+
+	hasher := sha1.New()
+	sizeBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(sizeBytes, uint64(info.Size()))
+	hasher.Write(sizeBytes)
+	hasher.Write(head)
+	if info.Size() > 2*partialHashChunkSize {
+		hasher.Write(tail)
+	}
+
+	return &fileFingerprint{
+		Size:        info.Size(),
+		ModifiedAt:  info.ModTime().UTC().Truncate(time.Second),
+		PartialHash: hex.EncodeToString(hasher.Sum(nil)),
+		OSHash:      osHash(info.Size(), head, tail),
+	}, nil
+}
+
+// osHash computes the OpenSubtitles hash: the file size plus the little-endian
+// uint64 words of the first and last 64KB, formatted as 16 hex digits
+func osHash(size int64, head, tail []byte) string {
+	hash := uint64(size)
+	for _, chunk := range [][]byte{head, tail} {
+		for i := 0; i+8 <= len(chunk); i += 8 {
+			hash += binary.LittleEndian.Uint64(chunk[i : i+8])
+		}
+	}
+	return fmt.Sprintf("%016x", hash)
+}
+
+// loadLibraryIndex loads every video row of a library keyed by file path
+func (s *VideoService) loadLibraryIndex(libraryID int64) (map[string]*indexedVideo, error) {
+	query := `
+		SELECT v.id, v.library_id, COALESCE(l.path, ''), v.file_path, COALESCE(v.file_size, 0), v.file_modified_at,
+		       COALESCE(v.partial_hash, ''), COALESCE(v.oshash, ''), COALESCE(v.thumbnail_path, ''), COALESCE(v.preview_path, ''),
+		       COALESCE(v.status, 'available')
+		FROM videos v
+		LEFT JOIN libraries l ON l.id = v.library_id
+		WHERE v.library_id = ?
+	`
+	rows, err := s.db.Query(query, libraryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load library index: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	index := make(map[string]*indexedVideo)
+	for rows.Next() {
+		row, err := scanIndexedVideo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan library index: %w", err)
+		}
+		index[row.FilePath] = row
+	}
+
+	return index, rows.Err()
+}
+
+// loadMissingVideos loads rows already marked missing in any library. These are
+// move candidates too, so a file that reappears in another library keeps its metadata.
+func (s *VideoService) loadMissingVideos() ([]*indexedVideo, error) {
+	query := `
+		SELECT v.id, v.library_id, COALESCE(l.path, ''), v.file_path, COALESCE(v.file_size, 0), v.file_modified_at,
+		       COALESCE(v.partial_hash, ''), COALESCE(v.oshash, ''), COALESCE(v.thumbnail_path, ''), COALESCE(v.preview_path, ''),
+		       COALESCE(v.status, 'available')
+		FROM videos v
+		LEFT JOIN libraries l ON l.id = v.library_id
+		WHERE v.status = ?
+	`
+	rows, err := s.db.Query(query, models.VideoStatusMissing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load missing videos: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var missing []*indexedVideo
+	for rows.Next() {
+		row, err := scanIndexedVideo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan missing video: %w", err)
+		}
+		missing = append(missing, row)
+	}
+
+	return missing, rows.Err()
+}
+
+// scanIndexedVideo scans a row selected by loadLibraryIndex or loadMissingVideos
+func scanIndexedVideo(rows *sql.Rows) (*indexedVideo, error) {
+	var row indexedVideo
+	var modifiedAt sql.NullTime
+	var libraryID sql.NullInt64
+	err := rows.Scan(&row.ID, &libraryID, &row.LibraryPath, &row.FilePath, &row.FileSize, &modifiedAt,
+		&row.PartialHash, &row.OSHash, &row.ThumbnailPath, &row.PreviewPath, &row.Status)
+	if err != nil {
+		return nil, err
+	}
+	row.LibraryID = libraryID.Int64
+	if modifiedAt.Valid {
+		t := modifiedAt.Time.UTC()
+		row.ModifiedAt = &t
+	}
+	return &row, nil
+}
+
+// moveCandidates indexes vanished and missing rows by file size for move detection
+type moveCandidates struct {
+	bySize map[int64][]*indexedVideo
+}
+
+// newMoveCandidates builds the candidate set, ignoring duplicate rows
+func newMoveCandidates(rows []*indexedVideo) *moveCandidates {
+	c := &moveCandidates{bySize: make(map[int64][]*indexedVideo)}
+	seen := make(map[int64]bool)
+	for _, row := range rows {
+		if seen[row.ID] {
+			continue
+		}
+		seen[row.ID] = true
+		c.bySize[row.FileSize] = append(c.bySize[row.FileSize], row)
+	}
+	return c
+}
+
+// sameContent reports whether a row's stored hashes identify the fingerprinted file
+func (row *indexedVideo) sameContent(fp *fileFingerprint) bool {
+	if row.OSHash != "" && row.OSHash == fp.OSHash {
+		return true
+	}
+	return row.PartialHash != "" && row.PartialHash == fp.PartialHash
+}
+
+// claim returns the candidate row that a new file most likely used to be, and removes
+// it from the set. A content hash match is required when the row has one; legacy rows
+// without a hash only match on size plus identical mtime, and only when unambiguous.
+func (c *moveCandidates) claim(fp *fileFingerprint) *indexedVideo {
+	rows := c.bySize[fp.Size]
+	if len(rows) == 0 {
+		return nil
+	}
+
+	match := -1
+	for i, row := range rows {
+		if row.sameContent(fp) {
+			// Prefer a row whose mtime also matches when several share the hash
+			if match == -1 || (row.ModifiedAt != nil && row.ModifiedAt.Equal(fp.ModifiedAt)) {
+				match = i
+			}
+		}
+	}
+
+	if match == -1 {
+		legacyMatches := 0
+		for i, row := range rows {
+			if row.PartialHash == "" && row.OSHash == "" && row.ModifiedAt != nil && row.ModifiedAt.Equal(fp.ModifiedAt) {
+				legacyMatches++
+				match = i
+			}
+		}
+		if legacyMatches != 1 {
+			return nil
+		}
+	}
+
+	row := rows[match]
+	c.bySize[fp.Size] = append(rows[:match:match], rows[match+1:]...)
+	return row
+}
+
+// fingerprintStale reports whether the file at path needs fingerprinting again:
+// the row was never fingerprinted, or the file's size or modification time no
+// longer match, as when it was overwritten in place
+func (row *indexedVideo) fingerprintStale(path string) bool {
+	if row.PartialHash == "" || row.OSHash == "" || row.ModifiedAt == nil {
+		return true
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.Size() != row.FileSize || !info.ModTime().UTC().Truncate(time.Second).Equal(*row.ModifiedAt)
+}
+
+// scanExclusion is a file the library's minimum duration kept out, with the
+// duration it was probed at. Scans skip probing it again while it's unchanged.
+type scanExclusion struct {
+	Size       int64
+	ModifiedAt time.Time
+	Duration   float64
+}
+
+// unchanged reports whether the file still has the size and modification time
+// it was excluded with
+func (e *scanExclusion) unchanged(fp *fileFingerprint) bool {
+	return e.Size == fp.Size && e.ModifiedAt.Equal(fp.ModifiedAt)
+}
+
+// loadScanExclusions reads a library's excluded files by path
+func (s *VideoService) loadScanExclusions(libraryID int64) (map[string]*scanExclusion, error) {
+	rows, err := s.db.Query(`
+		SELECT file_path, file_size, file_modified_at, duration FROM scan_exclusions WHERE library_id = ?
+	`, libraryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scan exclusions: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	exclusions := make(map[string]*scanExclusion)
+	for rows.Next() {
+		var path string
+		var e scanExclusion
+		if err := rows.Scan(&path, &e.Size, &e.ModifiedAt, &e.Duration); err != nil {
+			return nil, fmt.Errorf("failed to scan scan exclusion: %w", err)
+		}
+		exclusions[path] = &e
+	}
+	return exclusions, rows.Err()
+}
+
+// saveScanExclusion remembers that a file was kept out for its duration
+func (s *VideoService) saveScanExclusion(libraryID int64, filePath string, fp *fileFingerprint, duration float64) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO scan_exclusions (library_id, file_path, file_size, file_modified_at, duration, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, libraryID, filePath, fp.Size, fp.ModifiedAt, duration, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save scan exclusion: %w", err)
+	}
+	return nil
+}
+
+// pruneScanExclusions forgets the exclusions of files that are gone, changed
+// or now indexed, which is every loaded one the scan didn't reuse
+func (s *VideoService) pruneScanExclusions(libraryID int64, exclusions map[string]*scanExclusion, reused map[string]bool) {
+	for path := range exclusions {
+		if reused[path] {
+			continue
+		}
+		if _, err := s.db.Exec(`DELETE FROM scan_exclusions WHERE library_id = ? AND file_path = ?`, libraryID, path); err != nil {
+			log.Printf("Failed to delete scan exclusion for %s: %v", path, err)
+		}
+	}
+}
+
+// saveFingerprint stores a file's fingerprint on its video row. The full MD5 is
+// cleared so the next hashing pass recomputes it for the new content.
+func (s *VideoService) saveFingerprint(videoID int64, fp *fileFingerprint) error {
+	_, err := s.db.Exec(`
+		UPDATE videos SET file_size = ?, file_modified_at = ?, partial_hash = ?, oshash = ?, md5 = NULL WHERE id = ?
+	`, fp.Size, fp.ModifiedAt, fp.PartialHash, fp.OSHash, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to save fingerprint: %w", err)
+	}
+	return nil
+}
+
+// applyMove re-points an existing video row at the file's new location, carrying its
+// thumbnail and preview along so tags, performers, ratings and play counts survive.
+func (s *VideoService) applyMove(row *indexedVideo, library *models.Library, newPath string, fp *fileFingerprint) error {
+	assets := NewMediaService().RelocateVideoAssets(
+		AssetLocation{LibraryID: library.ID, LibraryPath: library.Path, FilePath: newPath},
+		row.ThumbnailPath,
+		row.PreviewPath,
+	)
+
+	_, err := s.db.Exec(`
+		UPDATE videos
+		SET file_path = ?, library_id = ?, file_size = ?, file_modified_at = ?, partial_hash = ?, oshash = ?,
+		    thumbnail_path = ?, preview_path = ?, status = ?, missing_since = NULL, updated_at = ?
+		WHERE id = ?
+	`, newPath, library.ID, fp.Size, fp.ModifiedAt, fp.PartialHash, fp.OSHash,
+		assets.ThumbnailPath, assets.PreviewPath, models.VideoStatusAvailable, time.Now(), row.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update moved video %d: %w", row.ID, err)
+	}
+
+	log.Printf("Detected move of video %d: %s -> %s", row.ID, row.FilePath, newPath)
+	return nil
+}
+
+// setVideoStatus marks a video available or missing
+func (s *VideoService) setVideoStatus(videoID int64, status string) error {
+	var err error
+	if status == models.VideoStatusMissing {
+		_, err = s.db.Exec(`
+			UPDATE videos SET status = ?, missing_since = COALESCE(missing_since, ?), updated_at = ? WHERE id = ?
+		`, status, time.Now(), time.Now(), videoID)
+	} else {
+		_, err = s.db.Exec(`
+			UPDATE videos SET status = ?, missing_since = NULL, updated_at = ? WHERE id = ?
+		`, status, time.Now(), videoID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set status of video %d: %w", videoID, err)
+	}
+	return nil
+}
+
+// PurgeMissing permanently deletes videos marked missing, along with their thumbnails
+// and previews. libraryID 0 purges every library; olderThanDays 0 ignores how long
+// the videos have been missing.
+func (s *VideoService) PurgeMissing(libraryID int64, olderThanDays int) (int, error) {
+	query := `SELECT id, COALESCE(thumbnail_path, ''), COALESCE(preview_path, '') FROM videos WHERE status = ?`
+	args := []interface{}{models.VideoStatusMissing}
+
+	// Videos on an unmounted drive are only missing until it comes back
+	if libraryID > 0 {
+		library, err := s.libraryService.GetByID(libraryID)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.libraryService.EnsureOnline(library); err != nil {
+			return 0, err
+		}
+		query += " AND library_id = ?"
+		args = append(args, libraryID)
+	} else {
+		if _, err := s.libraryService.CheckAllStatuses(); err != nil {
+			return 0, err
+		}
+		query += " AND library_id NOT IN (SELECT id FROM libraries WHERE status = ?)"
+		args = append(args, models.LibraryStatusOffline)
+	}
+	if olderThanDays > 0 {
+		query += " AND missing_since < ?"
+		args = append(args, time.Now().AddDate(0, 0, -olderThanDays))
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query missing videos: %w", err)
+	}
+
+	type purgeTarget struct {
+		id            int64
+		thumbnailPath string
+		previewPath   string
+	}
+	var targets []purgeTarget
+	for rows.Next() {
+		var t purgeTarget
+		if err := rows.Scan(&t.id, &t.thumbnailPath, &t.previewPath); err != nil {
+			continue
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Close(); err != nil {
+		log.Printf("failed to close rows: %v", err)
+	}
+
+	if len(targets) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	placeholders := make([]string, len(targets))
+	ids := make([]interface{}, len(targets))
+	for i, t := range targets {
+		placeholders[i] = "?"
+		ids[i] = t.id
+	}
+	inClause := strings.Join(placeholders, ",")
+
+	// Remember affected performers so their video counts can be corrected afterwards
+	performerQuery := fmt.Sprintf(`SELECT DISTINCT performer_id FROM video_performers WHERE video_id IN (%s)`, inClause)
+	performerRows, err := tx.Query(performerQuery, ids...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query affected performers: %w", err)
+	}
+	var performerIDs []interface{}
+	for performerRows.Next() {
+		var performerID int64
+		if err := performerRows.Scan(&performerID); err == nil {
+			performerIDs = append(performerIDs, performerID)
+		}
+	}
+	if err := performerRows.Close(); err != nil {
+		log.Printf("failed to close performerRows: %v", err)
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM videos WHERE id IN (%s)`, inClause), ids...); err != nil {
+		return 0, fmt.Errorf("failed to delete missing videos: %w", err)
+	}
+
+	if len(performerIDs) > 0 {
+		performerPlaceholders := strings.TrimSuffix(strings.Repeat("?,", len(performerIDs)), ",")
+		countQuery := fmt.Sprintf(`
+			UPDATE performers
+			SET video_count = (SELECT COUNT(*) FROM video_performers WHERE performer_id = performers.id)
+			WHERE id IN (%s)
+		`, performerPlaceholders)
+		if _, err := tx.Exec(countQuery, performerIDs...); err != nil {
+			return 0, fmt.Errorf("failed to update performer video counts: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %w", err)
+	}
+
+	mediaService := NewMediaService()
+	for _, t := range targets {
+		mediaService.RemoveVideoAssets(t.thumbnailPath, t.previewPath)
+	}
+
+	return len(targets), nil
+}
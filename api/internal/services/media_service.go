@@ -519,3 +519,126 @@ func (s *MediaService) GeneratePreviewStoryboard(config PreviewConfig) (*Preview
 	log.Printf("Generated %d preview frames for %s (interval: %.2fs)", len(frames), videoFileName, interval)
 	return result, nil
 }
+
+// videoThumbnailDir returns the base directory for hierarchical video thumbnails
+func videoThumbnailDir() string {
+	thumbnailDir := os.Getenv("THUMBNAIL_DIR")
+	if thumbnailDir == "" {
+		thumbnailDir = filepath.Join("assets", "thumbnails")
+	}
+	return thumbnailDir
+}
+
+// videoPreviewDir returns the base directory for hierarchical preview storyboards
+func videoPreviewDir() string {
+	previewDir := os.Getenv("PREVIEW_DIR")
+	if previewDir == "" {
+		previewDir = filepath.Join("assets", "previews")
+	}
+	return previewDir
+}
+
+// GetPreviewPath returns the expected preview directory without generating it
+func (s *MediaService) GetPreviewPath(config PreviewConfig) *PreviewResult {
+	relativeVideoPath, err := filepath.Rel(config.LibraryPath, config.VideoFilePath)
+	if err != nil {
+		log.Printf("Failed to calculate relative path: %v", err)
+		return nil
+	}
+
+	relativeDir := filepath.Dir(relativeVideoPath)
+	videoFileName := filepath.Base(config.VideoFilePath)
+	videoBaseName := strings.TrimSuffix(videoFileName, filepath.Ext(videoFileName))
+
+	libraryPreviewDir := filepath.Join(config.PreviewDir, fmt.Sprintf("%d", config.LibraryID), relativeDir, videoBaseName)
+	relativePath := filepath.ToSlash(filepath.Join(fmt.Sprintf("%d", config.LibraryID), relativeDir, videoBaseName))
+
+	return &PreviewResult{
+		RelativePath: relativePath,
+		FullPath:     libraryPreviewDir,
+	}
+}
+
+// AssetLocation identifies where a video file lives, for asset path calculation
+type AssetLocation struct {
+	LibraryID   int64
+	LibraryPath string
+	FilePath    string
+}
+
+// RelocatedAssets holds the database paths of a video's assets after relocation
+type RelocatedAssets struct {
+	ThumbnailPath string
+	PreviewPath   string
+}
+
+// RelocateVideoAssets moves a video's thumbnail and preview storyboard so they match
+// the video's new location in the hierarchical asset tree. Assets that don't exist
+// on disk come back as empty paths so they get regenerated later.
+func (s *MediaService) RelocateVideoAssets(to AssetLocation, thumbnailPath, previewPath string) RelocatedAssets {
+	result := RelocatedAssets{}
+
+	if thumbnailPath != "" {
+		oldFull := filepath.Join(videoThumbnailDir(), filepath.FromSlash(strings.TrimPrefix(thumbnailPath, "thumbnails/")))
+		target := s.GetThumbnailPath(ThumbnailConfig{
+			LibraryID:     to.LibraryID,
+			LibraryPath:   to.LibraryPath,
+			VideoFilePath: to.FilePath,
+			ThumbnailDir:  videoThumbnailDir(),
+		})
+		if target != nil && moveAsset(oldFull, target.FullPath) {
+			result.ThumbnailPath = target.RelativePath
+		}
+	}
+
+	if previewPath != "" {
+		oldFull := filepath.Join(videoPreviewDir(), filepath.FromSlash(previewPath))
+		target := s.GetPreviewPath(PreviewConfig{
+			LibraryID:     to.LibraryID,
+			LibraryPath:   to.LibraryPath,
+			VideoFilePath: to.FilePath,
+			PreviewDir:    videoPreviewDir(),
+		})
+		if target != nil && moveAsset(oldFull, target.FullPath) {
+			result.PreviewPath = target.RelativePath
+		}
+	}
+
+	return result
+}
+
+// RemoveVideoAssets deletes a video's thumbnail and preview storyboard from disk
+func (s *MediaService) RemoveVideoAssets(thumbnailPath, previewPath string) {
+	if thumbnailPath != "" {
+		full := filepath.Join(videoThumbnailDir(), filepath.FromSlash(strings.TrimPrefix(thumbnailPath, "thumbnails/")))
+		if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove thumbnail %s: %v", full, err)
+		}
+	}
+	if previewPath != "" {
+		full := filepath.Join(videoPreviewDir(), filepath.FromSlash(previewPath))
+		if err := os.RemoveAll(full); err != nil {
+			log.Printf("Failed to remove preview %s: %v", full, err)
+		}
+	}
+}
+
+// moveAsset renames an asset file or directory, creating the destination parent.
+// Returns true if the asset exists at the destination afterwards.
+func moveAsset(oldPath, newPath string) bool {
+	if _, err := os.Stat(oldPath); err != nil {
+		return false
+	}
+	if filepath.Clean(oldPath) == filepath.Clean(newPath) {
+		return true
+	}
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		log.Printf("Failed to create asset directory for %s: %v", newPath, err)
+		return false
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		log.Printf("Failed to move asset %s to %s: %v", oldPath, newPath, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,605 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// NFOService reads and writes Kodi/Jellyfin-style NFO sidecar files so metadata
+// can travel between this library and a media center
+type NFOService struct {
+	db               *sql.DB
+	videoService     *VideoService
+	studioService    *StudioService
+	performerService *PerformerService
+	tagService       *TagService
+}
+
+// NewNFOService creates a new NFO service
+func NewNFOService() *NFOService {
+	performerService := NewPerformerService()
+	return &NFOService{
+		db:               database.GetDB(),
+		videoService:     NewVideoService(NewActivityService(), NewLibraryService(), performerService),
+		studioService:    NewStudioService(),
+		performerService: performerService,
+		tagService:       NewTagService(),
+	}
+}
+
+// nfoRaw keeps elements we don't understand so merging an existing file doesn't drop them
+type nfoRaw struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+// nfoActor is an <actor> entry
+type nfoActor struct {
+	Name  string `xml:"name"`
+	Role  string `xml:"role,omitempty"`
+	Thumb string `xml:"thumb,omitempty"`
+}
+
+// nfoRatings is the newer <ratings> block
+type nfoRatings struct {
+	Entries []nfoRating `xml:"rating"`
+}
+
+// nfoRating is an entry of a <ratings> block
+type nfoRating struct {
+	Name  string  `xml:"name,attr,omitempty"`
+	Max   float64 `xml:"max,attr,omitempty"`
+	Value float64 `xml:"value"`
+}
+
+// nfoVideo is a <movie> or <episodedetails> document
+type nfoVideo struct {
+	XMLName    xml.Name
+	Title      string      `xml:"title,omitempty"`
+	Plot       string      `xml:"plot,omitempty"`
+	Outline    string      `xml:"outline,omitempty"`
+	Premiered  string      `xml:"premiered,omitempty"`
+	Aired      string      `xml:"aired,omitempty"`
+	Year       string      `xml:"year,omitempty"`
+	Rating     string      `xml:"rating,omitempty"`
+	Ratings    *nfoRatings `xml:"ratings,omitempty"`
+	UserRating string      `xml:"userrating,omitempty"`
+	Studios    []string    `xml:"studio,omitempty"`
+	Tags       []string    `xml:"tag,omitempty"`
+	Genres     []string    `xml:"genre,omitempty"`
+	Actors     []nfoActor  `xml:"actor,omitempty"`
+	Extra      []nfoRaw    `xml:",any"`
+}
+
+// nfoPerson is a performer.nfo document
+type nfoPerson struct {
+	XMLName    xml.Name
+	Name       string   `xml:"name,omitempty"`
+	Biography  string   `xml:"biography,omitempty"`
+	Birthdate  string   `xml:"birthdate,omitempty"`
+	Birthplace string   `xml:"birthplace,omitempty"`
+	Height     string   `xml:"height,omitempty"`
+	Weight     string   `xml:"weight,omitempty"`
+	Aliases    []string `xml:"alias,omitempty"`
+	URLs       []string `xml:"url,omitempty"`
+	Extra      []nfoRaw `xml:",any"`
+}
+
+// performerNFOName is the file written into a performer's folder
+const performerNFOName = "performer.nfo"
+
+// videoNFOPath is the sidecar path for a video: the same name with an .nfo extension
+func videoNFOPath(filePath string) string {
+	return strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".nfo"
+}
+
+// findVideoNFO returns the NFO that describes a video, or "" when there is none. A
+// folder-wide movie.nfo only counts when the video is the only one in its folder;
+// otherwise every video beside it would take the same title and actors.
+func findVideoNFO(filePath string) string {
+	if info, err := os.Stat(videoNFOPath(filePath)); err == nil && !info.IsDir() {
+		return videoNFOPath(filePath)
+	}
+
+	dir := filepath.Dir(filePath)
+	movieNFO := filepath.Join(dir, "movie.nfo")
+	if info, err := os.Stat(movieNFO); err != nil || info.IsDir() {
+		return ""
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !IsSupportedVideoFile(entry.Name()) {
+			continue
+		}
+		if entry.Name() != filepath.Base(filePath) {
+			return ""
+		}
+	}
+	return movieNFO
+}
+
+// readNFO decodes an NFO file into doc
+func readNFO(path string, doc interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeNFO encodes doc with an XML declaration
+func writeNFO(path string, doc interface{}) error {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	data = append([]byte(xml.Header), data...)
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// nfoDate picks the release date, settling for January 1st when only a year is known
+func (n *nfoVideo) nfoDate() string {
+	for _, value := range []string{n.Premiered, n.Aired} {
+		if value = strings.TrimSpace(value); value != "" {
+			if date := normalizeParsedDate(value, "", "", ""); date != "" {
+				return date
+			}
+		}
+	}
+	return normalizeParsedDate("", strings.TrimSpace(n.Year), "", "")
+}
+
+// nfoRating maps the NFO's 0-10 score onto our 0-5 stars, preferring the user rating
+func (n *nfoVideo) nfoRating() int {
+	score := -1.0
+	for _, value := range []string{n.UserRating, n.Rating} {
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && parsed > 0 {
+			score = parsed
+			break
+		}
+	}
+	if score < 0 && n.Ratings != nil && len(n.Ratings.Entries) > 0 {
+		score = n.Ratings.Entries[0].Value
+		if max := n.Ratings.Entries[0].Max; max > 0 && max != 10 {
+			score = score * 10 / max
+		}
+	}
+	if score <= 0 {
+		return 0
+	}
+	return int(math.Min(5, math.Round(score/2)))
+}
+
+// ImportVideo reads the NFO next to a video and applies it. With the fill policy only
+// empty fields are set; overwrite replaces them and the studio. Tags and actors are
+// always added, creating missing ones. Returns false when the video has no NFO.
+func (s *NFOService) ImportVideo(videoID int64, policy string) (bool, error) {
+	video, err := s.videoService.GetByID(videoID)
+	if err != nil {
+		return false, err
+	}
+
+	path := findVideoNFO(video.FilePath)
+	if path == "" {
+		return false, nil
+	}
+	var doc nfoVideo
+	if err := readNFO(path, &doc); err != nil {
+		return false, err
+	}
+
+	overwrite := policy == models.NFOPolicyOverwrite
+	pick := func(current, incoming string) string {
+		incoming = strings.TrimSpace(incoming)
+		if incoming != "" && (overwrite || current == "") {
+			return incoming
+		}
+		return current
+	}
+
+	plot := doc.Plot
+	if plot == "" {
+		plot = doc.Outline
+	}
+	rating := video.Rating
+	if incoming := doc.nfoRating(); incoming > 0 && (overwrite || rating == 0) {
+		rating = incoming
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE videos SET title = ?, date = ?, rating = ?, description = ?, updated_at = ?
+		WHERE id = ?
+	`, pick(video.Title, doc.Title), pick(video.Date, doc.nfoDate()), rating,
+		pick(video.Description, plot), time.Now(), videoID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update video: %w", err)
+	}
+
+	if len(doc.Studios) > 0 && strings.TrimSpace(doc.Studios[0]) != "" && (overwrite || len(video.Studios) == 0) {
+		if err := s.linkStudio(videoID, strings.TrimSpace(doc.Studios[0])); err != nil {
+			return false, err
+		}
+	}
+
+	for _, name := range append(doc.Tags, doc.Genres...) {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if err := s.linkTag(videoID, name); err != nil {
+			return false, err
+		}
+	}
+
+	for _, actor := range doc.Actors {
+		name := strings.TrimSpace(actor.Name)
+		if name == "" {
+			continue
+		}
+		performerID, err := s.findOrCreatePerformer(name)
+		if err != nil {
+			return false, err
+		}
+		if err := linkVideoPerformer(s.db, videoID, performerID); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// linkStudio replaces a video's studio, creating the studio if it doesn't exist
+func (s *NFOService) linkStudio(videoID int64, name string) error {
+	var studioID int64
+	err := s.db.QueryRow(`SELECT id FROM studios WHERE name = ? COLLATE NOCASE LIMIT 1`, name).Scan(&studioID)
+	if err == sql.ErrNoRows {
+		studio, err := s.studioService.Create(&models.StudioCreate{Name: name})
+		if err != nil {
+			return fmt.Errorf("failed to create studio: %w", err)
+		}
+		studioID = studio.ID
+	} else if err != nil {
+		return fmt.Errorf("failed to look up studio: %w", err)
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM video_studios WHERE video_id = ?`, videoID); err != nil {
+		return fmt.Errorf("failed to clear studios: %w", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO video_studios (video_id, studio_id) VALUES (?, ?)`, videoID, studioID); err != nil {
+		return fmt.Errorf("failed to add studio: %w", err)
+	}
+	return nil
+}
+
+// linkTag adds a tag and its ancestors to a video unless already present, creating
+// the tag if needed
+func (s *NFOService) linkTag(videoID int64, name string) error {
+	tagID, err := resolveTagID(s.db, name)
+	if err != nil {
+		return err
+	}
+	if tagID == 0 {
+		tag, err := s.tagService.Create(&models.TagCreate{Name: name})
+		if err != nil {
+			return fmt.Errorf("failed to create tag: %w", err)
+		}
+		tagID = tag.ID
+	}
+
+	if _, err := insertVideoTags(s.db, []linkPair{{videoID, tagID}}); err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+	return nil
+}
+
+// findOrCreatePerformer looks a performer up by name or alias, creating one when unknown
+func (s *NFOService) findOrCreatePerformer(name string) (int64, error) {
+	id, err := findPerformerIDByName(s.db, name)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to look up performer: %w", err)
+	}
+	performer, err := s.performerService.Create(&models.PerformerCreate{Name: name})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create performer: %w", err)
+	}
+	return performer.ID, nil
+}
+
+// Import reads NFOs for the requested videos, or every available video in a library
+func (s *NFOService) Import(req *models.NFOImportRequest) (*models.NFOResult, error) {
+	policy := req.Policy
+	if policy == "" {
+		policy = models.NFOPolicyFill
+	}
+	if policy != models.NFOPolicyFill && policy != models.NFOPolicyOverwrite {
+		return nil, fmt.Errorf("invalid import policy: %s", policy)
+	}
+
+	ids := req.VideoIDs
+	if len(ids) == 0 {
+		if req.LibraryID == 0 {
+			return nil, fmt.Errorf("library_id or video_ids is required")
+		}
+		var err error
+		if ids, err = s.libraryVideoIDs(req.LibraryID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &models.NFOResult{}
+	for _, id := range ids {
+		result.Processed++
+		imported, err := s.ImportVideo(id, policy)
+		switch {
+		case err != nil:
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("video %d: %v", id, err))
+		case imported:
+			result.Written++
+		default:
+			result.Skipped++
+		}
+	}
+	return result, nil
+}
+
+// libraryVideoIDs lists the available videos of a library
+func (s *NFOService) libraryVideoIDs(libraryID int64) ([]int64, error) {
+	rows, err := s.db.Query(`SELECT id FROM videos WHERE library_id = ? AND COALESCE(status, 'available') = ? ORDER BY id`,
+		libraryID, models.VideoStatusAvailable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query videos: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan video id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// videoToNFO builds the NFO document for a video
+func videoToNFO(video *models.Video) *nfoVideo {
+	doc := &nfoVideo{
+		XMLName:   xml.Name{Local: "movie"},
+		Title:     video.Title,
+		Plot:      video.Description,
+		Premiered: video.Date,
+	}
+	if len(video.Date) >= 4 {
+		doc.Year = video.Date[:4]
+	}
+	if video.Rating > 0 {
+		doc.Rating = strconv.Itoa(video.Rating * 2)
+		doc.UserRating = doc.Rating
+	}
+	for _, studio := range video.Studios {
+		doc.Studios = append(doc.Studios, studio.Name)
+	}
+	for _, tag := range video.Tags {
+		doc.Tags = append(doc.Tags, tag.Name)
+	}
+	for _, performer := range video.Performers {
+		doc.Actors = append(doc.Actors, nfoActor{Name: performer.Name})
+	}
+	return doc
+}
+
+// mergeNFOVideo keeps everything already in existing and fills its gaps from ours
+func mergeNFOVideo(existing, ours *nfoVideo) *nfoVideo {
+	merged := *existing
+	fill := func(dst *string, value string) {
+		if strings.TrimSpace(*dst) == "" {
+			*dst = value
+		}
+	}
+	fill(&merged.Title, ours.Title)
+	fill(&merged.Plot, ours.Plot)
+	fill(&merged.Premiered, ours.Premiered)
+	fill(&merged.Year, ours.Year)
+	fill(&merged.Rating, ours.Rating)
+	fill(&merged.UserRating, ours.UserRating)
+	if len(merged.Studios) == 0 {
+		merged.Studios = ours.Studios
+	}
+	// Our tags include imported genres, which the file already lists as genres
+	tags := unionStrings(append(append([]string{}, merged.Tags...), merged.Genres...), ours.Tags)
+	merged.Tags = append(merged.Tags, tags[len(merged.Tags)+len(merged.Genres):]...)
+
+	seen := make(map[string]bool)
+	for _, actor := range merged.Actors {
+		seen[strings.ToLower(actor.Name)] = true
+	}
+	for _, actor := range ours.Actors {
+		if !seen[strings.ToLower(actor.Name)] {
+			merged.Actors = append(merged.Actors, actor)
+		}
+	}
+	return &merged
+}
+
+// unionStrings appends the values of b missing from a, ignoring case
+func unionStrings(a, b []string) []string {
+	seen := make(map[string]bool)
+	for _, value := range a {
+		seen[strings.ToLower(value)] = true
+	}
+	for _, value := range b {
+		if !seen[strings.ToLower(value)] {
+			seen[strings.ToLower(value)] = true
+			a = append(a, value)
+		}
+	}
+	return a
+}
+
+// performerToNFO builds the performer.nfo document for a performer
+func performerToNFO(performer *models.Performer) *nfoPerson {
+	doc := &nfoPerson{XMLName: xml.Name{Local: "person"}, Name: performer.Name}
+	if meta := performer.MetadataObj; meta != nil {
+		doc.Biography = meta.Bio
+		doc.Birthdate = meta.Birthdate
+		doc.Birthplace = meta.Birthplace
+		doc.Height = meta.Height
+		doc.Weight = meta.Weight
+		doc.Aliases = meta.Aliases
+		doc.URLs = meta.URLs
+	}
+	return doc
+}
+
+// mergeNFOPerson keeps everything already in existing and fills its gaps from ours
+func mergeNFOPerson(existing, ours *nfoPerson) *nfoPerson {
+	merged := *existing
+	for _, field := range []struct {
+		dst   *string
+		value string
+	}{
+		{&merged.Name, ours.Name},
+		{&merged.Biography, ours.Biography},
+		{&merged.Birthdate, ours.Birthdate},
+		{&merged.Birthplace, ours.Birthplace},
+		{&merged.Height, ours.Height},
+		{&merged.Weight, ours.Weight},
+	} {
+		if strings.TrimSpace(*field.dst) == "" {
+			*field.dst = field.value
+		}
+	}
+	merged.Aliases = unionStrings(merged.Aliases, ours.Aliases)
+	merged.URLs = unionStrings(merged.URLs, ours.URLs)
+	return &merged
+}
+
+// Export writes NFO files for the requested videos and performers. With the skip
+// policy existing files are left alone, overwrite replaces them, and merge keeps
+// their values while filling in anything missing.
+func (s *NFOService) Export(req *models.NFOExportRequest) (*models.NFOResult, error) {
+	policy := req.Policy
+	if policy == "" {
+		policy = models.NFOPolicySkip
+	}
+	if policy != models.NFOPolicySkip && policy != models.NFOPolicyOverwrite && policy != models.NFOPolicyMerge {
+		return nil, fmt.Errorf("invalid export policy: %s", policy)
+	}
+	if len(req.VideoIDs) == 0 && len(req.PerformerIDs) == 0 {
+		return nil, fmt.Errorf("video_ids or performer_ids is required")
+	}
+
+	result := &models.NFOResult{}
+	record := func(label, path string, written bool, err error) {
+		result.Processed++
+		switch {
+		case err != nil:
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", label, err))
+		case written:
+			result.Written++
+			result.Files = append(result.Files, path)
+		default:
+			result.Skipped++
+		}
+	}
+
+	performerIDs := append([]int64{}, req.PerformerIDs...)
+	for _, id := range req.VideoIDs {
+		video, err := s.videoService.GetByID(id)
+		if err != nil {
+			record(fmt.Sprintf("video %d", id), "", false, err)
+			continue
+		}
+		path := videoNFOPath(video.FilePath)
+		written, err := exportNFO(path, policy, videoToNFO(video), mergeNFOVideo)
+		record(fmt.Sprintf("video %d", id), path, written, err)
+
+		if req.IncludePerformers {
+			for _, performer := range video.Performers {
+				performerIDs = append(performerIDs, performer.ID)
+			}
+		}
+	}
+
+	exported := make(map[int64]bool)
+	for _, id := range performerIDs {
+		if exported[id] {
+			continue
+		}
+		exported[id] = true
+
+		performer, err := s.performerService.GetByID(id)
+		if err != nil {
+			record(fmt.Sprintf("performer %d", id), "", false, err)
+			continue
+		}
+		if !performer.FolderPath.Valid || performer.FolderPath.String == "" {
+			record(fmt.Sprintf("performer %d", id), "", false, nil)
+			continue
+		}
+		if info, err := os.Stat(performer.FolderPath.String); err != nil || !info.IsDir() {
+			record(fmt.Sprintf("performer %d", id), "", false, fmt.Errorf("folder %s is not accessible", performer.FolderPath.String))
+			continue
+		}
+		path := filepath.Join(performer.FolderPath.String, performerNFOName)
+		written, err := exportNFO(path, policy, performerToNFO(performer), mergeNFOPerson)
+		record(fmt.Sprintf("performer %d", id), path, written, err)
+	}
+
+	return result, nil
+}
+
+// exportNFO writes doc to path according to policy, returning whether the file was written
+func exportNFO[T any](path, policy string, doc *T, merge func(existing, ours *T) *T) (bool, error) {
+	_, err := os.Stat(path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	if exists {
+		switch policy {
+		case models.NFOPolicySkip:
+			return false, nil
+		case models.NFOPolicyMerge:
+			existing := new(T)
+			if err := readNFO(path, existing); err != nil {
+				return false, err
+			}
+			doc = merge(existing, doc)
+		}
+	}
+
+	if err := writeNFO(path, doc); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// ErrOperationConflict is returned when an operation can't be undone because
+// something has changed the same data since
+var ErrOperationConflict = errors.New("operation conflicts with a later change")
+
+// maxJournaledOperations is how many operations the journal keeps
+const maxJournaledOperations = 1000
+
+// journaledTables are the tables operations may add rows to or delete rows
+// from, with the columns that identify a row
+var journaledTables = map[string][]string{
+	"videos":           {"id"},
+	"tags":             {"id"},
+	"video_tags":       {"video_id", "tag_id"},
+	"performer_tags":   {"performer_id", "tag_id"},
+	"video_performers": {"video_id", "performer_id"},
+	"video_studios":    {"video_id", "studio_id"},
+	"video_groups":     {"video_id", "group_id"},
+	"tag_parents":      {"tag_id", "parent_id"},
+	"tag_aliases":      {"id"},
+}
+
+// OperationService lists journaled bulk operations and undoes them
+type OperationService struct {
+	db             *sql.DB
+	libraryService *LibraryService
+	fileService    *FileService
+}
+
+// NewOperationService creates a new operation service
+func NewOperationService() *OperationService {
+	return &OperationService{
+		db:             database.GetDB(),
+		libraryService: NewLibraryService(),
+		fileService:    NewFileService(),
+	}
+}
+
+// journalOperation records a finished operation, on the database or in the
+// transaction that made its changes, and returns its ID. An operation that
+// changed nothing isn't recorded.
+func journalOperation(q interface {
+	Exec(string, ...interface{}) (sql.Result, error)
+}, opType, summary string, changes []models.OperationChange) (int64, error) {
+	if len(changes) == 0 {
+		return 0, nil
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode operation changes: %w", err)
+	}
+	result, err := q.Exec(`INSERT INTO operations (type, summary, change_count, changes, created_at) VALUES (?, ?, ?, ?, ?)`,
+		opType, summary, len(changes), string(data), time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to journal operation: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	// Only the most recent operations are kept
+	if _, err := q.Exec(`DELETE FROM operations WHERE id <= (SELECT id FROM operations ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		maxJournaledOperations); err != nil {
+		return 0, fmt.Errorf("failed to prune operation journal: %w", err)
+	}
+	return id, nil
+}
+
+// linkChange describes a row added to or deleted from a link table
+func linkChange(kind, table string, firstID, secondID int64) models.OperationChange {
+	columns := journaledTables[table]
+	return models.OperationChange{
+		Kind:  kind,
+		Table: table,
+		Row:   map[string]interface{}{columns[0]: firstID, columns[1]: secondID},
+	}
+}
+
+// operationColumns lists the columns scanOperation expects, in order
+const operationColumns = `id, type, summary, change_count, created_at, undone_at`
+
+// scanOperation scans an operation row without its changes
+func scanOperation(scanner activityScanner) (*models.Operation, error) {
+	var op models.Operation
+	var undoneAt sql.NullTime
+	if err := scanner.Scan(&op.ID, &op.Type, &op.Summary, &op.ChangeCount, &op.CreatedAt, &undoneAt); err != nil {
+		return nil, err
+	}
+	if undoneAt.Valid {
+		op.UndoneAt = &undoneAt.Time
+	}
+	return &op, nil
+}
+
+// GetAll returns the most recent operations, newest first
+func (s *OperationService) GetAll(limit int) ([]models.Operation, error) {
+	if limit <= 0 || limit > maxJournaledOperations {
+		limit = 50
+	}
+	rows, err := s.db.Query(`SELECT `+operationColumns+` FROM operations ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query operations: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	operations := []models.Operation{}
+	for rows.Next() {
+		op, err := scanOperation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan operation: %w", err)
+		}
+		operations = append(operations, *op)
+	}
+	return operations, rows.Err()
+}
+
+// GetByID returns an operation with its changes
+func (s *OperationService) GetByID(id int64) (*models.Operation, error) {
+	op, err := scanOperation(s.db.QueryRow(`SELECT `+operationColumns+` FROM operations WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("operation not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operation: %w", err)
+	}
+
+	var data string
+	if err := s.db.QueryRow(`SELECT changes FROM operations WHERE id = ?`, id).Scan(&data); err != nil {
+		return nil, fmt.Errorf("failed to get operation changes: %w", err)
+	}
+	if op.Changes, err = decodeChanges(data); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// decodeChanges decodes journaled changes, keeping row IDs as numbers
+func decodeChanges(data string) ([]models.OperationChange, error) {
+	var changes []models.OperationChange
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&changes); err != nil {
+		return nil, fmt.Errorf("failed to decode operation changes: %w", err)
+	}
+	for i := range changes {
+		for column, value := range changes[i].Row {
+			changes[i].Row[column] = snapshotValue(value)
+		}
+	}
+	return changes, nil
+}
+
+// changeKeys names what a change touched: a table row by its identifying
+// columns, or the paths of a moved file
+func changeKeys(change models.OperationChange) []string {
+	if change.Kind == models.ChangeFileMoved {
+		return []string{"path:" + filepath.Clean(change.From), "path:" + filepath.Clean(change.To)}
+	}
+	key := change.Table
+	for _, column := range journaledTables[change.Table] {
+		key += fmt.Sprintf(":%s=%v", column, change.Row[column])
+	}
+	return []string{key}
+}
+
+// keysOverlap reports whether two change keys touch the same data. A path
+// overlaps itself and everything inside it.
+func keysOverlap(a, b string) bool {
+	if !strings.HasPrefix(a, "path:") || !strings.HasPrefix(b, "path:") {
+		return a == b
+	}
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	separator := string(filepath.Separator)
+	return a == b || strings.HasPrefix(a, b+separator) || strings.HasPrefix(b, a+separator)
+}
+
+// checkLaterOperations returns an ErrOperationConflict error when an operation
+// made after op, and not undone, touched any of op's data
+func (s *OperationService) checkLaterOperations(op *models.Operation) error {
+	var keys []string
+	for _, change := range op.Changes {
+		keys = append(keys, changeKeys(change)...)
+	}
+
+	rows, err := s.db.Query(`SELECT id, summary, changes FROM operations WHERE id > ? AND undone_at IS NULL ORDER BY id`, op.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query later operations: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var laterID int64
+		var summary, data string
+		if err := rows.Scan(&laterID, &summary, &data); err != nil {
+			return fmt.Errorf("failed to scan operation: %w", err)
+		}
+		changes, err := decodeChanges(data)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			for _, laterKey := range changeKeys(change) {
+				for _, key := range keys {
+					if keysOverlap(key, laterKey) {
+						return fmt.Errorf("%w: undo operation %d (%s) first", ErrOperationConflict, laterID, summary)
+					}
+				}
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// Undo reverses an operation's changes, last change first. Row changes are
+// undone in one transaction; moved files go back through FileService so their
+// videos and assets follow them.
+func (s *OperationService) Undo(id int64) (*models.Operation, error) {
+	op, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if op.UndoneAt != nil {
+		return nil, fmt.Errorf("operation has already been undone")
+	}
+	if err := s.checkLaterOperations(op); err != nil {
+		return nil, err
+	}
+
+	for i := len(op.Changes) - 1; i >= 0; i-- {
+		if change := op.Changes[i]; change.Kind == models.ChangeFileMoved {
+			if err := s.undoFileMove(change); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back operation undo: %v", err)
+		}
+	}()
+
+	performers := make(map[interface{}]bool)
+	hierarchyChanged := false
+	for i := len(op.Changes) - 1; i >= 0; i-- {
+		change := op.Changes[i]
+		switch change.Kind {
+		case models.ChangeRowAdded:
+			err = undoRowAdded(tx, change)
+		case models.ChangeRowDeleted:
+			err = undoRowDeleted(tx, change)
+		case models.ChangeRowUpdated:
+			err = undoRowUpdated(tx, change)
+		case models.ChangeFileMoved:
+			continue
+		default:
+			err = fmt.Errorf("unknown change kind %q", change.Kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if change.Table == "video_performers" {
+			performers[change.Row["performer_id"]] = true
+		}
+		if change.Table == "tag_parents" {
+			hierarchyChanged = true
+		}
+	}
+
+	// Linked performers keep a count of their videos
+	for performerID := range performers {
+		if _, err := tx.Exec(`UPDATE performers SET video_count = (SELECT COUNT(*) FROM video_performers WHERE performer_id = ?) WHERE id = ?`,
+			performerID, performerID); err != nil {
+			return nil, fmt.Errorf("failed to update performer video count: %w", err)
+		}
+	}
+
+	if hierarchyChanged {
+		if err := rebuildTagAncestors(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`UPDATE operations SET undone_at = ? WHERE id = ?`, now, id); err != nil {
+		return nil, fmt.Errorf("failed to mark operation undone: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit operation undo: %w", err)
+	}
+
+	op.UndoneAt = &now
+	return op, nil
+}
+
+// rowCondition builds the WHERE clause matching a journaled row
+func rowCondition(change models.OperationChange) (string, []interface{}, error) {
+	columns, ok := journaledTables[change.Table]
+	if !ok {
+		return "", nil, fmt.Errorf("table %q is not journaled", change.Table)
+	}
+	conditions := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		conditions[i] = column + " = ?"
+		args[i] = change.Row[column]
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// undoRowAdded deletes a row an operation added. A row that's already gone
+// was removed by someone else and is left that way.
+func undoRowAdded(tx *sql.Tx, change models.OperationChange) error {
+	condition, args, err := rowCondition(change)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %s`, change.Table, condition), args...); err != nil {
+		return fmt.Errorf("failed to remove %s row: %w", change.Table, err)
+	}
+	return nil
+}
+
+// undoRowDeleted re-inserts a row an operation deleted. A record that can't
+// come back, such as a tag whose name has been taken since, is a conflict; a
+// link to a video or performer that has since been deleted is skipped.
+func undoRowDeleted(tx *sql.Tx, change models.OperationChange) error {
+	condition, conditionArgs, err := rowCondition(change)
+	if err != nil {
+		return err
+	}
+	var exists bool
+	if err := tx.QueryRow(fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE %s)`, change.Table, condition), conditionArgs...).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to query %s: %w", change.Table, err)
+	}
+	if exists {
+		if _, isRecord := change.Row["id"]; isRecord {
+			return fmt.Errorf("%w: %s row %v exists again", ErrOperationConflict, change.Table, change.Row["id"])
+		}
+		return nil
+	}
+
+	existing, err := tableColumns(tx, change.Table)
+	if err != nil {
+		return err
+	}
+	var columns []string
+	var args []interface{}
+	for column, value := range change.Row {
+		if existing[column] {
+			columns = append(columns, column)
+			args = append(args, value)
+		}
+	}
+	_, err = tx.Exec(fmt.Sprintf(`INSERT INTO %s (%s) VALUES (?%s)`,
+		change.Table, strings.Join(columns, ", "), strings.Repeat(", ?", len(columns)-1)), args...)
+	if err == nil {
+		return nil
+	}
+	if _, isRecord := change.Row["id"]; isRecord {
+		return fmt.Errorf("%w: failed to restore %s row %v: %v", ErrOperationConflict, change.Table, change.Row["id"], err)
+	}
+	log.Printf("Skipped restoring %s row %v: %v", change.Table, change.Row, err)
+	return nil
+}
+
+// undoRowUpdated puts back the previous values of an updated row. A row that's
+// gone since is left gone.
+func undoRowUpdated(tx *sql.Tx, change models.OperationChange) error {
+	condition, conditionArgs, err := rowCondition(change)
+	if err != nil {
+		return err
+	}
+	existing, err := tableColumns(tx, change.Table)
+	if err != nil {
+		return err
+	}
+	identity := make(map[string]bool)
+	for _, column := range journaledTables[change.Table] {
+		identity[column] = true
+	}
+
+	var assignments []string
+	var args []interface{}
+	for column, value := range change.Row {
+		if existing[column] && !identity[column] {
+			assignments = append(assignments, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	if len(assignments) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, change.Table, strings.Join(assignments, ", "), condition),
+		append(args, conditionArgs...)...); err != nil {
+		return fmt.Errorf("failed to restore %s row: %w", change.Table, err)
+	}
+	return nil
+}
+
+// undoFileMove moves a file or folder back where an operation found it, as
+// long as it's still where the operation put it
+func (s *OperationService) undoFileMove(change models.OperationChange) error {
+	source, err := s.libraryService.GetByID(change.LibraryID)
+	if err != nil {
+		return fmt.Errorf("library not found: %w", err)
+	}
+	target := source
+	if change.TargetLibraryID != 0 && change.TargetLibraryID != change.LibraryID {
+		if target, err = s.libraryService.GetByID(change.TargetLibraryID); err != nil {
+			return fmt.Errorf("target library not found: %w", err)
+		}
+	}
+	for _, library := range []*models.Library{source, target} {
+		if err := s.libraryService.EnsureOnline(library); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(change.To); err != nil {
+		return fmt.Errorf("%w: %s is no longer there", ErrOperationConflict, change.To)
+	}
+	if _, err := os.Stat(change.From); err == nil {
+		return fmt.Errorf("%w: %s exists again", ErrOperationConflict, change.From)
+	}
+	return s.fileService.relocate(target, source, change.To, change.From)
+}
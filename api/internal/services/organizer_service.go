@@ -0,0 +1,511 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// maxOrganizeVideos caps how many videos one plan covers, including search results
+const maxOrganizeVideos = 5000
+
+// maxPathSegment keeps each rendered folder or file name under common filesystem limits
+const maxPathSegment = 200
+
+// organizeFieldPattern matches {field} and {field|fallback} in a path template
+var organizeFieldPattern = regexp.MustCompile(`\{(\w+)(?:\|([^{}]*))?\}`)
+
+// unsafePathChars can't appear in a file name on at least one supported platform
+var unsafePathChars = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]+`)
+
+// organizeFields pull a template value from a video
+var organizeFields = map[string]func(*models.Video) string{
+	"title": func(v *models.Video) string { return v.Title },
+	"studio": func(v *models.Video) string {
+		if len(v.Studios) > 0 {
+			return v.Studios[0].Name
+		}
+		return ""
+	},
+	"group": func(v *models.Video) string {
+		if len(v.Groups) > 0 {
+			return v.Groups[0].Name
+		}
+		return ""
+	},
+	"performers": func(v *models.Video) string {
+		names := make([]string, len(v.Performers))
+		for i, p := range v.Performers {
+			names[i] = p.Name
+		}
+		return strings.Join(names, ", ")
+	},
+	"year": func(v *models.Video) string {
+		if len(v.Date) >= 4 {
+			return v.Date[:4]
+		}
+		return ""
+	},
+	"date": func(v *models.Video) string { return v.Date },
+	"resolution": func(v *models.Video) string {
+		if height := resolutionHeight(v.Resolution); height > 0 {
+			return fmt.Sprintf("%.0fp", height)
+		}
+		return ""
+	},
+	"filename": func(v *models.Video) string {
+		base := filepath.Base(v.FilePath)
+		return strings.TrimSuffix(base, filepath.Ext(base))
+	},
+	"ext": func(v *models.Video) string { return strings.TrimPrefix(filepath.Ext(v.FilePath), ".") },
+}
+
+// OrganizerService moves videos into a folder layout built from their metadata
+type OrganizerService struct {
+	db             *sql.DB
+	fileService    *FileService
+	libraryService *LibraryService
+	videoService   *VideoService
+}
+
+// NewOrganizerService creates a new organizer service
+func NewOrganizerService() *OrganizerService {
+	return &OrganizerService{
+		db:             database.GetDB(),
+		fileService:    NewFileService(),
+		libraryService: NewLibraryService(),
+		videoService:   NewVideoService(NewActivityService(), NewLibraryService(), NewPerformerService()),
+	}
+}
+
+// validateOrganizeTemplate rejects templates with unknown fields or that could
+// leave the library
+func validateOrganizeTemplate(template string) error {
+	template = strings.TrimSpace(template)
+	if template == "" {
+		return fmt.Errorf("template is required")
+	}
+	if strings.HasPrefix(template, "/") || strings.HasPrefix(template, "\\") || filepath.IsAbs(template) {
+		return fmt.Errorf("template must be relative to the library")
+	}
+	for _, match := range organizeFieldPattern.FindAllStringSubmatch(template, -1) {
+		if _, ok := organizeFields[strings.ToLower(match[1])]; !ok {
+			return fmt.Errorf("unknown template field {%s}", match[1])
+		}
+	}
+	for _, segment := range strings.FieldsFunc(template, isPathSeparator) {
+		if segment == ".." || segment == "." {
+			return fmt.Errorf("template can't contain %q segments", segment)
+		}
+	}
+	return nil
+}
+
+// isPathSeparator accepts both separators so templates work on every platform
+func isPathSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+// renderOrganizePath fills in a template for a video, returning a path relative
+// to the library. Each value is cleaned so it stays within its own segment, and
+// the video's extension is added when the template doesn't produce it.
+func renderOrganizePath(template string, video *models.Video) (string, error) {
+	var segments []string
+	for _, segment := range strings.FieldsFunc(strings.TrimSpace(template), isPathSeparator) {
+		rendered := organizeFieldPattern.ReplaceAllStringFunc(segment, func(field string) string {
+			match := organizeFieldPattern.FindStringSubmatch(field)
+			value := strings.TrimSpace(organizeFields[strings.ToLower(match[1])](video))
+			if value == "" {
+				value = "Unknown"
+				if strings.Contains(field, "|") {
+					value = match[2]
+				}
+			}
+			return unsafePathChars.ReplaceAllString(value, "_")
+		})
+		if rendered = cleanPathSegment(rendered); rendered != "" {
+			segments = append(segments, rendered)
+		}
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("template renders to an empty path")
+	}
+
+	ext := filepath.Ext(video.FilePath)
+	name := segments[len(segments)-1]
+	if ext != "" && !strings.EqualFold(filepath.Ext(name), ext) {
+		name = cleanPathSegment(name) + ext
+	}
+	segments[len(segments)-1] = name
+	return filepath.Join(segments...), nil
+}
+
+// cleanPathSegment collapses whitespace, trims the spaces and dots Windows
+// rejects at the end of a name, and shortens long names
+func cleanPathSegment(segment string) string {
+	segment = strings.Join(strings.Fields(unsafePathChars.ReplaceAllString(segment, "_")), " ")
+	for len(segment) > maxPathSegment {
+		_, size := utf8.DecodeLastRuneInString(segment)
+		segment = segment[:len(segment)-size]
+	}
+	return strings.Trim(segment, " .")
+}
+
+// selectVideos resolves a request's listed IDs and search into videos
+func (s *OrganizerService) selectVideos(req *models.OrganizeRequest) ([]models.Video, error) {
+	ids := append([]int64{}, req.VideoIDs...)
+	if req.Query != nil && len(ids) < maxOrganizeVideos {
+		matches, err := s.videoService.SearchIDs(req.Query, maxOrganizeVideos-len(ids))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, matches...)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("video_ids or query is required")
+	}
+	if len(ids) > maxOrganizeVideos {
+		return nil, fmt.Errorf("at most %d videos can be organized at once", maxOrganizeVideos)
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	unique := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return s.videoService.getVideosByIDs(unique)
+}
+
+// Plan works out where each selected video would move without touching any
+// files. Moves whose destination already exists, or that share a destination
+// with another video, are conflicts and won't be executed.
+func (s *OrganizerService) Plan(req *models.OrganizeRequest) (*models.OrganizePlan, error) {
+	if err := validateOrganizeTemplate(req.Template); err != nil {
+		return nil, err
+	}
+	videos, err := s.selectVideos(req)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.OrganizePlan{Template: strings.TrimSpace(req.Template), Moves: []models.OrganizeMoveItem{}}
+	libraries := make(map[int64]*models.Library)
+	targets := make(map[string]int)
+	for i := range videos {
+		video := &videos[i]
+		move := models.OrganizeMoveItem{VideoID: video.ID, LibraryID: video.LibraryID, Title: video.Title, From: video.FilePath, To: video.FilePath}
+
+		library, ok := libraries[video.LibraryID]
+		if !ok {
+			if library, err = s.libraryService.GetByID(video.LibraryID); err != nil {
+				library = nil
+			}
+			libraries[video.LibraryID] = library
+		}
+
+		switch {
+		case library == nil:
+			move.Status, move.Reason = models.OrganizeSkipped, "library not found"
+		case video.Unavailable:
+			move.Status, move.Reason = models.OrganizeSkipped, "library is offline"
+		case video.Status == models.VideoStatusMissing:
+			move.Status, move.Reason = models.OrganizeSkipped, "file is missing"
+		default:
+			rel, err := renderOrganizePath(plan.Template, video)
+			if err != nil {
+				move.Status, move.Reason = models.OrganizeSkipped, err.Error()
+				break
+			}
+			move.To = filepath.Join(library.Path, rel)
+			move.Status = models.OrganizeMove
+			if filepath.Clean(move.To) == filepath.Clean(move.From) {
+				move.Status = models.OrganizeUnchanged
+				break
+			}
+			targets[strings.ToLower(move.To)]++
+		}
+		plan.Moves = append(plan.Moves, move)
+	}
+
+	// Decide conflicts once every destination is known, so no video wins a
+	// shared destination just by coming first
+	for i := range plan.Moves {
+		move := &plan.Moves[i]
+		if move.Status == models.OrganizeMove {
+			if targets[strings.ToLower(move.To)] > 1 {
+				move.Status, move.Reason = models.OrganizeConflict, "another video in the plan moves to the same path"
+			} else if info, err := os.Stat(move.To); err == nil {
+				// A case-only rename on a case-insensitive filesystem finds the file itself
+				if from, err := os.Stat(move.From); err != nil || !os.SameFile(info, from) {
+					move.Status, move.Reason = models.OrganizeConflict, "a file already exists at the destination"
+				}
+			}
+		}
+
+		switch move.Status {
+		case models.OrganizeMove:
+			plan.ToMove++
+		case models.OrganizeUnchanged:
+			plan.Unchanged++
+		case models.OrganizeConflict:
+			plan.Conflicts++
+		default:
+			plan.Skipped++
+		}
+	}
+	return plan, nil
+}
+
+// Execute plans the request again and carries out its moves, journaling each so
+// the run can be reverted. Conflicting and skipped videos are left where they are.
+func (s *OrganizerService) Execute(req *models.OrganizeRequest) (*models.OrganizeRun, error) {
+	plan, err := s.Plan(req)
+	if err != nil {
+		return nil, err
+	}
+	if plan.ToMove == 0 {
+		return nil, fmt.Errorf("no videos need moving")
+	}
+
+	result, err := s.db.Exec(`INSERT INTO organize_runs (template, created_at) VALUES (?, ?)`, plan.Template, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organize run: %w", err)
+	}
+	runID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	var moved, failed int
+	for _, move := range plan.Moves {
+		if move.Status != models.OrganizeMove {
+			continue
+		}
+		status, reason := models.OrganizeMoved, ""
+		if err := s.moveVideo(move.LibraryID, move.From, move.To); err != nil {
+			status, reason = models.OrganizeFailed, err.Error()
+			failed++
+			log.Printf("Organizer failed to move video %d: %v", move.VideoID, err)
+		} else {
+			moved++
+		}
+		_, err := s.db.Exec(`
+			INSERT INTO organize_moves (run_id, video_id, library_id, old_path, new_path, status, error)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, runID, move.VideoID, move.LibraryID, move.From, move.To, status, reason)
+		if err != nil {
+			log.Printf("Failed to journal move of video %d: %v", move.VideoID, err)
+		}
+	}
+
+	if _, err := s.db.Exec(`UPDATE organize_runs SET moved = ?, failed = ? WHERE id = ?`, moved, failed, runID); err != nil {
+		return nil, fmt.Errorf("failed to update organize run: %w", err)
+	}
+	log.Printf("Organize run %d moved %d videos (%d failed)", runID, moved, failed)
+	return s.GetRun(runID)
+}
+
+// moveVideo moves a video's file within its library. FileService carries the
+// video's row and assets along; the run's own journal, not the operation
+// journal, records the move.
+func (s *OrganizerService) moveVideo(libraryID int64, from, to string) error {
+	library, err := s.libraryService.GetByID(libraryID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errOrganizeLibraryMissing, err)
+	}
+	// An offline library would otherwise look like a missing source file
+	if err := s.libraryService.EnsureOnline(library); err != nil {
+		return err
+	}
+
+	if err := s.fileService.relocate(library, library, from, to); err != nil {
+		return err
+	}
+	removeEmptyDirs(filepath.Dir(from), library.Path)
+	return nil
+}
+
+// errOrganizeLibraryMissing means a move's library has been deleted
+var errOrganizeLibraryMissing = errors.New("library not found")
+
+// permanentMoveError reports whether a failed move would fail the same way again
+func permanentMoveError(err error) bool {
+	return errors.Is(err, errMoveSourceMissing) || errors.Is(err, errMoveDestinationExists) ||
+		errors.Is(err, errOrganizeLibraryMissing)
+}
+
+// removeEmptyDirs removes dir and then its parents while they're empty, stopping
+// at the library root
+func removeEmptyDirs(dir, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// Revert moves the videos of a run back where they were, newest move first.
+// Videos that have been moved, renamed or replaced since, or whose old path is
+// taken, are left alone and reported as revert_failed. Moves that fail for a
+// passing reason, such as the library being offline, stay pending so reverting
+// again retries them; the run only counts as reverted once none are pending.
+func (s *OrganizerService) Revert(runID int64) (*models.OrganizeRun, error) {
+	run, err := s.GetRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.RevertedAt != nil {
+		return nil, fmt.Errorf("organize run %d was already reverted", runID)
+	}
+
+	var reverted, pending int
+	var moveErr error
+	for i := len(run.Moves) - 1; i >= 0; i-- {
+		move := run.Moves[i]
+		if move.Status == models.OrganizeReverted {
+			reverted++ // By an earlier, partial revert
+			continue
+		}
+		if move.Status != models.OrganizeMoved {
+			continue
+		}
+
+		status, reason := models.OrganizeReverted, ""
+		var current string
+		err := s.db.QueryRow(`SELECT file_path FROM videos WHERE id = ?`, move.VideoID).Scan(&current)
+		switch {
+		case err == sql.ErrNoRows:
+			status, reason = models.OrganizeRevertFailed, "video no longer exists"
+		case err != nil:
+			status, reason = models.OrganizeRevertFailed, err.Error()
+		case filepath.Clean(current) != filepath.Clean(move.To):
+			status, reason = models.OrganizeRevertFailed, "video has moved since"
+		default:
+			if err := s.moveVideo(move.LibraryID, move.To, move.From); err != nil {
+				status, reason = models.OrganizeMoved, err.Error()
+				if permanentMoveError(err) {
+					status = models.OrganizeRevertFailed
+				} else {
+					moveErr = err
+				}
+			}
+		}
+		if status == models.OrganizeReverted {
+			reverted++
+		} else if status == models.OrganizeMoved {
+			pending++
+		}
+
+		if _, err := s.db.Exec(`UPDATE organize_moves SET status = ?, error = ? WHERE id = ?`, status, reason, move.ID); err != nil {
+			log.Printf("Failed to journal revert of video %d: %v", move.VideoID, err)
+		}
+	}
+
+	if pending > 0 {
+		return nil, fmt.Errorf("failed to move %d videos back, revert again to retry: %w", pending, moveErr)
+	}
+	if reverted == 0 {
+		return nil, fmt.Errorf("no videos of organize run %d could be moved back", runID)
+	}
+	if _, err := s.db.Exec(`UPDATE organize_runs SET reverted_at = ? WHERE id = ?`, time.Now(), runID); err != nil {
+		return nil, fmt.Errorf("failed to update organize run: %w", err)
+	}
+	return s.GetRun(runID)
+}
+
+// scanOrganizeRun reads a run row
+func scanOrganizeRun(row interface{ Scan(...interface{}) error }) (*models.OrganizeRun, error) {
+	var run models.OrganizeRun
+	var revertedAt sql.NullTime
+	if err := row.Scan(&run.ID, &run.Template, &run.Moved, &run.Failed, &run.CreatedAt, &revertedAt); err != nil {
+		return nil, err
+	}
+	if revertedAt.Valid {
+		run.RevertedAt = &revertedAt.Time
+	}
+	return &run, nil
+}
+
+// GetRuns lists organize runs, newest first, without their moves
+func (s *OrganizerService) GetRuns(limit int) ([]models.OrganizeRun, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.db.Query(`
+		SELECT id, template, moved, failed, created_at, reverted_at
+		FROM organize_runs
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organize runs: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	runs := []models.OrganizeRun{}
+	for rows.Next() {
+		run, err := scanOrganizeRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organize run: %w", err)
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// GetRun retrieves a run with its journaled moves
+func (s *OrganizerService) GetRun(id int64) (*models.OrganizeRun, error) {
+	run, err := scanOrganizeRun(s.db.QueryRow(`
+		SELECT id, template, moved, failed, created_at, reverted_at FROM organize_runs WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("organize run not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organize run: %w", err)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT m.id, m.video_id, m.library_id, COALESCE(v.title, ''), m.old_path, m.new_path, m.status, COALESCE(m.error, '')
+		FROM organize_moves m
+		LEFT JOIN videos v ON v.id = m.video_id
+		WHERE m.run_id = ?
+		ORDER BY m.id ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organize moves: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	run.Moves = []models.OrganizeMoveItem{}
+	for rows.Next() {
+		var move models.OrganizeMoveItem
+		if err := rows.Scan(&move.ID, &move.VideoID, &move.LibraryID, &move.Title, &move.From, &move.To, &move.Status, &move.Reason); err != nil {
+			return nil, fmt.Errorf("failed to scan organize move: %w", err)
+		}
+		run.Moves = append(run.Moves, move)
+	}
+	return run, rows.Err()
+}
@@ -0,0 +1,494 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+// fuzzyMatchThreshold is the lowest similarity a fuzzy performer search returns
+const fuzzyMatchThreshold = 0.85
+
+// foldedLetters covers letters that don't decompose into a base letter and an accent
+var foldedLetters = map[rune]string{
+	'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th", 'ı': "i",
+}
+
+// normalizePerformerName folds a name for comparison: accents dropped, lower case,
+// and punctuation collapsed into single spaces, so "Zoë-Anne" matches "zoe anne"
+func normalizePerformerName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		r = unicode.ToLower(r)
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case foldedLetters[r] != "":
+			b.WriteString(foldedLetters[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// jaroWinkler scores the similarity of two strings from 0 to 1, favouring a
+// shared prefix; transposed and mistyped letters still score highly
+func jaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	window := len(s1)
+	if len(s2) > window {
+		window = len(s2)
+	}
+	window = window/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		start := i - window
+		if start < 0 {
+			start = 0
+		}
+		for j := start; j < min(len(s2), i+window+1); j++ {
+			if !matched2[j] && s1[i] == s2[j] {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// performerNameScore rates how well a normalized search term matches a
+// normalized name. Substrings rank above typos; a term shorter than the name is
+// also compared with each run of as many words, so "rilye" finds "Riley Reid".
+func performerNameScore(term, name string) float64 {
+	switch {
+	case term == "" || name == "":
+		return 0
+	case term == name:
+		return 1
+	case strings.HasPrefix(name, term):
+		return 0.97
+	case strings.Contains(" "+name, " "+term):
+		return 0.95
+	case strings.Contains(name, term):
+		return 0.9
+	}
+
+	// Typo tolerance needs enough letters to mean anything
+	if len([]rune(term)) < 3 {
+		return 0
+	}
+	score := jaroWinkler(term, name)
+	termWords := len(strings.Fields(term))
+	nameWords := strings.Fields(name)
+	for i := 0; i+termWords <= len(nameWords) && termWords < len(nameWords); i++ {
+		// Partial matches rank a little below full-name ones
+		if partial := jaroWinkler(term, strings.Join(nameWords[i:i+termWords], " ")) * 0.98; partial > score {
+			score = partial
+		}
+	}
+	return score
+}
+
+// performerNameEntry is one name a performer answers to: their own or an alias
+type performerNameEntry struct {
+	performerID int64
+	name        string // Performer's name
+	alias       string // Empty for the performer's own name
+	normalized  string
+}
+
+// loadPerformerNames reads every performer name and alias for fuzzy matching in Go
+func loadPerformerNames(db *sql.DB) ([]performerNameEntry, error) {
+	rows, err := db.Query(`
+		SELECT id, name, '', COALESCE(normalized_name, '') FROM performers
+		UNION ALL
+		SELECT p.id, p.name, a.alias, a.normalized
+		FROM performer_aliases a
+		JOIN performers p ON p.id = a.performer_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query performer names: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var entries []performerNameEntry
+	for rows.Next() {
+		var e performerNameEntry
+		if err := rows.Scan(&e.performerID, &e.name, &e.alias, &e.normalized); err != nil {
+			return nil, fmt.Errorf("failed to scan performer name: %w", err)
+		}
+		if e.normalized == "" {
+			e.normalized = normalizePerformerName(e.name)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// findPerformerIDByName resolves a name to a performer, accepting aliases and
+// ignoring case, accents and punctuation. It returns sql.ErrNoRows when nothing matches.
+func findPerformerIDByName(db *sql.DB, name string) (int64, error) {
+	var id int64
+	err := db.QueryRow(`SELECT id FROM performers WHERE name = ? COLLATE NOCASE LIMIT 1`, name).Scan(&id)
+	if err != sql.ErrNoRows {
+		return id, err
+	}
+
+	normalized := normalizePerformerName(name)
+	if normalized == "" {
+		return 0, sql.ErrNoRows
+	}
+	err = db.QueryRow(`
+		SELECT a.performer_id FROM performer_aliases a
+		JOIN performers p ON p.id = a.performer_id
+		WHERE a.normalized = ?
+		ORDER BY a.id LIMIT 1
+	`, normalized).Scan(&id)
+	if err != sql.ErrNoRows {
+		return id, err
+	}
+
+	err = db.QueryRow(`SELECT id FROM performers WHERE normalized_name = ? ORDER BY id LIMIT 1`, normalized).Scan(&id)
+	return id, err
+}
+
+// SyncNormalizedNames fills in the normalized name of every performer that lacks
+// one. Rebuilding the performers table on start drops the column's values.
+func (s *PerformerService) SyncNormalizedNames() error {
+	rows, err := s.db.Query(`SELECT id, name FROM performers WHERE normalized_name IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to query performer names: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	names := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return fmt.Errorf("failed to scan performer name: %w", err)
+		}
+		names[id] = name
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating performer names: %w", err)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", err)
+		}
+	}()
+	for id, name := range names {
+		if _, err := tx.Exec(`UPDATE performers SET normalized_name = ? WHERE id = ?`, normalizePerformerName(name), id); err != nil {
+			return fmt.Errorf("failed to store normalized name: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit normalized names: %w", err)
+	}
+	return nil
+}
+
+// fuzzySearch ranks performers by how closely their name or an alias matches the
+// search term, tolerating typos, case and accents. It returns the matching IDs in
+// order with the score and the alias that matched, if any.
+func (s *PerformerService) fuzzySearch(searchTerm string) ([]performerSearchHit, error) {
+	term := normalizePerformerName(searchTerm)
+	if term == "" {
+		return nil, nil
+	}
+	entries, err := loadPerformerNames(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	best := make(map[int64]*performerSearchHit)
+	for _, e := range entries {
+		score := performerNameScore(term, e.normalized)
+		if score < fuzzyMatchThreshold {
+			continue
+		}
+		hit := best[e.performerID]
+		// The performer's own name wins ties against an alias
+		if hit == nil || score > hit.score || (score == hit.score && e.alias == "") {
+			best[e.performerID] = &performerSearchHit{id: e.performerID, name: e.name, alias: e.alias, score: score}
+		}
+	}
+
+	hits := make([]performerSearchHit, 0, len(best))
+	for _, hit := range best {
+		hits = append(hits, *hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return strings.ToLower(hits[i].name) < strings.ToLower(hits[j].name)
+	})
+	return hits, nil
+}
+
+// performerSearchHit is one performer found by fuzzySearch
+type performerSearchHit struct {
+	id    int64
+	name  string
+	alias string
+	score float64
+}
+
+// getSearchHits loads the performers for a slice of search hits, in hit order
+func (s *PerformerService) getSearchHits(hits []performerSearchHit) ([]models.Performer, error) {
+	if len(hits) == 0 {
+		return []models.Performer{}, nil
+	}
+	args := make([]interface{}, len(hits))
+	for i, hit := range hits {
+		args[i] = hit.id
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, name, preview_path, thumbnail_path, folder_path, video_count, category, metadata, created_at, updated_at
+		FROM performers
+		WHERE id IN (?`+strings.Repeat(",?", len(hits)-1)+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search performers: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	byID := make(map[int64]models.Performer, len(hits))
+	for rows.Next() {
+		var p models.Performer
+		err := rows.Scan(
+			&p.ID, &p.Name, &p.PreviewPath, &p.ThumbnailPath, &p.FolderPath,
+			&p.VideoCount, &p.Category, &p.Metadata, &p.CreatedAt, &p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan performer: %w", err)
+		}
+		if err := p.UnmarshalMetadata(); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+		byID[p.ID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating performers: %w", err)
+	}
+
+	performers := make([]models.Performer, 0, len(hits))
+	for _, hit := range hits {
+		p, ok := byID[hit.id]
+		if !ok {
+			continue
+		}
+		p.MatchedAlias = hit.alias
+		p.MatchScore = hit.score
+		performers = append(performers, p)
+	}
+	return performers, nil
+}
+
+// GetAliases retrieves a performer's aliases
+func (s *PerformerService) GetAliases(performerID int64) ([]models.PerformerAlias, error) {
+	rows, err := s.db.Query(`
+		SELECT id, performer_id, alias, source, created_at
+		FROM performer_aliases
+		WHERE performer_id = ?
+		ORDER BY alias COLLATE NOCASE ASC
+	`, performerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aliases: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	aliases := []models.PerformerAlias{}
+	for rows.Next() {
+		var a models.PerformerAlias
+		if err := rows.Scan(&a.ID, &a.PerformerID, &a.Alias, &a.Source, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alias: %w", err)
+		}
+		aliases = append(aliases, a)
+	}
+	return aliases, rows.Err()
+}
+
+// AddAlias adds a manual alias to a performer. An alias can't repeat the
+// performer's name or belong to another performer.
+func (s *PerformerService) AddAlias(performerID int64, alias string) (*models.PerformerAlias, error) {
+	performer, err := s.GetByID(performerID)
+	if err != nil {
+		return nil, err
+	}
+
+	alias = strings.TrimSpace(alias)
+	normalized := normalizePerformerName(alias)
+	if normalized == "" {
+		return nil, fmt.Errorf("alias is required")
+	}
+	if normalized == normalizePerformerName(performer.Name) {
+		return nil, fmt.Errorf("alias %q is the performer's name", alias)
+	}
+	ownerID, err := findPerformerIDByName(s.db, alias)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if err == nil && ownerID != performerID {
+		return nil, fmt.Errorf("%q already names another performer (ID %d)", alias, ownerID)
+	}
+
+	created := &models.PerformerAlias{
+		PerformerID: performerID,
+		Alias:       alias,
+		Source:      models.AliasSourceManual,
+		CreatedAt:   time.Now(),
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO performer_aliases (performer_id, alias, normalized, source, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, performerID, alias, normalized, created.Source, created.CreatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, fmt.Errorf("performer already has alias %q", alias)
+		}
+		return nil, fmt.Errorf("failed to add alias: %w", err)
+	}
+
+	created.ID, err = result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	return created, nil
+}
+
+// DeleteAlias removes one of a performer's aliases
+func (s *PerformerService) DeleteAlias(performerID, aliasID int64) error {
+	result, err := s.db.Exec(`DELETE FROM performer_aliases WHERE id = ? AND performer_id = ?`, aliasID, performerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete alias: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("alias not found")
+	}
+	return nil
+}
+
+// syncMetadataAliases replaces a performer's metadata aliases with those in meta.
+// Manual aliases are kept, and win when both name the same alias.
+func (s *PerformerService) syncMetadataAliases(performerID int64, name string, meta *models.PerformerMetadata) error {
+	if _, err := s.db.Exec(`DELETE FROM performer_aliases WHERE performer_id = ? AND source = ?`,
+		performerID, models.AliasSourceMetadata); err != nil {
+		return fmt.Errorf("failed to clear metadata aliases: %w", err)
+	}
+	if meta == nil {
+		return nil
+	}
+
+	own := normalizePerformerName(name)
+	for _, alias := range meta.Aliases {
+		alias = strings.TrimSpace(alias)
+		normalized := normalizePerformerName(alias)
+		if normalized == "" || normalized == own {
+			continue
+		}
+		_, err := s.db.Exec(`
+			INSERT OR IGNORE INTO performer_aliases (performer_id, alias, normalized, source, created_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, performerID, alias, normalized, models.AliasSourceMetadata, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to add metadata alias: %w", err)
+		}
+	}
+	return nil
+}
+
+// SyncAllMetadataAliases copies aliases from every performer's metadata into
+// the aliases table. It's safe to repeat, and fills the table for performers
+// whose metadata predates it.
+func (s *PerformerService) SyncAllMetadataAliases() error {
+	performers, err := s.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, p := range performers {
+		if p.MetadataObj == nil || len(p.MetadataObj.Aliases) == 0 {
+			continue
+		}
+		if err := s.syncMetadataAliases(p.ID, p.Name, p.MetadataObj); err != nil {
+			log.Printf("Failed to sync aliases for performer %d: %v", p.ID, err)
+		}
+	}
+	return nil
+}
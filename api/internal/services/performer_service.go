@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/brixen96/video-storage-ai/internal/database"
@@ -88,23 +89,17 @@ func (s *PerformerService) GetByID(id int64) (*models.Performer, error) {
 		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
 	}
 
+	p.Aliases, err = s.GetAliases(id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &p, nil
 }
 
-// GetPerformerByName retrieves a performer by name
+// GetPerformerByName retrieves a performer by name or alias, ignoring case and accents
 func (s *PerformerService) GetPerformerByName(name string) (*models.Performer, error) {
-	query := `
-		SELECT id, name, preview_path, thumbnail_path, folder_path, video_count, category, metadata, created_at, updated_at
-		FROM performers
-		WHERE name = ?
-	`
-
-	var p models.Performer
-	err := s.db.QueryRow(query, name).Scan(
-		&p.ID, &p.Name, &p.PreviewPath, &p.ThumbnailPath, &p.FolderPath,
-		&p.VideoCount, &p.Category, &p.Metadata, &p.CreatedAt, &p.UpdatedAt,
-	)
-
+	id, err := findPerformerIDByName(s.db, name)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("performer not found")
 	}
@@ -112,12 +107,7 @@ func (s *PerformerService) GetPerformerByName(name string) (*models.Performer, e
 		return nil, fmt.Errorf("failed to query performer: %w", err)
 	}
 
-	// Parse metadata JSON
-	if err := p.UnmarshalMetadata(); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-	}
-
-	return &p, nil
+	return s.GetByID(id)
 }
 
 // Create creates a new performer
@@ -125,6 +115,9 @@ func (s *PerformerService) Create(create *models.PerformerCreate) (*models.Perfo
 	// Check if performer already exists
 	existing, _ := s.GetPerformerByName(create.Name)
 	if existing != nil {
+		if !strings.EqualFold(existing.Name, create.Name) {
+			return nil, fmt.Errorf("'%s' is already known as performer '%s'", create.Name, existing.Name)
+		}
 		return nil, fmt.Errorf("performer with name '%s' already exists", create.Name)
 	}
 
@@ -151,13 +144,13 @@ func (s *PerformerService) Create(create *models.PerformerCreate) (*models.Perfo
 
 	// Insert into database
 	query := `
-		INSERT INTO performers (name, preview_path, folder_path, video_count, category, metadata, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO performers (name, normalized_name, preview_path, folder_path, video_count, category, metadata, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := s.db.Exec(
 		query,
-		performer.Name, performer.PreviewPath, performer.FolderPath,
+		performer.Name, normalizePerformerName(performer.Name), performer.PreviewPath, performer.FolderPath,
 		performer.VideoCount, performer.Category, performer.Metadata, performer.CreatedAt, performer.UpdatedAt,
 	)
 	if err != nil {
@@ -170,6 +163,15 @@ func (s *PerformerService) Create(create *models.PerformerCreate) (*models.Perfo
 	}
 
 	performer.ID = id
+
+	if err := s.syncMetadataAliases(id, performer.Name, performer.MetadataObj); err != nil {
+		return nil, err
+	}
+	performer.Aliases, err = s.GetAliases(id)
+	if err != nil {
+		return nil, err
+	}
+
 	return performer, nil
 }
 
@@ -211,19 +213,29 @@ func (s *PerformerService) Update(id int64, update *models.PerformerUpdate) (*mo
 	// Update database
 	query := `
 		UPDATE performers
-		SET name = ?, preview_path = ?, thumbnail_path = ?, folder_path = ?, category = ?, metadata = ?, updated_at = ?
+		SET name = ?, normalized_name = ?, preview_path = ?, thumbnail_path = ?, folder_path = ?, category = ?, metadata = ?, updated_at = ?
 		WHERE id = ?
 	`
 
 	_, err = s.db.Exec(
 		query,
-		performer.Name, performer.PreviewPath, performer.ThumbnailPath, performer.FolderPath,
+		performer.Name, normalizePerformerName(performer.Name), performer.PreviewPath, performer.ThumbnailPath, performer.FolderPath,
 		performer.Category, performer.Metadata, performer.UpdatedAt, id,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update performer: %w", err)
 	}
 
+	if update.Metadata != nil {
+		if err := s.syncMetadataAliases(id, performer.Name, performer.MetadataObj); err != nil {
+			return nil, err
+		}
+		performer.Aliases, err = s.GetAliases(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return performer, nil
 }
 
@@ -241,48 +253,23 @@ func (s *PerformerService) Delete(id int64) error {
 		return fmt.Errorf("failed to delete performer: %w", err)
 	}
 
+	// Aliases have no foreign key to cascade from
+	if _, err := s.db.Exec(`DELETE FROM performer_aliases WHERE performer_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete aliases: %w", err)
+	}
+
 	return nil
 }
 
-// Search searches performers by name
+// Search searches performers by name and alias, tolerating typos, case and accents.
+// Results are ranked best match first.
 func (s *PerformerService) Search(searchTerm string) ([]models.Performer, error) {
-	query := `
-		SELECT id, name, preview_path, thumbnail_path, folder_path, video_count, category, metadata, created_at, updated_at
-		FROM performers
-		WHERE name LIKE ?
-		ORDER BY name ASC
-	`
-
-	rows, err := s.db.Query(query, "%"+searchTerm+"%")
+	hits, err := s.fuzzySearch(searchTerm)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search performers: %w", err)
 	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			log.Printf("failed to close rows: %v", err)
-		}
-	}()
-
-	var performers []models.Performer
-	for rows.Next() {
-		var p models.Performer
-		err := rows.Scan(
-			&p.ID, &p.Name, &p.PreviewPath, &p.ThumbnailPath, &p.FolderPath,
-			&p.VideoCount, &p.Category, &p.Metadata, &p.CreatedAt, &p.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan performer: %w", err)
-		}
 
-		// Parse metadata JSON
-		if err := p.UnmarshalMetadata(); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-		}
-
-		performers = append(performers, p)
-	}
-
-	return performers, nil
+	return s.getSearchHits(hits)
 }
 
 // ResetMetadata clears all metadata for a performer
@@ -298,6 +285,10 @@ func (s *PerformerService) ResetMetadata(id int64) error {
 		return fmt.Errorf("failed to reset metadata: %w", err)
 	}
 
+	if err := s.syncMetadataAliases(id, "", nil); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -355,58 +346,22 @@ func (s *PerformerService) GetAllPaginated(limit, offset int) ([]models.Performe
 	return performers, total, nil
 }
 
-// SearchPaginated searches performers with pagination
+// SearchPaginated searches performers by name and alias with pagination, best match first
 func (s *PerformerService) SearchPaginated(searchTerm string, limit, offset int) ([]models.Performer, int64, error) {
-	searchPattern := "%" + searchTerm + "%"
-
-	// Get total count
-	var total int64
-	countQuery := "SELECT COUNT(*) FROM performers WHERE name LIKE ?"
-	err := s.db.QueryRow(countQuery, searchPattern).Scan(&total)
+	hits, err := s.fuzzySearch(searchTerm)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count performers: %w", err)
+		return nil, 0, fmt.Errorf("failed to search performers: %w", err)
 	}
 
-	// Get paginated results
-	query := `
-        SELECT id, name, preview_path, thumbnail_path, folder_path, video_count, category, metadata, created_at, updated_at
-        FROM performers
-        WHERE name LIKE ?
-        ORDER BY name ASC
-        LIMIT ? OFFSET ?
-    `
-
-	rows, err := s.db.Query(query, searchPattern, limit, offset)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query performers: %w", err)
-	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			log.Printf("failed to close rows: %v", err)
-		}
-	}()
-
-	var performers []models.Performer
-	for rows.Next() {
-		var p models.Performer
-		err := rows.Scan(
-			&p.ID, &p.Name, &p.PreviewPath, &p.ThumbnailPath, &p.FolderPath,
-			&p.VideoCount, &p.Category, &p.Metadata, &p.CreatedAt, &p.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan performer: %w", err)
-		}
-
-		// Parse metadata JSON
-		if err := p.UnmarshalMetadata(); err != nil {
-			return nil, 0, fmt.Errorf("failed to unmarshal metadata: %w", err)
-		}
-
-		performers = append(performers, p)
+	total := int64(len(hits))
+	if offset >= len(hits) {
+		return []models.Performer{}, total, nil
 	}
+	hits = hits[offset:min(offset+limit, len(hits))]
 
-	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating performers: %w", err)
+	performers, err := s.getSearchHits(hits)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	return performers, total, nil
@@ -514,29 +469,28 @@ func (s *PerformerService) SyncPerformerTagsToVideos(performerID int64) (int, er
 	// Add each master tag to each video (if not already present)
 	tagsAdded := 0
 	videosUpdated := make(map[int64]bool)
+	var applied []models.OperationChange
 	for _, videoID := range videoIDs {
 		for _, tag := range performerTags {
-			// Check if video already has this tag
-			var count int
-			err := s.db.QueryRow("SELECT COUNT(*) FROM video_tags WHERE video_id = ? AND tag_id = ?", videoID, tag.ID).Scan(&count)
+			// Adds the tag and its ancestors unless the video already has them
+			added, err := insertVideoTags(s.db, []linkPair{{videoID, tag.ID}})
 			if err != nil {
-				log.Printf("Warning: Failed to check existing video tag: %v", err)
+				log.Printf("Warning: Failed to add tag %d to video %d: %v", tag.ID, videoID, err)
 				continue
 			}
-
-			if count == 0 {
-				// Add the tag
-				_, err = s.db.Exec("INSERT INTO video_tags (video_id, tag_id) VALUES (?, ?)", videoID, tag.ID)
-				if err != nil {
-					log.Printf("Warning: Failed to add tag %d to video %d: %v", tag.ID, videoID, err)
-					continue
-				}
-				tagsAdded++
+			if len(added) > 0 {
+				tagsAdded += len(added)
 				videosUpdated[videoID] = true
+				applied = appendLinkChanges(applied, models.ChangeRowAdded, "video_tags", added)
 			}
 		}
 	}
 
+	summary := fmt.Sprintf("Synced master tags of performer %d to %d videos", performerID, len(videosUpdated))
+	if _, err := journalOperation(s.db, models.OperationTagApply, summary, applied); err != nil {
+		log.Printf("Failed to journal tag sync for performer %d: %v", performerID, err)
+	}
+
 	log.Printf("Synced %d tags to %d videos for performer %d", tagsAdded, len(videosUpdated), performerID)
 	return len(videosUpdated), nil
 }
@@ -552,21 +506,13 @@ func (s *PerformerService) ApplyMasterTagsToVideo(performerID, videoID int64) er
 
 	// Add each master tag to the video (if not already present)
 	for _, tag := range performerTags {
-		// Check if video already has this tag
-		var count int
-		err := s.db.QueryRow("SELECT COUNT(*) FROM video_tags WHERE video_id = ? AND tag_id = ?", videoID, tag.ID).Scan(&count)
+		// Adds the tag and its ancestors unless the video already has them
+		added, err := insertVideoTags(s.db, []linkPair{{videoID, tag.ID}})
 		if err != nil {
-			log.Printf("Warning: Failed to check existing video tag: %v", err)
+			log.Printf("Warning: Failed to add master tag %d to video %d: %v", tag.ID, videoID, err)
 			continue
 		}
-
-		if count == 0 {
-			// Add the tag
-			_, err = s.db.Exec("INSERT INTO video_tags (video_id, tag_id) VALUES (?, ?)", videoID, tag.ID)
-			if err != nil {
-				log.Printf("Warning: Failed to add master tag %d to video %d: %v", tag.ID, videoID, err)
-				continue
-			}
+		if len(added) > 0 {
 			log.Printf("Applied master tag '%s' from performer %d to video %d", tag.Name, performerID, videoID)
 		}
 	}
@@ -0,0 +1,565 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// maxPlaylistAdd caps how many videos one add can insert, including search results
+const maxPlaylistAdd = 5000
+
+// PlaylistService handles playlist business logic
+type PlaylistService struct {
+	db           *sql.DB
+	videoService *VideoService
+}
+
+// NewPlaylistService creates a new playlist service
+func NewPlaylistService() *PlaylistService {
+	return &PlaylistService{
+		db:           database.GetDB(),
+		videoService: NewVideoService(NewActivityService(), NewLibraryService(), NewPerformerService()),
+	}
+}
+
+// playlistColumns lists the columns scanPlaylist expects, in order. The cover is
+// the chosen video's thumbnail, or the first item's.
+const playlistColumns = `p.id, p.name, COALESCE(p.description, ''), p.cover_video_id, p.created_at, p.updated_at,
+	(SELECT COUNT(*) FROM playlist_items WHERE playlist_id = p.id),
+	(SELECT COALESCE(SUM(v.duration), 0) FROM playlist_items i JOIN videos v ON v.id = i.video_id WHERE i.playlist_id = p.id),
+	COALESCE((SELECT thumbnail_path FROM videos WHERE id = COALESCE(p.cover_video_id,
+		(SELECT video_id FROM playlist_items WHERE playlist_id = p.id ORDER BY position LIMIT 1))), '')`
+
+// scanPlaylist reads a playlist from a row selected with playlistColumns
+func scanPlaylist(row interface{ Scan(...interface{}) error }) (*models.Playlist, error) {
+	var p models.Playlist
+	var coverVideoID sql.NullInt64
+	err := row.Scan(&p.ID, &p.Name, &p.Description, &coverVideoID, &p.CreatedAt, &p.UpdatedAt,
+		&p.ItemCount, &p.TotalDuration, &p.CoverThumbnail)
+	if err != nil {
+		return nil, err
+	}
+	if coverVideoID.Valid {
+		p.CoverVideoID = &coverVideoID.Int64
+	}
+	return &p, nil
+}
+
+// GetAll retrieves every playlist without its items
+func (s *PlaylistService) GetAll() ([]models.Playlist, error) {
+	rows, err := s.db.Query(`SELECT ` + playlistColumns + ` FROM playlists p ORDER BY p.name COLLATE NOCASE ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query playlists: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	playlists := []models.Playlist{}
+	for rows.Next() {
+		playlist, err := scanPlaylist(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan playlist: %w", err)
+		}
+		playlists = append(playlists, *playlist)
+	}
+	return playlists, rows.Err()
+}
+
+// get retrieves a playlist without its items
+func (s *PlaylistService) get(id int64) (*models.Playlist, error) {
+	playlist, err := scanPlaylist(s.db.QueryRow(`SELECT `+playlistColumns+` FROM playlists p WHERE p.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("playlist not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query playlist: %w", err)
+	}
+	return playlist, nil
+}
+
+// GetByID retrieves a playlist with its items and their videos, in order
+func (s *PlaylistService) GetByID(id int64) (*models.Playlist, error) {
+	playlist, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	playlist.Items, err = s.getItems(id)
+	if err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// getItems loads a playlist's items in order, with their videos
+func (s *PlaylistService) getItems(playlistID int64) ([]models.PlaylistItem, error) {
+	rows, err := s.db.Query(`
+		SELECT id, playlist_id, video_id, position, added_at
+		FROM playlist_items
+		WHERE playlist_id = ?
+		ORDER BY position ASC
+	`, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query playlist items: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	items := []models.PlaylistItem{}
+	for rows.Next() {
+		var item models.PlaylistItem
+		if err := rows.Scan(&item.ID, &item.PlaylistID, &item.VideoID, &item.Position, &item.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan playlist item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating playlist items: %w", err)
+	}
+
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = item.VideoID
+	}
+	videos, err := s.videoService.getVideosByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*models.Video, len(videos))
+	for i := range videos {
+		byID[videos[i].ID] = &videos[i]
+	}
+	for i := range items {
+		items[i].Video = byID[items[i].VideoID]
+	}
+	return items, nil
+}
+
+// checkCoverVideo makes sure a cover video exists
+func (s *PlaylistService) checkCoverVideo(videoID int64) error {
+	var exists bool
+	if err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM videos WHERE id = ?)`, videoID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up cover video: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("cover video %d not found", videoID)
+	}
+	return nil
+}
+
+// Create creates a new, empty playlist
+func (s *PlaylistService) Create(create *models.PlaylistCreate) (*models.Playlist, error) {
+	name := strings.TrimSpace(create.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if create.CoverVideoID != nil {
+		if err := s.checkCoverVideo(*create.CoverVideoID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec(`
+		INSERT INTO playlists (name, description, cover_video_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, name, create.Description, create.CoverVideoID, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create playlist: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	return s.GetByID(id)
+}
+
+// Update updates a playlist's name, description or cover
+func (s *PlaylistService) Update(id int64, update *models.PlaylistUpdate) (*models.Playlist, error) {
+	playlist, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		playlist.Name = strings.TrimSpace(*update.Name)
+		if playlist.Name == "" {
+			return nil, fmt.Errorf("name is required")
+		}
+	}
+	if update.Description != nil {
+		playlist.Description = *update.Description
+	}
+	if update.CoverVideoID != nil {
+		playlist.CoverVideoID = nil
+		if *update.CoverVideoID != 0 {
+			if err := s.checkCoverVideo(*update.CoverVideoID); err != nil {
+				return nil, err
+			}
+			playlist.CoverVideoID = update.CoverVideoID
+		}
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE playlists SET name = ?, description = ?, cover_video_id = ?, updated_at = ?
+		WHERE id = ?
+	`, playlist.Name, playlist.Description, playlist.CoverVideoID, time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update playlist: %w", err)
+	}
+	return s.GetByID(id)
+}
+
+// Delete deletes a playlist and its items; the videos are untouched
+func (s *PlaylistService) Delete(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM playlists WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete playlist: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("playlist not found")
+	}
+	return nil
+}
+
+// AddVideos inserts videos into a playlist, listed IDs first and then search
+// results. Videos already in the playlist are skipped and reported, since each
+// video appears once.
+func (s *PlaylistService) AddVideos(playlistID int64, req *models.PlaylistAddRequest) (*models.PlaylistAddResult, error) {
+	playlist, err := s.get(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.PlaylistAddResult{Duplicates: []int64{}, NotFound: []int64{}}
+
+	existing, err := s.videoIDsIn(`SELECT video_id FROM playlist_items WHERE playlist_id = ?`, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listed videos must exist; search results always do
+	var candidates []int64
+	if len(req.VideoIDs) > 0 {
+		if len(req.VideoIDs) > maxPlaylistAdd {
+			return nil, fmt.Errorf("at most %d videos can be added at once", maxPlaylistAdd)
+		}
+		args := make([]interface{}, len(req.VideoIDs))
+		for i, id := range req.VideoIDs {
+			args[i] = id
+		}
+		found, err := s.videoIDsIn(`SELECT id FROM videos WHERE id IN (?`+strings.Repeat(",?", len(args)-1)+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range req.VideoIDs {
+			if found[id] {
+				candidates = append(candidates, id)
+			} else {
+				result.NotFound = append(result.NotFound, id)
+			}
+		}
+	}
+	if req.Query != nil && len(candidates) < maxPlaylistAdd {
+		matches, err := s.videoService.SearchIDs(req.Query, maxPlaylistAdd-len(candidates))
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, matches...)
+	}
+
+	var toAdd []int64
+	for _, id := range candidates {
+		if existing[id] {
+			result.Duplicates = append(result.Duplicates, id)
+			continue
+		}
+		existing[id] = true
+		toAdd = append(toAdd, id)
+	}
+	if len(toAdd) == 0 {
+		return result, nil
+	}
+
+	position := playlist.ItemCount
+	if req.Position != nil && *req.Position >= 0 && *req.Position < position {
+		position = *req.Position
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back playlist add: %v", err)
+		}
+	}()
+
+	// Make room, then fill the gap in order
+	if _, err := tx.Exec(`UPDATE playlist_items SET position = position + ? WHERE playlist_id = ? AND position >= ?`,
+		len(toAdd), playlistID, position); err != nil {
+		return nil, fmt.Errorf("failed to shift playlist items: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO playlist_items (playlist_id, video_id, position, added_at) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer func() {
+		if err := stmt.Close(); err != nil {
+			log.Printf("failed to close statement: %v", err)
+		}
+	}()
+	now := time.Now()
+	for i, videoID := range toAdd {
+		if _, err := stmt.Exec(playlistID, videoID, position+i, now); err != nil {
+			return nil, fmt.Errorf("failed to add video %d: %w", videoID, err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE playlists SET updated_at = ? WHERE id = ?`, now, playlistID); err != nil {
+		return nil, fmt.Errorf("failed to update playlist: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit playlist add: %w", err)
+	}
+
+	result.Added = len(toAdd)
+	return result, nil
+}
+
+// videoIDsIn runs a query selecting video IDs into a set
+func (s *PlaylistService) videoIDsIn(query string, args ...interface{}) (map[int64]bool, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query videos: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan video ID: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// RemoveItem takes an item out of a playlist. The items after it move up.
+func (s *PlaylistService) RemoveItem(playlistID, itemID int64) error {
+	result, err := s.db.Exec(`DELETE FROM playlist_items WHERE id = ? AND playlist_id = ?`, itemID, playlistID)
+	if err != nil {
+		return fmt.Errorf("failed to remove playlist item: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("playlist item not found")
+	}
+	if _, err := s.db.Exec(`UPDATE playlists SET updated_at = ? WHERE id = ?`, time.Now(), playlistID); err != nil {
+		return fmt.Errorf("failed to update playlist: %w", err)
+	}
+	return nil
+}
+
+// MoveItem moves an item to a new position, as when it's dragged. The items in
+// between shift by one; positions past the end move it to the end.
+func (s *PlaylistService) MoveItem(playlistID, itemID int64, position int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back playlist move: %v", err)
+		}
+	}()
+
+	var current, count int
+	err = tx.QueryRow(`
+		SELECT position, (SELECT COUNT(*) FROM playlist_items WHERE playlist_id = ?)
+		FROM playlist_items WHERE id = ? AND playlist_id = ?
+	`, playlistID, itemID, playlistID).Scan(&current, &count)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("playlist item not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query playlist item: %w", err)
+	}
+
+	if position < 0 {
+		position = 0
+	}
+	if position >= count {
+		position = count - 1
+	}
+	if position == current {
+		return nil
+	}
+
+	if position < current {
+		_, err = tx.Exec(`UPDATE playlist_items SET position = position + 1 WHERE playlist_id = ? AND position >= ? AND position < ?`,
+			playlistID, position, current)
+	} else {
+		_, err = tx.Exec(`UPDATE playlist_items SET position = position - 1 WHERE playlist_id = ? AND position > ? AND position <= ?`,
+			playlistID, current, position)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to shift playlist items: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE playlist_items SET position = ? WHERE id = ?`, position, itemID); err != nil {
+		return fmt.Errorf("failed to move playlist item: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE playlists SET updated_at = ? WHERE id = ?`, time.Now(), playlistID); err != nil {
+		return fmt.Errorf("failed to update playlist: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Next finds the item to play after a video, or the first item when
+// afterVideoID is 0. Videos whose files are missing are skipped. At the end it
+// returns nil, or wraps to the start when loop is set.
+func (s *PlaylistService) Next(playlistID, afterVideoID int64, loop bool) (*models.PlaylistItem, error) {
+	if _, err := s.get(playlistID); err != nil {
+		return nil, err
+	}
+
+	current := -1
+	if afterVideoID != 0 {
+		err := s.db.QueryRow(`SELECT position FROM playlist_items WHERE playlist_id = ? AND video_id = ?`,
+			playlistID, afterVideoID).Scan(&current)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("video %d isn't in this playlist", afterVideoID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query playlist item: %w", err)
+		}
+	}
+
+	next := func(condition string) (*models.PlaylistItem, error) {
+		var item models.PlaylistItem
+		err := s.db.QueryRow(`
+			SELECT i.id, i.playlist_id, i.video_id, i.position, i.added_at
+			FROM playlist_items i
+			JOIN videos v ON v.id = i.video_id
+			WHERE i.playlist_id = ? AND `+condition+` AND COALESCE(v.status, 'available') != 'missing'
+			ORDER BY i.position ASC
+			LIMIT 1
+		`, playlistID, current).Scan(&item.ID, &item.PlaylistID, &item.VideoID, &item.Position, &item.AddedAt)
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query next item: %w", err)
+		}
+		return &item, nil
+	}
+
+	item, err := next("i.position > ?")
+	if err == nil && item == nil && loop {
+		item, err = next("i.position <= ?")
+	}
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	item.Video, err = s.videoService.GetByID(item.VideoID)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// playableItems loads a playlist's items for export, leaving out missing files
+func (s *PlaylistService) playableItems(playlistID int64) (*models.Playlist, []models.PlaylistItem, error) {
+	playlist, err := s.GetByID(playlistID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var items []models.PlaylistItem
+	for _, item := range playlist.Items {
+		if item.Video != nil && item.Video.Status != models.VideoStatusMissing {
+			items = append(items, item)
+		}
+	}
+	return playlist, items, nil
+}
+
+// streamURL is where a player fetches a video from the API
+func streamURL(baseURL string, videoID int64) string {
+	return fmt.Sprintf("%s/api/v1/videos/%d/stream", strings.TrimSuffix(baseURL, "/"), videoID)
+}
+
+// ExportM3U8 writes a playlist as extended M3U with stream URLs under baseURL
+func (s *PlaylistService) ExportM3U8(playlistID int64, baseURL string) (string, error) {
+	playlist, items, err := s.playableItems(playlistID)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	fmt.Fprintf(&b, "#PLAYLIST:%s\n", playlist.Name)
+	for _, item := range items {
+		// Titles end at the line break
+		title := strings.NewReplacer("\r", " ", "\n", " ").Replace(item.Video.Title)
+		fmt.Fprintf(&b, "#EXTINF:%d,%s\n%s\n", int(item.Video.Duration), title, streamURL(baseURL, item.VideoID))
+	}
+	return b.String(), nil
+}
+
+// xspfPlaylist is the XML Shareable Playlist Format document
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"playlist"`
+	Version string      `xml:"version,attr"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	Annot   string      `xml:"annotation,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+// xspfTrack is one XSPF entry; duration is in milliseconds
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title"`
+	Duration int64  `xml:"duration,omitempty"`
+}
+
+// ExportXSPF writes a playlist as XSPF with stream URLs under baseURL
+func (s *PlaylistService) ExportXSPF(playlistID int64, baseURL string) ([]byte, error) {
+	playlist, items, err := s.playableItems(playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := xspfPlaylist{Version: "1", XMLNS: "http://xspf.org/ns/0/", Title: playlist.Name, Annot: playlist.Description}
+	for _, item := range items {
+		doc.Tracks = append(doc.Tracks, xspfTrack{
+			Location: streamURL(baseURL, item.VideoID),
+			Title:    item.Video.Title,
+			Duration: int64(item.Video.Duration * 1000),
+		})
+	}
+
+	encoded, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode playlist: %w", err)
+	}
+	return append([]byte(xml.Header), encoded...), nil
+}
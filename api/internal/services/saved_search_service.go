@@ -0,0 +1,267 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// SavedSearchService handles saved search business logic
+type SavedSearchService struct {
+	db           *sql.DB
+	videoService *VideoService
+}
+
+// NewSavedSearchService creates a new saved search service
+func NewSavedSearchService() *SavedSearchService {
+	return &SavedSearchService{
+		db:           database.GetDB(),
+		videoService: NewVideoService(NewActivityService(), NewLibraryService(), NewPerformerService()),
+	}
+}
+
+// savedSearchColumns lists the columns scanSavedSearch expects, in order
+const savedSearchColumns = `id, name, COALESCE(icon, ''), pinned, query, video_count, count_updated_at, created_at, updated_at`
+
+// scanSavedSearch reads a saved search from a row selected with savedSearchColumns
+func scanSavedSearch(row interface{ Scan(...interface{}) error }) (*models.SavedSearch, error) {
+	var search models.SavedSearch
+	var query string
+	var count sql.NullInt64
+	var countUpdatedAt sql.NullTime
+	err := row.Scan(&search.ID, &search.Name, &search.Icon, &search.Pinned, &query,
+		&count, &countUpdatedAt, &search.CreatedAt, &search.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(query), &search.Query); err != nil {
+		return nil, fmt.Errorf("failed to parse saved query: %w", err)
+	}
+	if count.Valid {
+		n := int(count.Int64)
+		search.VideoCount = &n
+	}
+	if countUpdatedAt.Valid {
+		search.CountUpdatedAt = &countUpdatedAt.Time
+	}
+	return &search, nil
+}
+
+// encodeSavedQuery validates a query and serializes it without pagination
+func encodeSavedQuery(query models.VideoSearchQuery) (string, error) {
+	if query.Q != "" {
+		if _, err := compileVideoQuery(query.Q, time.Now()); err != nil {
+			return "", err
+		}
+	}
+	query.Page = 0
+	query.Limit = 0
+
+	encoded, err := json.Marshal(query)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode saved query: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// GetAll retrieves every saved search, pinned ones first
+func (s *SavedSearchService) GetAll() ([]models.SavedSearch, error) {
+	rows, err := s.db.Query(`SELECT ` + savedSearchColumns + ` FROM saved_searches ORDER BY pinned DESC, name COLLATE NOCASE ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved searches: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	searches := []models.SavedSearch{}
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		searches = append(searches, *search)
+	}
+	return searches, rows.Err()
+}
+
+// GetByID retrieves a saved search by ID
+func (s *SavedSearchService) GetByID(id int64) (*models.SavedSearch, error) {
+	search, err := scanSavedSearch(s.db.QueryRow(`SELECT `+savedSearchColumns+` FROM saved_searches WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("saved search not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved search: %w", err)
+	}
+	return search, nil
+}
+
+// Create creates a new saved search and counts its videos in the background
+func (s *SavedSearchService) Create(create *models.SavedSearchCreate) (*models.SavedSearch, error) {
+	name := strings.TrimSpace(create.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	query, err := encodeSavedQuery(create.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec(`
+		INSERT INTO saved_searches (name, icon, pinned, query, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, name, create.Icon, create.Pinned, query, now, now)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, fmt.Errorf("a saved search named %q already exists", name)
+		}
+		return nil, fmt.Errorf("failed to create saved search: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	go s.refreshCountInBackground(id)
+	return s.GetByID(id)
+}
+
+// Update updates an existing saved search. Changing the query recounts it in the background.
+func (s *SavedSearchService) Update(id int64, update *models.SavedSearchUpdate) (*models.SavedSearch, error) {
+	search, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		search.Name = strings.TrimSpace(*update.Name)
+		if search.Name == "" {
+			return nil, fmt.Errorf("name is required")
+		}
+	}
+	if update.Icon != nil {
+		search.Icon = *update.Icon
+	}
+	if update.Pinned != nil {
+		search.Pinned = *update.Pinned
+	}
+	if update.Query != nil {
+		search.Query = *update.Query
+		search.VideoCount = nil
+		search.CountUpdatedAt = nil
+	}
+	query, err := encodeSavedQuery(search.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	search.UpdatedAt = time.Now()
+	_, err = s.db.Exec(`
+		UPDATE saved_searches
+		SET name = ?, icon = ?, pinned = ?, query = ?, video_count = ?, count_updated_at = ?, updated_at = ?
+		WHERE id = ?
+	`, search.Name, search.Icon, search.Pinned, query, search.VideoCount, search.CountUpdatedAt, search.UpdatedAt, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, fmt.Errorf("a saved search named %q already exists", search.Name)
+		}
+		return nil, fmt.Errorf("failed to update saved search: %w", err)
+	}
+
+	if update.Query != nil {
+		go s.refreshCountInBackground(id)
+	}
+	return search, nil
+}
+
+// Delete deletes a saved search
+func (s *SavedSearchService) Delete(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM saved_searches WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("saved search not found")
+	}
+	return nil
+}
+
+// Execute runs a saved search live and returns one page of its videos. The
+// count is stored as a side effect, since the query computes it anyway.
+func (s *SavedSearchService) Execute(id int64, page, limit int) (*models.SavedSearch, []models.Video, int, error) {
+	search, err := s.GetByID(id)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	query := search.Query
+	query.Page = page
+	query.Limit = limit
+	videos, total, err := s.videoService.GetAll(&query)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	if err := s.storeCount(search, total); err != nil {
+		log.Printf("Warning: Failed to store count for saved search %d: %v", id, err)
+	}
+	return search, videos, total, nil
+}
+
+// storeCount records how many videos a saved search matched
+func (s *SavedSearchService) storeCount(search *models.SavedSearch, count int) error {
+	now := time.Now()
+	search.VideoCount = &count
+	search.CountUpdatedAt = &now
+	_, err := s.db.Exec(`UPDATE saved_searches SET video_count = ?, count_updated_at = ? WHERE id = ?`, count, now, search.ID)
+	return err
+}
+
+// RefreshCount recounts the videos a saved search matches
+func (s *SavedSearchService) RefreshCount(id int64) error {
+	search, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	query := search.Query
+	query.Page = 1
+	query.Limit = 1
+	_, total, err := s.videoService.GetAll(&query)
+	if err != nil {
+		return fmt.Errorf("failed to count saved search %s: %w", search.Name, err)
+	}
+	return s.storeCount(search, total)
+}
+
+// refreshCountInBackground recounts a saved search, logging rather than returning failures
+func (s *SavedSearchService) refreshCountInBackground(id int64) {
+	if err := s.RefreshCount(id); err != nil {
+		log.Printf("Failed to refresh saved search count: %v", err)
+	}
+}
+
+// RefreshAllCounts recounts every saved search
+func (s *SavedSearchService) RefreshAllCounts() error {
+	searches, err := s.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, search := range searches {
+		if err := s.RefreshCount(search.ID); err != nil {
+			log.Printf("Failed to refresh saved search count: %v", err)
+		}
+	}
+	return nil
+}
@@ -999,8 +999,32 @@ func (s *ScraperService) GetThreadByID(id int64) (*models.ScrapedThread, error)
 	return thread, nil
 }
 
-// SearchThreads searches threads by title or content
+// threadSearchHits ranks threads by their best matching title, author or post.
+// SQLite takes the bare snippet column from the row holding MIN(rank).
+const threadSearchHits = `
+	WITH hits AS (
+		SELECT rowid AS thread_id, bm25(threads_fts, 5.0, 1.0) AS rank,
+		       snippet(threads_fts, -1, ?, ?, ?, ?) AS snippet
+		FROM threads_fts WHERE threads_fts MATCH ?
+		UNION ALL
+		SELECT p.thread_id, bm25(posts_fts, 1.0, 2.0),
+		       snippet(posts_fts, -1, ?, ?, ?, ?)
+		FROM posts_fts JOIN scraped_posts p ON p.id = posts_fts.rowid
+		WHERE posts_fts MATCH ?
+	), best AS (
+		SELECT thread_id, MIN(rank) AS rank, snippet FROM hits GROUP BY thread_id
+	)
+`
+
+// SearchThreads searches threads by title, author or post content. Results are
+// ranked with full-text search when available.
 func (s *ScraperService) SearchThreads(query string, limit, offset int) ([]*models.ScrapedThread, int, error) {
+	if database.FTSEnabled() {
+		if match := buildFTSQuery(query); match != "" {
+			return s.searchThreadsFullText(match, limit, offset)
+		}
+	}
+
 	searchPattern := "%" + query + "%"
 
 	var total int
@@ -1043,6 +1067,62 @@ func (s *ScraperService) SearchThreads(query string, limit, offset int) ([]*mode
 	return threads, total, nil
 }
 
+// searchThreadsFullText ranks threads against an FTS5 match expression
+func (s *ScraperService) searchThreadsFullText(match string, limit, offset int) ([]*models.ScrapedThread, int, error) {
+	hitArgs := []interface{}{
+		searchMarkOpen, searchMarkClose, searchEllipsis, searchSnippetTokens, match,
+		searchMarkOpen, searchMarkClose, searchEllipsis, searchSnippetTokens, match,
+	}
+
+	var total int
+	if err := s.db.QueryRow(threadSearchHits+`SELECT COUNT(*) FROM best`, hitArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count matching threads: %w", err)
+	}
+
+	rows, err := s.db.Query(threadSearchHits+`
+		SELECT t.id, t.external_id, t.source, t.title, t.url, t.author, t.category,
+			   t.view_count, t.reply_count, t.post_count, t.download_count,
+			   t.metadata, t.first_scraped_at, t.last_scraped_at, t.last_updated_at, t.created_at,
+			   best.snippet
+		FROM best
+		JOIN scraped_threads t ON t.id = best.thread_id
+		ORDER BY best.rank ASC, t.last_scraped_at DESC
+		LIMIT ? OFFSET ?
+	`, append(hitArgs, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search threads: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	threads := []*models.ScrapedThread{}
+	for rows.Next() {
+		thread := &models.ScrapedThread{}
+		var author, category sql.NullString
+		var lastUpdatedAt sql.NullTime
+		err := rows.Scan(
+			&thread.ID, &thread.ExternalID, &thread.Source, &thread.Title,
+			&thread.URL, &author, &category, &thread.ViewCount,
+			&thread.ReplyCount, &thread.PostCount, &thread.DownloadCount,
+			&thread.Metadata, &thread.FirstScrapedAt, &thread.LastScrapedAt,
+			&lastUpdatedAt, &thread.CreatedAt, &thread.Snippet,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan thread: %w", err)
+		}
+		thread.Author = author.String
+		thread.Category = category.String
+		thread.LastUpdatedAt = lastUpdatedAt.Time
+		thread.Snippet = markSearchMatches(thread.Snippet)
+		thread.UnmarshalMetadata()
+		threads = append(threads, thread)
+	}
+	return threads, total, rows.Err()
+}
+
 // GetPostsByThreadID retrieves all posts for a thread
 func (s *ScraperService) GetPostsByThreadID(threadID int64) ([]*models.ScrapedPost, error) {
 	rows, err := s.db.Query(`
@@ -1493,13 +1573,10 @@ func (s *ScraperService) LinkThreadToPerformer(threadID int64, performerID int64
 	return nil
 }
 
-// FindOrCreatePerformer finds an existing performer by name or creates a new one
+// FindOrCreatePerformer finds an existing performer by name or alias or creates a new one
 func (s *ScraperService) FindOrCreatePerformer(name string) (int64, error) {
-	// First, try to find existing performer (case-insensitive)
-	var performerID int64
-	err := s.db.QueryRow(`
-		SELECT id FROM performers WHERE LOWER(name) = LOWER(?)
-	`, name).Scan(&performerID)
+	// First, try to find existing performer (case and accent-insensitive)
+	performerID, err := findPerformerIDByName(s.db, name)
 
 	if err == nil {
 		// Found existing performer
@@ -1512,9 +1589,9 @@ func (s *ScraperService) FindOrCreatePerformer(name string) (int64, error) {
 
 	// Create new performer
 	result, err := s.db.Exec(`
-		INSERT INTO performers (name, category, metadata, created_at, updated_at)
-		VALUES (?, 'regular', '{}', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-	`, name)
+		INSERT INTO performers (name, normalized_name, category, metadata, created_at, updated_at)
+		VALUES (?, ?, 'regular', '{}', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, name, normalizePerformerName(name))
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to create performer: %w", err)
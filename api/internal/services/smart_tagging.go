@@ -130,6 +130,7 @@ func (s *AIService) SuggestTags(videoIDs []int64, autoApply bool, minConfidence
 	}
 
 	appliedCount := 0
+	var applied []models.OperationChange
 
 	for rows.Next() {
 		var videoID int64
@@ -165,10 +166,12 @@ func (s *AIService) SuggestTags(videoIDs []int64, autoApply bool, minConfidence
 		if autoApply {
 			for _, suggestion := range videoSuggestions.Suggestions {
 				if suggestion.Confidence >= minConfidence {
-					if err := s.addTagToVideo(videoID, suggestion.TagID); err != nil {
+					added, err := s.addTagToVideo(videoID, suggestion.TagID)
+					if err != nil {
 						log.Printf("Failed to auto-apply tag %d to video %d: %v", suggestion.TagID, videoID, err)
 					} else {
 						appliedCount++
+						applied = appendLinkChanges(applied, models.ChangeRowAdded, "video_tags", added)
 					}
 				}
 			}
@@ -188,6 +191,7 @@ func (s *AIService) SuggestTags(videoIDs []int64, autoApply bool, minConfidence
 		}
 	}
 
+	s.journal(models.OperationTagApply, fmt.Sprintf("Auto-applied %d suggested tags", len(applied)), applied)
 	log.Printf("Found suggestions for %d videos, auto-applied %d tags", len(suggestions), appliedCount)
 	return suggestions, nil
 }
@@ -333,28 +337,24 @@ func (s *AIService) getVideoTags(videoID int64) ([]models.Tag, error) {
 	return tags, nil
 }
 
-// addTagToVideo adds a tag to a video if it doesn't already exist
-func (s *AIService) addTagToVideo(videoID, tagID int64) error {
-	// Check if tag already exists
-	var exists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM video_tags WHERE video_id = ? AND tag_id = ?)", videoID, tagID).Scan(&exists)
-	if err != nil {
-		return err
-	}
-	if exists {
-		return nil // Tag already exists
-	}
-
-	_, err = s.db.Exec("INSERT INTO video_tags (video_id, tag_id) VALUES (?, ?)", videoID, tagID)
-	return err
+// addTagToVideo adds a tag and its ancestors to a video, returning the links
+// that weren't there yet
+func (s *AIService) addTagToVideo(videoID, tagID int64) ([]linkPair, error) {
+	return insertVideoTags(s.db, []linkPair{{videoID, tagID}})
 }
 
 // ApplyTagSuggestions applies selected tag suggestions to a video
 func (s *AIService) ApplyTagSuggestions(videoID int64, tagIDs []int64) error {
+	var applied []models.OperationChange
+	var applyErr error
 	for _, tagID := range tagIDs {
-		if err := s.addTagToVideo(videoID, tagID); err != nil {
-			return fmt.Errorf("failed to apply tag %d: %w", tagID, err)
+		added, err := s.addTagToVideo(videoID, tagID)
+		if err != nil {
+			applyErr = fmt.Errorf("failed to apply tag %d: %w", tagID, err)
+			break
 		}
+		applied = appendLinkChanges(applied, models.ChangeRowAdded, "video_tags", added)
 	}
-	return nil
+	s.journal(models.OperationTagApply, fmt.Sprintf("Applied %d tags to video %d", len(applied), videoID), applied)
+	return applyErr
 }
@@ -0,0 +1,373 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// ErrInvalidTag is returned when a tag's parents or aliases can't be saved,
+// such as a parent that would make the tag its own ancestor
+var ErrInvalidTag = errors.New("invalid tag")
+
+// tagHierarchy is every tag's parents and aliases
+type tagHierarchy struct {
+	parents map[int64][]int64
+	aliases map[int64][]string
+}
+
+// loadTagHierarchy reads every tag's parents and aliases
+func (s *TagService) loadTagHierarchy() (*tagHierarchy, error) {
+	hierarchy := &tagHierarchy{parents: make(map[int64][]int64), aliases: make(map[int64][]string)}
+
+	rows, err := s.db.Query(`SELECT tag_id, parent_id FROM tag_parents ORDER BY tag_id, parent_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag parents: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+	for rows.Next() {
+		var tagID, parentID int64
+		if err := rows.Scan(&tagID, &parentID); err != nil {
+			return nil, fmt.Errorf("failed to scan tag parent: %w", err)
+		}
+		hierarchy.parents[tagID] = append(hierarchy.parents[tagID], parentID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query tag parents: %w", err)
+	}
+
+	aliasRows, err := s.db.Query(`SELECT tag_id, alias FROM tag_aliases ORDER BY alias`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag aliases: %w", err)
+	}
+	defer func() {
+		if err := aliasRows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+	for aliasRows.Next() {
+		var tagID int64
+		var alias string
+		if err := aliasRows.Scan(&tagID, &alias); err != nil {
+			return nil, fmt.Errorf("failed to scan tag alias: %w", err)
+		}
+		hierarchy.aliases[tagID] = append(hierarchy.aliases[tagID], alias)
+	}
+	return hierarchy, aliasRows.Err()
+}
+
+// fill sets a tag's parents and aliases, as empty lists when it has none
+func (h *tagHierarchy) fill(tag *models.Tag) {
+	tag.ParentIDs = append([]int64{}, h.parents[tag.ID]...)
+	tag.Aliases = append([]string{}, h.aliases[tag.ID]...)
+}
+
+// resolveTagID finds a tag by name or alias, ignoring case. It returns 0 when
+// no tag answers to the name.
+func resolveTagID(q interface {
+	QueryRow(string, ...interface{}) *sql.Row
+}, name string) (int64, error) {
+	name = strings.TrimSpace(name)
+	var id int64
+	err := q.QueryRow(`
+		SELECT id FROM (
+			SELECT id, 0 AS rank FROM tags WHERE name = ? COLLATE NOCASE
+			UNION ALL
+			SELECT tag_id, 1 FROM tag_aliases WHERE alias = ?
+		) ORDER BY rank, id LIMIT 1
+	`, name, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve tag: %w", err)
+	}
+	return id, nil
+}
+
+// Resolve returns the tag with a name or alias
+func (s *TagService) Resolve(name string) (*models.Tag, error) {
+	id, err := resolveTagID(s.db, name)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("tag not found")
+	}
+	return s.GetByID(id)
+}
+
+// setTagParents replaces a tag's parents, refusing any that would make the tag
+// its own ancestor
+func setTagParents(tx *sql.Tx, tagID int64, parentIDs []int64) error {
+	seen := make(map[int64]bool, len(parentIDs))
+	var parents []int64
+	for _, parentID := range parentIDs {
+		if seen[parentID] {
+			continue
+		}
+		seen[parentID] = true
+		if parentID == tagID {
+			return fmt.Errorf("%w: a tag can't be its own parent", ErrInvalidTag)
+		}
+		var exists, cycle bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM tags WHERE id = ?)`, parentID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to query parent tag: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: parent tag %d not found", ErrInvalidTag, parentID)
+		}
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM tag_ancestors WHERE tag_id = ? AND ancestor_id = ?)`, parentID, tagID).Scan(&cycle); err != nil {
+			return fmt.Errorf("failed to check tag hierarchy: %w", err)
+		}
+		if cycle {
+			return fmt.Errorf("%w: tag %d is already below tag %d, so it can't be its parent", ErrInvalidTag, parentID, tagID)
+		}
+		parents = append(parents, parentID)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM tag_parents WHERE tag_id = ?`, tagID); err != nil {
+		return fmt.Errorf("failed to clear tag parents: %w", err)
+	}
+	for _, parentID := range parents {
+		if _, err := tx.Exec(`INSERT INTO tag_parents (tag_id, parent_id) VALUES (?, ?)`, tagID, parentID); err != nil {
+			return fmt.Errorf("failed to add tag parent: %w", err)
+		}
+	}
+	return rebuildTagAncestors(tx)
+}
+
+// setTagAliases replaces a tag's aliases. An alias can't be another tag's name
+// or alias.
+func setTagAliases(tx *sql.Tx, tagID int64, aliases []string) error {
+	if _, err := tx.Exec(`DELETE FROM tag_aliases WHERE tag_id = ?`, tagID); err != nil {
+		return fmt.Errorf("failed to clear tag aliases: %w", err)
+	}
+
+	seen := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		key := strings.ToLower(alias)
+		if alias == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		owner, err := resolveTagID(tx, alias)
+		if err != nil {
+			return err
+		}
+		if owner == tagID {
+			continue // The tag's own name
+		}
+		if owner != 0 {
+			return fmt.Errorf("%w: %q is already the name or an alias of tag %d", ErrInvalidTag, alias, owner)
+		}
+		if _, err := tx.Exec(`INSERT INTO tag_aliases (tag_id, alias, created_at) VALUES (?, ?, CURRENT_TIMESTAMP)`, tagID, alias); err != nil {
+			return fmt.Errorf("failed to add tag alias: %w", err)
+		}
+	}
+	return nil
+}
+
+// rebuildTagAncestors recomputes tag_ancestors from tag_parents. The union
+// stops at tags already reached, so it ends even if the parents hold a cycle.
+func rebuildTagAncestors(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM tag_ancestors`); err != nil {
+		return fmt.Errorf("failed to clear tag ancestors: %w", err)
+	}
+	if _, err := tx.Exec(`
+		WITH RECURSIVE chain(tag_id, ancestor_id) AS (
+			SELECT tag_id, parent_id FROM tag_parents
+			UNION
+			SELECT chain.tag_id, tag_parents.parent_id FROM chain JOIN tag_parents ON tag_parents.tag_id = chain.ancestor_id
+		)
+		INSERT INTO tag_ancestors (tag_id, ancestor_id) SELECT tag_id, ancestor_id FROM chain WHERE tag_id != ancestor_id
+	`); err != nil {
+		return fmt.Errorf("failed to rebuild tag ancestors: %w", err)
+	}
+	return nil
+}
+
+// removeTagHierarchy drops a deleted tag's parents, children and aliases
+func removeTagHierarchy(tx *sql.Tx, tagID int64) error {
+	if _, err := tx.Exec(`DELETE FROM tag_parents WHERE tag_id = ? OR parent_id = ?`, tagID, tagID); err != nil {
+		return fmt.Errorf("failed to delete tag parents: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM tag_aliases WHERE tag_id = ?`, tagID); err != nil {
+		return fmt.Errorf("failed to delete tag aliases: %w", err)
+	}
+	return rebuildTagAncestors(tx)
+}
+
+// mergeTagHierarchy hands a merged tag's aliases, parents and children to the
+// target tag, skipping links that would make the target its own ancestor, and
+// returns the changes
+func mergeTagHierarchy(tx *sql.Tx, sourceID, targetID int64) ([]models.OperationChange, error) {
+	var changes []models.OperationChange
+
+	aliasIDs, err := queryIDs(tx, `SELECT id FROM tag_aliases WHERE tag_id = ?`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag aliases: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE tag_aliases SET tag_id = ? WHERE tag_id = ?`, targetID, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to move tag aliases: %w", err)
+	}
+	for _, aliasID := range aliasIDs {
+		changes = append(changes, models.OperationChange{
+			Kind:  models.ChangeRowUpdated,
+			Table: "tag_aliases",
+			Row:   map[string]interface{}{"id": aliasID, "tag_id": sourceID},
+		})
+	}
+
+	parentIDs, err := queryIDs(tx, `SELECT parent_id FROM tag_parents WHERE tag_id = ?`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag parents: %w", err)
+	}
+	childIDs, err := queryIDs(tx, `SELECT tag_id FROM tag_parents WHERE parent_id = ?`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag children: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM tag_parents WHERE tag_id = ? OR parent_id = ?`, sourceID, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to delete tag parents: %w", err)
+	}
+	for _, parentID := range parentIDs {
+		changes = append(changes, linkChange(models.ChangeRowDeleted, "tag_parents", sourceID, parentID))
+	}
+	for _, childID := range childIDs {
+		changes = append(changes, linkChange(models.ChangeRowDeleted, "tag_parents", childID, sourceID))
+	}
+
+	// The target inherits the links, unless one would close a loop through it
+	var moved [][2]int64
+	for _, parentID := range parentIDs {
+		moved = append(moved, [2]int64{targetID, parentID})
+	}
+	for _, childID := range childIDs {
+		moved = append(moved, [2]int64{childID, targetID})
+	}
+	for _, link := range moved {
+		var loop bool
+		if err := tx.QueryRow(`
+			SELECT ? = ? OR EXISTS (SELECT 1 FROM tag_ancestors WHERE tag_id = ? AND ancestor_id = ?)
+		`, link[0], link[1], link[1], link[0]).Scan(&loop); err != nil {
+			return nil, fmt.Errorf("failed to check tag hierarchy: %w", err)
+		}
+		if loop {
+			continue
+		}
+		result, err := tx.Exec(`INSERT OR IGNORE INTO tag_parents (tag_id, parent_id) VALUES (?, ?)`, link[0], link[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to move tag parent: %w", err)
+		}
+		if added, err := result.RowsAffected(); err == nil && added > 0 {
+			changes = append(changes, linkChange(models.ChangeRowAdded, "tag_parents", link[0], link[1]))
+		}
+	}
+	return changes, rebuildTagAncestors(tx)
+}
+
+// GetTree returns the tag hierarchy from its roots, the tags without parents
+func (s *TagService) GetTree() ([]models.TagTreeNode, error) {
+	tags, err := s.GetAll(0)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int64][]int64)
+	byID := make(map[int64]models.TagWithCount, len(tags))
+	var roots []int64
+	for _, tag := range tags {
+		byID[tag.ID] = tag
+		if len(tag.ParentIDs) == 0 {
+			roots = append(roots, tag.ID)
+		}
+		for _, parentID := range tag.ParentIDs {
+			children[parentID] = append(children[parentID], tag.ID)
+		}
+	}
+
+	// Tags are sorted by name, so children and roots are too. The path guard
+	// keeps a cycle from recursing forever.
+	var build func(id int64, path map[int64]bool) models.TagTreeNode
+	build = func(id int64, path map[int64]bool) models.TagTreeNode {
+		node := models.TagTreeNode{TagWithCount: byID[id], Children: []models.TagTreeNode{}}
+		path[id] = true
+		for _, childID := range children[id] {
+			if _, ok := byID[childID]; ok && !path[childID] {
+				node.Children = append(node.Children, build(childID, path))
+			}
+		}
+		delete(path, id)
+		return node
+	}
+
+	tree := []models.TagTreeNode{}
+	for _, id := range roots {
+		tree = append(tree, build(id, make(map[int64]bool)))
+	}
+	return tree, nil
+}
+
+// tagQuerier is a database or transaction to read and write tags through
+type tagQuerier interface {
+	Exec(string, ...interface{}) (sql.Result, error)
+	Query(string, ...interface{}) (*sql.Rows, error)
+}
+
+// tagsWithAncestors returns tags followed by any of their ancestors not
+// already among them
+func tagsWithAncestors(q tagQuerier, tagIDs []int64) ([]int64, error) {
+	if len(tagIDs) == 0 {
+		return tagIDs, nil
+	}
+	ancestors, err := queryIDs(q, `SELECT DISTINCT ancestor_id FROM tag_ancestors WHERE tag_id IN (?`+strings.Repeat(",?", len(tagIDs)-1)+`) ORDER BY ancestor_id`, idArgs(tagIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag ancestors: %w", err)
+	}
+	expanded := append([]int64{}, tagIDs...)
+	for _, ancestorID := range ancestors {
+		if !slices.Contains(expanded, ancestorID) {
+			expanded = append(expanded, ancestorID)
+		}
+	}
+	return expanded, nil
+}
+
+// insertVideoTags tags videos, along with the ancestors of each tag, and
+// returns the links it added. Links a video already has are skipped, so the
+// result is exactly what undo has to remove.
+func insertVideoTags(q tagQuerier, pairs []linkPair) ([]linkPair, error) {
+	var added []linkPair
+	ancestors := make(map[int64][]int64)
+	for _, pair := range pairs {
+		tagIDs, ok := ancestors[pair.refID]
+		if !ok {
+			var err error
+			if tagIDs, err = tagsWithAncestors(q, []int64{pair.refID}); err != nil {
+				return nil, err
+			}
+			ancestors[pair.refID] = tagIDs
+		}
+		for _, tagID := range tagIDs {
+			result, err := q.Exec(`INSERT OR IGNORE INTO video_tags (video_id, tag_id) VALUES (?, ?)`, pair.videoID, tagID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to tag video %d: %w", pair.videoID, err)
+			}
+			if inserted, err := result.RowsAffected(); err == nil && inserted > 0 {
+				added = append(added, linkPair{pair.videoID, tagID})
+			}
+		}
+	}
+	return added, nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/brixen96/video-storage-ai/internal/database"
@@ -22,18 +23,31 @@ func NewTagService() *TagService {
 	}
 }
 
-// GetAll retrieves all tags with video counts
-func (s *TagService) GetAll() ([]models.TagWithCount, error) {
+// GetAll retrieves all tags with video counts. A parent ID limits them to the
+// parent's descendants.
+func (s *TagService) GetAll(parentID int64) ([]models.TagWithCount, error) {
+	where := ""
+	var args []interface{}
+	if parentID > 0 {
+		where = "WHERE t.id IN (SELECT tag_id FROM tag_ancestors WHERE ancestor_id = ?)"
+		args = append(args, parentID)
+	}
 	query := `
 		SELECT t.id, t.name, t.color, t.icon, t.category, t.created_at, t.updated_at,
 		       COALESCE(COUNT(vt.video_id), 0) as video_count
 		FROM tags t
 		LEFT JOIN video_tags vt ON t.id = vt.tag_id
+		` + where + `
 		GROUP BY t.id
 		ORDER BY t.name ASC
 	`
 
-	rows, err := s.db.Query(query)
+	hierarchy, err := s.loadTagHierarchy()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tags: %w", err)
 	}
@@ -50,6 +64,7 @@ func (s *TagService) GetAll() ([]models.TagWithCount, error) {
 			log.Printf("Failed to scan tag: %v", err)
 			continue
 		}
+		hierarchy.fill(&tag.Tag)
 		tags = append(tags, tag)
 	}
 
@@ -73,11 +88,27 @@ func (s *TagService) GetByID(id int64) (*models.Tag, error) {
 		return nil, fmt.Errorf("failed to get tag: %w", err)
 	}
 
+	hierarchy, err := s.loadTagHierarchy()
+	if err != nil {
+		return nil, err
+	}
+	hierarchy.fill(&tag)
+
 	return &tag, nil
 }
 
-// Create creates a new tag
+// Create creates a new tag. A name that is already another tag's alias
+// returns that tag instead.
 func (s *TagService) Create(create *models.TagCreate) (*models.Tag, error) {
+	var aliasOf int64
+	err := s.db.QueryRow(`SELECT tag_id FROM tag_aliases WHERE alias = ?`, strings.TrimSpace(create.Name)).Scan(&aliasOf)
+	if err == nil {
+		return s.GetByID(aliasOf)
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to resolve tag alias: %w", err)
+	}
+
 	now := time.Now()
 
 	// Default to 'regular' if category is empty
@@ -86,8 +117,18 @@ func (s *TagService) Create(create *models.TagCreate) (*models.Tag, error) {
 		category = "regular"
 	}
 
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back tag create: %v", err)
+		}
+	}()
+
 	query := `INSERT INTO tags (name, color, icon, category, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
-	result, err := s.db.Exec(query, create.Name, create.Color, create.Icon, category, now, now)
+	result, err := tx.Exec(query, create.Name, create.Color, create.Icon, category, now, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tag: %w", err)
 	}
@@ -97,6 +138,16 @@ func (s *TagService) Create(create *models.TagCreate) (*models.Tag, error) {
 		return nil, fmt.Errorf("failed to get last insert id: %w", err)
 	}
 
+	if err := setTagParents(tx, id, create.ParentIDs); err != nil {
+		return nil, err
+	}
+	if err := setTagAliases(tx, id, create.Aliases); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tag create: %w", err)
+	}
+
 	return s.GetByID(id)
 }
 
@@ -124,25 +175,68 @@ func (s *TagService) Update(id int64, update *models.TagUpdate) (*models.Tag, er
 
 	tag.UpdatedAt = time.Now()
 
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back tag update: %v", err)
+		}
+	}()
+
+	var aliasOf int64
+	err = tx.QueryRow(`SELECT tag_id FROM tag_aliases WHERE alias = ? AND tag_id != ?`, strings.TrimSpace(tag.Name), id).Scan(&aliasOf)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %q is an alias of tag %d", ErrInvalidTag, tag.Name, aliasOf)
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to resolve tag alias: %w", err)
+	}
+
 	query := `UPDATE tags SET name = ?, color = ?, icon = ?, category = ?, updated_at = ? WHERE id = ?`
-	_, err = s.db.Exec(query, tag.Name, tag.Color, tag.Icon, tag.Category, tag.UpdatedAt, id)
+	_, err = tx.Exec(query, tag.Name, tag.Color, tag.Icon, tag.Category, tag.UpdatedAt, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update tag: %w", err)
 	}
 
-	return tag, nil
+	if update.ParentIDs != nil {
+		if err := setTagParents(tx, id, update.ParentIDs); err != nil {
+			return nil, err
+		}
+	}
+	if update.Aliases != nil {
+		if err := setTagAliases(tx, id, update.Aliases); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tag update: %w", err)
+	}
+
+	return s.GetByID(id)
 }
 
-// Delete deletes a tag
+// Delete deletes a tag along with its aliases and its place in the hierarchy
 func (s *TagService) Delete(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back tag delete: %v", err)
+		}
+	}()
+
 	// First, remove all video-tag associations
-	_, err := s.db.Exec(`DELETE FROM video_tags WHERE tag_id = ?`, id)
+	_, err = tx.Exec(`DELETE FROM video_tags WHERE tag_id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete video-tag associations: %w", err)
 	}
 
 	// Then delete the tag
-	result, err := s.db.Exec(`DELETE FROM tags WHERE id = ?`, id)
+	result, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete tag: %w", err)
 	}
@@ -156,40 +250,114 @@ func (s *TagService) Delete(id int64) error {
 		return fmt.Errorf("tag not found")
 	}
 
+	if err := removeTagHierarchy(tx, id); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tag delete: %w", err)
+	}
+
 	return nil
 }
 
-// Merge merges multiple source tags into a target tag
+// Merge merges multiple source tags into a target tag. The merge runs in one
+// transaction and is journaled so it can be undone.
 func (s *TagService) Merge(request *models.TagMergeRequest) error {
 	// Verify target tag exists
-	_, err := s.GetByID(request.TargetTagID)
+	target, err := s.GetByID(request.TargetTagID)
 	if err != nil {
 		return fmt.Errorf("target tag not found: %w", err)
 	}
 
-	// For each source tag
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back tag merge: %v", err)
+		}
+	}()
+
+	var changes []models.OperationChange
+	var merged []string
 	for _, sourceID := range request.SourceTagIDs {
 		if sourceID == request.TargetTagID {
 			continue // Skip if source is same as target
 		}
 
-		// Move all video associations to target tag
-		query := `
-			INSERT OR IGNORE INTO video_tags (video_id, tag_id)
-			SELECT video_id, ? FROM video_tags WHERE tag_id = ?
-		`
-		_, err := s.db.Exec(query, request.TargetTagID, sourceID)
+		sourceChanges, name, err := mergeTag(tx, sourceID, target.ID)
 		if err != nil {
-			log.Printf("Failed to merge tag %d: %v", sourceID, err)
-			continue
+			return fmt.Errorf("failed to merge tag %d: %w", sourceID, err)
 		}
-
-		// Delete the source tag
-		err = s.Delete(sourceID)
-		if err != nil {
-			log.Printf("Failed to delete source tag %d: %v", sourceID, err)
+		if sourceChanges == nil {
+			log.Printf("Skipped merging tag %d: tag not found", sourceID)
+			continue
 		}
+		changes = append(changes, sourceChanges...)
+		merged = append(merged, name)
 	}
 
+	summary := fmt.Sprintf("Merged %s into %s", strings.Join(merged, ", "), target.Name)
+	if _, err := journalOperation(tx, models.OperationTagMerge, summary, changes); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tag merge: %w", err)
+	}
 	return nil
 }
+
+// mergeTag moves a source tag's videos, aliases, parents and children to the
+// target tag and deletes the source, returning the changes and the source's
+// name. It returns no changes when the source tag doesn't exist.
+func mergeTag(tx *sql.Tx, sourceID, targetID int64) ([]models.OperationChange, string, error) {
+	tag, err := snapshotRow(tx, "tags", sourceID)
+	if err != nil || tag == nil {
+		return nil, "", err
+	}
+
+	videoIDs, err := queryIDs(tx, `SELECT video_id FROM video_tags WHERE tag_id = ?`, sourceID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query tagged videos: %w", err)
+	}
+	performerIDs, err := queryIDs(tx, `SELECT performer_id FROM performer_tags WHERE tag_id = ?`, sourceID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query tagged performers: %w", err)
+	}
+
+	// Only links the videos didn't have yet are added, so undo leaves the others
+	targetLinks := make([]linkPair, len(videoIDs))
+	for i, videoID := range videoIDs {
+		targetLinks[i] = linkPair{videoID, targetID}
+	}
+	added, err := insertVideoTags(tx, targetLinks)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to move video associations: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM video_tags WHERE tag_id = ?`, sourceID); err != nil {
+		return nil, "", fmt.Errorf("failed to delete video-tag associations: %w", err)
+	}
+	hierarchyChanges, err := mergeTagHierarchy(tx, sourceID, targetID)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, sourceID); err != nil {
+		return nil, "", fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	// Undo replays these backwards: the tag comes back before its links
+	var changes []models.OperationChange
+	changes = appendLinkChanges(changes, models.ChangeRowAdded, "video_tags", added)
+	for _, videoID := range videoIDs {
+		changes = append(changes, linkChange(models.ChangeRowDeleted, "video_tags", videoID, sourceID))
+	}
+	for _, performerID := range performerIDs {
+		changes = append(changes, linkChange(models.ChangeRowDeleted, "performer_tags", performerID, sourceID))
+	}
+	changes = append(changes, hierarchyChanges...)
+	changes = append(changes, models.OperationChange{Kind: models.ChangeRowDeleted, Table: "tags", Row: tag})
+
+	name, _ := tag["name"].(string)
+	return changes, name, nil
+}
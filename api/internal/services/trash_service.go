@@ -0,0 +1,712 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// trashDirName is the folder at each library root that holds deleted files.
+// Scans and the file watcher never look inside it.
+const trashDirName = ".trash"
+
+// trashSettingsKey is the app_settings key holding the trash settings
+const trashSettingsKey = "trash_settings"
+
+// defaultTrashSettings apply until the trash is configured
+var defaultTrashSettings = models.TrashSettings{RetentionDays: 30}
+
+// trashLinkTables maps each table linking a video to other records to its linked ID column
+var trashLinkTables = map[string]string{
+	"video_performers": "performer_id",
+	"video_tags":       "tag_id",
+	"video_studios":    "studio_id",
+	"video_groups":     "group_id",
+}
+
+// trashSnapshot is everything needed to put a deleted video back: its row, as
+// column values, and its links to performers, tags, studios, groups and playlists
+type trashSnapshot struct {
+	Video     map[string]interface{} `json:"video"`
+	Links     map[string][]int64     `json:"links,omitempty"`
+	Playlists []trashPlaylistItem    `json:"playlists,omitempty"`
+}
+
+// trashPlaylistItem is a deleted video's place in a playlist
+type trashPlaylistItem struct {
+	PlaylistID int64     `json:"playlist_id"`
+	Position   int       `json:"position"`
+	AddedAt    time.Time `json:"added_at"`
+}
+
+// TrashService moves deleted files and videos into a recycle bin they can be
+// restored from until they're purged
+type TrashService struct {
+	db             *sql.DB
+	libraryService *LibraryService
+	mediaService   *MediaService
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService() *TrashService {
+	return &TrashService{
+		db:             database.GetDB(),
+		libraryService: NewLibraryService(),
+		mediaService:   NewMediaService(),
+	}
+}
+
+// GetSettings returns the trash settings
+func (s *TrashService) GetSettings() (*models.TrashSettings, error) {
+	settings := defaultTrashSettings
+
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM app_settings WHERE key = ?`, trashSettingsKey).Scan(&value)
+	if err == sql.ErrNoRows {
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trash settings: %w", err)
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse trash settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateSettings validates and stores new trash settings
+func (s *TrashService) UpdateSettings(update *models.TrashSettingsUpdate) (*models.TrashSettings, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	if update.RetentionDays != nil {
+		if *update.RetentionDays < 0 {
+			return nil, fmt.Errorf("retention_days must not be negative")
+		}
+		settings.RetentionDays = *update.RetentionDays
+	}
+
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode trash settings: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO app_settings (key, value, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, trashSettingsKey, string(value), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to save trash settings: %w", err)
+	}
+	return settings, nil
+}
+
+// TrashVideo moves a video's file into the trash and removes the video, keeping
+// enough to restore both. A video whose file is already gone is trashed on its own.
+// A video without a library has no trash to go to, so its row is just deleted and
+// a nil item returned.
+func (s *TrashService) TrashVideo(videoID int64) (*models.TrashItem, error) {
+	var libraryID int64
+	var filePath string
+	err := s.db.QueryRow(`SELECT COALESCE(library_id, 0), file_path FROM videos WHERE id = ?`, videoID).Scan(&libraryID, &filePath)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("video not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query video: %w", err)
+	}
+
+	if libraryID == 0 {
+		if _, err := s.db.Exec(`DELETE FROM videos WHERE id = ?`, videoID); err != nil {
+			return nil, fmt.Errorf("failed to delete video: %w", err)
+		}
+		return nil, nil
+	}
+
+	library, err := s.libraryService.GetByID(libraryID)
+	if err != nil {
+		return nil, fmt.Errorf("library not found: %w", err)
+	}
+	return s.trash(library, filePath, videoID)
+}
+
+// TrashFile moves a file into its library's trash, along with its video if it has one
+func (s *TrashService) TrashFile(library *models.Library, fullPath string) (*models.TrashItem, error) {
+	var videoID int64
+	err := s.db.QueryRow(`SELECT id FROM videos WHERE file_path = ?`, fullPath).Scan(&videoID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to query video: %w", err)
+	}
+	return s.trash(library, fullPath, videoID)
+}
+
+// trash records a trash item, deletes the video if there is one, and moves the
+// file into .trash. The file goes back if the database changes can't be committed.
+func (s *TrashService) trash(library *models.Library, fullPath string, videoID int64) (*models.TrashItem, error) {
+	// A missing file on an unmounted drive would otherwise look like a file that's gone
+	if err := s.libraryService.EnsureOnline(library); err != nil {
+		return nil, err
+	}
+
+	var fileSize int64
+	info, err := os.Stat(fullPath)
+	exists := err == nil
+	if exists {
+		if info.IsDir() {
+			return nil, fmt.Errorf("cannot trash directories")
+		}
+		fileSize = info.Size()
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back trash: %v", err)
+		}
+	}()
+
+	var snapshot []byte
+	var title string
+	var trashedVideoID *int64
+	if videoID != 0 {
+		snap, err := snapshotVideo(tx, videoID)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot, err = json.Marshal(snap); err != nil {
+			return nil, fmt.Errorf("failed to encode video snapshot: %w", err)
+		}
+		title, _ = snap.Video["title"].(string)
+		trashedVideoID = &videoID
+	}
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		INSERT INTO trash_items (library_id, original_path, file_size, video_id, title, snapshot, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, library.ID, fullPath, fileSize, trashedVideoID, title, string(snapshot), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trash item: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if videoID != 0 {
+		if _, err := tx.Exec(`DELETE FROM videos WHERE id = ?`, videoID); err != nil {
+			return nil, fmt.Errorf("failed to delete video: %w", err)
+		}
+	}
+
+	var trashPath string
+	if exists {
+		trashPath = filepath.Join(library.Path, trashDirName, fmt.Sprintf("%d-%s", id, filepath.Base(fullPath)))
+		if _, err := tx.Exec(`UPDATE trash_items SET trash_path = ? WHERE id = ?`, trashPath, id); err != nil {
+			return nil, fmt.Errorf("failed to update trash item: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(trashPath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create trash directory: %w", err)
+		}
+		if err := movePath(fullPath, trashPath, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if exists {
+			if undoErr := movePath(trashPath, fullPath, false); undoErr != nil {
+				log.Printf("Failed to move %s back out of the trash: %v", fullPath, undoErr)
+			}
+		}
+		return nil, fmt.Errorf("failed to commit trash: %w", err)
+	}
+
+	log.Printf("Moved %s to the trash", fullPath)
+	return s.GetByID(id)
+}
+
+// snapshotVideo captures a video row and its links before it's deleted
+func snapshotVideo(tx *sql.Tx, videoID int64) (*trashSnapshot, error) {
+	video, err := snapshotRow(tx, "videos", videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video == nil {
+		return nil, fmt.Errorf("video not found")
+	}
+
+	snapshot := &trashSnapshot{Video: video, Links: make(map[string][]int64)}
+	for table, column := range trashLinkTables {
+		ids, err := queryIDs(tx, fmt.Sprintf(`SELECT %s FROM %s WHERE video_id = ?`, column, table), videoID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %w", table, err)
+		}
+		if len(ids) > 0 {
+			snapshot.Links[table] = ids
+		}
+	}
+
+	playlistRows, err := tx.Query(`SELECT playlist_id, position, added_at FROM playlist_items WHERE video_id = ?`, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot playlists: %w", err)
+	}
+	defer func() {
+		if err := playlistRows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+	for playlistRows.Next() {
+		var item trashPlaylistItem
+		if err := playlistRows.Scan(&item.PlaylistID, &item.Position, &item.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan playlist item: %w", err)
+		}
+		snapshot.Playlists = append(snapshot.Playlists, item)
+	}
+	return snapshot, playlistRows.Err()
+}
+
+// snapshotRow captures a row by ID as a column-to-value map, or nil when there's
+// no such row
+func snapshotRow(tx *sql.Tx, table string, id int64) (map[string]interface{}, error) {
+	rows, err := tx.Query(fmt.Sprintf(`SELECT * FROM %s WHERE id = ?`, table), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", table, err)
+		}
+		return nil, nil
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, fmt.Errorf("failed to scan %s row: %w", table, err)
+	}
+
+	row := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if b, ok := values[i].([]byte); ok {
+			values[i] = string(b)
+		}
+		row[column] = values[i]
+	}
+	return row, nil
+}
+
+// tableColumns returns the names of a table's columns
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	return columns, nil
+}
+
+// queryIDs runs a query selecting one integer column, on the database or in a transaction
+func queryIDs(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, query string, args ...interface{}) ([]int64, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// trashItemColumns lists the columns scanTrashItem expects, in order
+const trashItemColumns = `id, library_id, original_path, COALESCE(trash_path, ''), COALESCE(file_size, 0), video_id, COALESCE(title, ''), deleted_at`
+
+// scanTrashItem reads a trash item selected with trashItemColumns
+func scanTrashItem(row interface{ Scan(...interface{}) error }) (*models.TrashItem, error) {
+	var item models.TrashItem
+	var videoID sql.NullInt64
+	err := row.Scan(&item.ID, &item.LibraryID, &item.OriginalPath, &item.TrashPath, &item.FileSize, &videoID, &item.Title, &item.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if videoID.Valid {
+		item.VideoID = &videoID.Int64
+	}
+	return &item, nil
+}
+
+// setExpiry fills in when the purge job will remove an item
+func setExpiry(item *models.TrashItem, settings *models.TrashSettings) {
+	if settings.RetentionDays > 0 {
+		expires := item.DeletedAt.AddDate(0, 0, settings.RetentionDays)
+		item.ExpiresAt = &expires
+	}
+}
+
+// GetAll lists trash items, newest first. libraryID 0 lists every library.
+func (s *TrashService) GetAll(libraryID int64) ([]models.TrashItem, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + trashItemColumns + ` FROM trash_items`
+	var args []interface{}
+	if libraryID != 0 {
+		query += ` WHERE library_id = ?`
+		args = append(args, libraryID)
+	}
+	rows, err := s.db.Query(query+` ORDER BY deleted_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	items := []models.TrashItem{}
+	for rows.Next() {
+		item, err := scanTrashItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trash item: %w", err)
+		}
+		setExpiry(item, settings)
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// GetByID retrieves a trash item
+func (s *TrashService) GetByID(id int64) (*models.TrashItem, error) {
+	item, err := scanTrashItem(s.db.QueryRow(`SELECT `+trashItemColumns+` FROM trash_items WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("trash item not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash item: %w", err)
+	}
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	setExpiry(item, settings)
+	return item, nil
+}
+
+// loadSnapshot reads a trash item's video snapshot; nil when no video was trashed
+func (s *TrashService) loadSnapshot(id int64) (*trashSnapshot, error) {
+	var raw string
+	if err := s.db.QueryRow(`SELECT COALESCE(snapshot, '') FROM trash_items WHERE id = ?`, id).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("failed to query trash item: %w", err)
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	// Numbers stay exact so IDs and sizes survive the round trip
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.UseNumber()
+	var snapshot trashSnapshot
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse video snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Restore moves a trashed file back to where it was and brings back its video
+// with its metadata and links. Links to records deleted in the meantime are dropped.
+// It returns the restored video's ID, or 0 when only a file was trashed.
+func (s *TrashService) Restore(id int64) (int64, error) {
+	item, err := s.GetByID(id)
+	if err != nil {
+		return 0, err
+	}
+	snapshot, err := s.loadSnapshot(id)
+	if err != nil {
+		return 0, err
+	}
+	library, err := s.libraryService.GetByID(item.LibraryID)
+	if err != nil {
+		return 0, fmt.Errorf("library not found: %w", err)
+	}
+	if err := s.libraryService.EnsureOnline(library); err != nil {
+		return 0, err
+	}
+	if item.TrashPath != "" {
+		if _, err := os.Stat(item.TrashPath); err != nil {
+			return 0, fmt.Errorf("trashed file is gone: %w", err)
+		}
+		if _, err := os.Stat(item.OriginalPath); err == nil {
+			return 0, fmt.Errorf("a file already exists at %s", item.OriginalPath)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back restore: %v", err)
+		}
+	}()
+
+	var videoID int64
+	if snapshot != nil {
+		if videoID, err = restoreVideo(tx, snapshot, item.TrashPath != ""); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM trash_items WHERE id = ?`, id); err != nil {
+		return 0, fmt.Errorf("failed to delete trash item: %w", err)
+	}
+
+	if item.TrashPath != "" {
+		if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0755); err != nil {
+			return 0, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := movePath(item.TrashPath, item.OriginalPath, false); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if item.TrashPath != "" {
+			if undoErr := movePath(item.OriginalPath, item.TrashPath, false); undoErr != nil {
+				log.Printf("Failed to move %s back into the trash: %v", item.OriginalPath, undoErr)
+			}
+		}
+		return 0, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	log.Printf("Restored %s from the trash", item.OriginalPath)
+	return videoID, nil
+}
+
+// restoreVideo inserts a snapshotted video row and its links. The video keeps
+// its old ID unless something has taken it.
+func restoreVideo(tx *sql.Tx, snapshot *trashSnapshot, hasFile bool) (int64, error) {
+	// Only columns the table still has; the schema may have changed since
+	existing, err := tableColumns(tx, "videos")
+	if err != nil {
+		return 0, err
+	}
+
+	video := snapshot.Video
+	if id, ok := video["id"]; ok {
+		var taken bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM videos WHERE id = ?)`, snapshotValue(id)).Scan(&taken); err != nil {
+			return 0, fmt.Errorf("failed to check video ID: %w", err)
+		}
+		if taken {
+			delete(video, "id")
+		}
+	}
+	if hasFile {
+		video["status"] = models.VideoStatusAvailable
+		video["missing_since"] = nil
+	}
+
+	var columns []string
+	for column := range video {
+		if existing[column] {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		args[i] = snapshotValue(video[column])
+	}
+
+	result, err := tx.Exec(fmt.Sprintf(`INSERT INTO videos (%s) VALUES (?%s)`,
+		strings.Join(columns, ", "), strings.Repeat(", ?", len(columns)-1)), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore video: %w", err)
+	}
+	videoID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	// A failed insert here is a link to a record that's been deleted; SQLite only
+	// undoes that statement, so the rest of the restore carries on
+	for table, ids := range snapshot.Links {
+		column, ok := trashLinkTables[table]
+		if !ok {
+			continue
+		}
+		for _, linkedID := range ids {
+			if _, err := tx.Exec(fmt.Sprintf(`INSERT OR IGNORE INTO %s (video_id, %s) VALUES (?, ?)`, table, column), videoID, linkedID); err != nil {
+				log.Printf("Skipped restoring %s %d for video %d: %v", column, linkedID, videoID, err)
+			}
+		}
+	}
+
+	for _, item := range snapshot.Playlists {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM playlists WHERE id = ?)`, item.PlaylistID).Scan(&exists); err != nil {
+			return 0, fmt.Errorf("failed to query playlist: %w", err)
+		}
+		if !exists {
+			continue
+		}
+		var count int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM playlist_items WHERE playlist_id = ?`, item.PlaylistID).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to query playlist: %w", err)
+		}
+		position := item.Position
+		if position > count {
+			position = count
+		}
+		if _, err := tx.Exec(`UPDATE playlist_items SET position = position + 1 WHERE playlist_id = ? AND position >= ?`,
+			item.PlaylistID, position); err != nil {
+			return 0, fmt.Errorf("failed to shift playlist items: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO playlist_items (playlist_id, video_id, position, added_at) VALUES (?, ?, ?, ?)`,
+			item.PlaylistID, videoID, position, item.AddedAt); err != nil {
+			return 0, fmt.Errorf("failed to restore playlist item: %w", err)
+		}
+	}
+
+	return videoID, nil
+}
+
+// snapshotValue turns a decoded snapshot value back into a database value
+func snapshotValue(value interface{}) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return number.String()
+}
+
+// Delete permanently removes a trash item's file and the thumbnail and preview
+// of its video
+func (s *TrashService) Delete(id int64) error {
+	item, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	snapshot, err := s.loadSnapshot(id)
+	if err != nil {
+		return err
+	}
+
+	if item.TrashPath != "" {
+		// An unmounted drive would make the file look already gone
+		library, err := s.libraryService.GetByID(item.LibraryID)
+		if err == nil {
+			err = s.libraryService.EnsureOnline(library)
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(item.TrashPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+	}
+	if snapshot != nil {
+		thumbnailPath, _ := snapshot.Video["thumbnail_path"].(string)
+		previewPath, _ := snapshot.Video["preview_path"].(string)
+		s.mediaService.RemoveVideoAssets(thumbnailPath, previewPath)
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM trash_items WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete trash item: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpired permanently deletes items older than the retention period and
+// returns how many went
+func (s *TrashService) PurgeExpired() (int, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return 0, err
+	}
+	if settings.RetentionDays <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -settings.RetentionDays)
+	ids, err := queryIDs(s.db, `SELECT id FROM trash_items WHERE deleted_at < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query expired trash: %w", err)
+	}
+
+	purged := 0
+	for _, id := range ids {
+		// Items on an unmounted drive are retried on a later run
+		if err := s.Delete(id); err != nil {
+			log.Printf("Failed to purge trash item %d: %v", id, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,505 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// maxBulkVideos caps how many videos one bulk edit may change
+const maxBulkVideos = 5000
+
+// bulkLinkTable is a relationship a bulk edit can change
+type bulkLinkTable struct {
+	field    string // Key in the result counts
+	name     string // Linked record, for errors
+	table    string
+	column   string
+	refTable string
+}
+
+// bulkLinkTables are applied in this order. Performers come before tags, as in
+// Update, so added performers' master tags are in place before tags are set.
+var bulkLinkTables = []bulkLinkTable{
+	{field: "studios", name: "studio", table: "video_studios", column: "studio_id", refTable: "studios"},
+	{field: "groups", name: "group", table: "video_groups", column: "group_id", refTable: "groups"},
+	{field: "performers", name: "performer", table: "video_performers", column: "performer_id", refTable: "performers"},
+	{field: "tags", name: "tag", table: "video_tags", column: "tag_id", refTable: "tags"},
+}
+
+// linkPair is a row of a video link table
+type linkPair struct {
+	videoID int64
+	refID   int64
+}
+
+// idArgs turns IDs into query arguments
+func idArgs(ids []int64) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+// BulkUpdate applies the same changes to every selected video in one
+// transaction, and journals them so the edit can be undone. Performers' video
+// counts are recomputed once at the end.
+func (s *VideoService) BulkUpdate(req *models.VideoBulkUpdate) (*models.VideoBulkResult, error) {
+	result := &models.VideoBulkResult{NotFound: []int64{}, Updated: make(map[string]int)}
+	links := map[string]*models.VideoBulkLinks{
+		"studios":    req.Studio,
+		"groups":     req.Group,
+		"performers": req.Performers,
+		"tags":       req.Tags,
+	}
+	for _, link := range bulkLinkTables {
+		if err := s.validateBulkLinks(link, links[link.field]); err != nil {
+			return nil, err
+		}
+	}
+
+	ids, err := s.selectBulkVideos(req, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Matched = len(ids)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to roll back bulk video update: %v", err)
+		}
+	}()
+
+	changed := make(map[int64]bool)
+	changes, err := bulkUpdateFields(tx, req, ids, result, changed)
+	if err != nil {
+		return nil, err
+	}
+
+	performers := make(map[int64]bool)
+	for _, link := range bulkLinkTables {
+		change := links[link.field]
+		if change == nil {
+			continue
+		}
+		added, removed, err := bulkUpdateLinks(tx, link, change, ids)
+		if err != nil {
+			return nil, err
+		}
+		if link.table == "video_performers" {
+			// Added performers bring their master tags, as in Update
+			masterTags, err := applyMasterTags(tx, added)
+			if err != nil {
+				return nil, err
+			}
+			changes = appendLinkChanges(changes, models.ChangeRowAdded, "video_tags", masterTags)
+			result.Updated["tags_added"] += len(masterTags)
+			for _, pair := range append(added, removed...) {
+				performers[pair.refID] = true
+			}
+		}
+		changes = appendLinkChanges(changes, models.ChangeRowDeleted, link.table, removed)
+		changes = appendLinkChanges(changes, models.ChangeRowAdded, link.table, added)
+		result.Updated[link.field+"_added"] += len(added)
+		result.Updated[link.field+"_removed"] += len(removed)
+		for _, pair := range append(added, removed...) {
+			changed[pair.videoID] = true
+		}
+	}
+
+	if len(performers) > 0 {
+		performerIDs := make([]int64, 0, len(performers))
+		for id := range performers {
+			performerIDs = append(performerIDs, id)
+		}
+		if _, err := tx.Exec(`
+			UPDATE performers SET video_count = (SELECT COUNT(*) FROM video_performers WHERE performer_id = performers.id)
+			WHERE id IN (?`+strings.Repeat(",?", len(performerIDs)-1)+`)
+		`, idArgs(performerIDs)...); err != nil {
+			return nil, fmt.Errorf("failed to update performer video counts: %w", err)
+		}
+	}
+
+	if len(changed) > 0 {
+		changedIDs := make([]int64, 0, len(changed))
+		for id := range changed {
+			changedIDs = append(changedIDs, id)
+		}
+		args := append([]interface{}{time.Now()}, idArgs(changedIDs)...)
+		if _, err := tx.Exec(`UPDATE videos SET updated_at = ? WHERE id IN (?`+strings.Repeat(",?", len(changedIDs)-1)+`)`, args...); err != nil {
+			return nil, fmt.Errorf("failed to update videos: %w", err)
+		}
+	}
+
+	summary := fmt.Sprintf("Bulk edited %d videos", len(changed))
+	if result.OperationID, err = journalOperation(tx, models.OperationVideoBulkEdit, summary, changes); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk video update: %w", err)
+	}
+	return result, nil
+}
+
+// validateBulkLinks checks that a relationship's changes don't contradict each
+// other and that the records to link exist
+func (s *VideoService) validateBulkLinks(link bulkLinkTable, change *models.VideoBulkLinks) error {
+	if change == nil {
+		return nil
+	}
+	if change.Set != nil && (len(change.Add) > 0 || len(change.Remove) > 0) {
+		return fmt.Errorf("%s: set can't be combined with add or remove", link.field)
+	}
+	removing := make(map[int64]bool, len(change.Remove))
+	for _, id := range change.Remove {
+		removing[id] = true
+	}
+	for _, id := range change.Add {
+		if removing[id] {
+			return fmt.Errorf("%s %d is both added and removed", link.name, id)
+		}
+	}
+
+	linking := append(append([]int64{}, change.Add...), change.Set...)
+	if len(linking) == 0 {
+		return nil
+	}
+	found, err := queryIDs(s.db, fmt.Sprintf(`SELECT id FROM %s WHERE id IN (?%s)`, link.refTable, strings.Repeat(",?", len(linking)-1)), idArgs(linking)...)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", link.refTable, err)
+	}
+	exists := make(map[int64]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+	for _, id := range linking {
+		if !exists[id] {
+			return fmt.Errorf("%s %d not found", link.name, id)
+		}
+	}
+	return nil
+}
+
+// selectBulkVideos resolves a bulk edit's listed IDs and search into unique
+// video IDs, noting listed videos that don't exist
+func (s *VideoService) selectBulkVideos(req *models.VideoBulkUpdate, result *models.VideoBulkResult) ([]int64, error) {
+	if len(req.VideoIDs) == 0 && req.Query == nil {
+		return nil, fmt.Errorf("video_ids or query is required")
+	}
+	if len(req.VideoIDs) > maxBulkVideos {
+		return nil, fmt.Errorf("at most %d videos can be edited at once", maxBulkVideos)
+	}
+
+	var ids []int64
+	if len(req.VideoIDs) > 0 {
+		found, err := queryIDs(s.db, `SELECT id FROM videos WHERE id IN (?`+strings.Repeat(",?", len(req.VideoIDs)-1)+`)`, idArgs(req.VideoIDs)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query videos: %w", err)
+		}
+		exists := make(map[int64]bool, len(found))
+		for _, id := range found {
+			exists[id] = true
+		}
+		for _, id := range req.VideoIDs {
+			if exists[id] {
+				ids = append(ids, id)
+			} else {
+				result.NotFound = append(result.NotFound, id)
+			}
+		}
+	}
+	if req.Query != nil {
+		matches, err := s.SearchIDs(req.Query, maxBulkVideos+1)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, matches...)
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	unique := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > maxBulkVideos {
+		return nil, fmt.Errorf("at most %d videos can be edited at once", maxBulkVideos)
+	}
+	return unique, nil
+}
+
+// bulkField is a videos column a bulk edit sets to one value
+type bulkField struct {
+	column string
+	value  interface{}
+}
+
+// bulkUpdateFields sets the scalar fields and appends the description in one
+// statement, returning the previous values of every video that changed
+func bulkUpdateFields(tx *sql.Tx, req *models.VideoBulkUpdate, ids []int64, result *models.VideoBulkResult, changed map[int64]bool) ([]models.OperationChange, error) {
+	var fields []bulkField
+	if req.Rating != nil {
+		fields = append(fields, bulkField{"rating", *req.Rating})
+	}
+	if req.IsFavorite != nil {
+		fields = append(fields, bulkField{"is_favorite", *req.IsFavorite})
+	}
+	if req.IsPinned != nil {
+		fields = append(fields, bulkField{"is_pinned", *req.IsPinned})
+	}
+	if req.NotInterested != nil {
+		fields = append(fields, bulkField{"not_interested", *req.NotInterested})
+	}
+	if req.InEditList != nil {
+		fields = append(fields, bulkField{"in_edit_list", *req.InEditList})
+	}
+	var appendText string
+	if req.AppendDescription != nil {
+		appendText = strings.TrimSpace(*req.AppendDescription)
+	}
+	if len(fields) == 0 && appendText == "" {
+		return nil, nil
+	}
+
+	args := idArgs(ids)
+	in := `(?` + strings.Repeat(",?", len(ids)-1) + `)`
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(rating, 0), COALESCE(is_favorite, 0), COALESCE(is_pinned, 0),
+		       COALESCE(not_interested, 0), COALESCE(in_edit_list, 0), COALESCE(description, '')
+		FROM videos WHERE id IN `+in, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query videos: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var changes []models.OperationChange
+	for rows.Next() {
+		var id int64
+		var rating int
+		var favorite, pinned, notInterested, inEditList bool
+		var description string
+		if err := rows.Scan(&id, &rating, &favorite, &pinned, &notInterested, &inEditList, &description); err != nil {
+			return nil, fmt.Errorf("failed to scan video: %w", err)
+		}
+		previous := map[string]interface{}{
+			"rating":         rating,
+			"is_favorite":    favorite,
+			"is_pinned":      pinned,
+			"not_interested": notInterested,
+			"in_edit_list":   inEditList,
+		}
+
+		row := map[string]interface{}{"id": id}
+		for _, field := range fields {
+			if previous[field.column] != field.value {
+				row[field.column] = previous[field.column]
+				result.Updated[field.column]++
+			}
+		}
+		if appendText != "" {
+			row["description"] = description
+			result.Updated["description"]++
+		}
+		if len(row) > 1 {
+			changes = append(changes, models.OperationChange{Kind: models.ChangeRowUpdated, Table: "videos", Row: row})
+			changed[id] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query videos: %w", err)
+	}
+
+	var assignments []string
+	var values []interface{}
+	for _, field := range fields {
+		assignments = append(assignments, field.column+" = ?")
+		values = append(values, field.value)
+	}
+	if appendText != "" {
+		assignments = append(assignments, `description = CASE WHEN COALESCE(description, '') = '' THEN ? ELSE description || char(10) || ? END`)
+		values = append(values, appendText, appendText)
+	}
+	if _, err := tx.Exec(`UPDATE videos SET `+strings.Join(assignments, ", ")+` WHERE id IN `+in, append(values, args...)...); err != nil {
+		return nil, fmt.Errorf("failed to update videos: %w", err)
+	}
+	return changes, nil
+}
+
+// bulkUpdateLinks applies one relationship's changes to the videos and returns
+// the rows it added and removed
+func bulkUpdateLinks(tx *sql.Tx, link bulkLinkTable, change *models.VideoBulkLinks, ids []int64) ([]linkPair, []linkPair, error) {
+	existing, err := videoLinks(tx, link.table, link.column, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	add, remove := change.Add, change.Remove
+	if change.Set != nil {
+		add = change.Set
+	}
+	if link.table == "video_tags" {
+		// Tags bring their ancestors, as a set keeps them
+		if add, err = tagsWithAncestors(tx, add); err != nil {
+			return nil, nil, err
+		}
+	}
+	var keep map[int64]bool
+	if change.Set != nil {
+		keep = make(map[int64]bool, len(add))
+		for _, id := range add {
+			keep[id] = true
+		}
+	}
+
+	var added, removed []linkPair
+	for _, videoID := range ids {
+		current := existing[videoID]
+		if keep != nil {
+			for refID := range current {
+				if !keep[refID] {
+					removed = append(removed, linkPair{videoID, refID})
+				}
+			}
+		}
+		for _, refID := range remove {
+			if current[refID] {
+				removed = append(removed, linkPair{videoID, refID})
+			}
+		}
+		adding := make(map[int64]bool, len(add))
+		for _, refID := range add {
+			if !current[refID] && !adding[refID] {
+				adding[refID] = true
+				added = append(added, linkPair{videoID, refID})
+			}
+		}
+	}
+
+	for refID, videoIDs := range groupLinkPairs(removed) {
+		args := append([]interface{}{refID}, idArgs(videoIDs)...)
+		if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %s = ? AND video_id IN (?%s)`,
+			link.table, link.column, strings.Repeat(",?", len(videoIDs)-1)), args...); err != nil {
+			return nil, nil, fmt.Errorf("failed to remove %ss: %w", link.name, err)
+		}
+	}
+	if err := insertLinkPairs(tx, link.table, link.column, added); err != nil {
+		return nil, nil, fmt.Errorf("failed to add %ss: %w", link.name, err)
+	}
+	return added, removed, nil
+}
+
+// applyMasterTags adds the master tags of newly linked performers, and their
+// ancestors, to their videos and returns the tag links it added
+func applyMasterTags(tx *sql.Tx, performerLinks []linkPair) ([]linkPair, error) {
+	if len(performerLinks) == 0 {
+		return nil, nil
+	}
+	byPerformer := groupLinkPairs(performerLinks)
+	performerIDs := make([]int64, 0, len(byPerformer))
+	for id := range byPerformer {
+		performerIDs = append(performerIDs, id)
+	}
+	masterTags, err := linksByOwner(tx, `SELECT performer_id, tag_id FROM performer_tags WHERE performer_id IN (?`+strings.Repeat(",?", len(performerIDs)-1)+`)`, idArgs(performerIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query master tags: %w", err)
+	}
+	if len(masterTags) == 0 {
+		return nil, nil
+	}
+
+	var tagLinks []linkPair
+	for _, pair := range performerLinks {
+		for tagID := range masterTags[pair.refID] {
+			tagLinks = append(tagLinks, linkPair{pair.videoID, tagID})
+		}
+	}
+	added, err := insertVideoTags(tx, tagLinks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply master tags: %w", err)
+	}
+	return added, nil
+}
+
+// videoLinks loads what each video is linked to in a link table
+func videoLinks(tx *sql.Tx, table, column string, videoIDs []int64) (map[int64]map[int64]bool, error) {
+	links, err := linksByOwner(tx, fmt.Sprintf(`SELECT video_id, %s FROM %s WHERE video_id IN (?%s)`,
+		column, table, strings.Repeat(",?", len(videoIDs)-1)), idArgs(videoIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	return links, nil
+}
+
+// linksByOwner runs a query selecting pairs of IDs and groups the second by the first
+func linksByOwner(tx *sql.Tx, query string, args ...interface{}) (map[int64]map[int64]bool, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	links := make(map[int64]map[int64]bool)
+	for rows.Next() {
+		var ownerID, refID int64
+		if err := rows.Scan(&ownerID, &refID); err != nil {
+			return nil, err
+		}
+		if links[ownerID] == nil {
+			links[ownerID] = make(map[int64]bool)
+		}
+		links[ownerID][refID] = true
+	}
+	return links, rows.Err()
+}
+
+// groupLinkPairs groups link rows by what the videos link to, so each linked
+// record takes one statement however many videos it's added to or removed from
+func groupLinkPairs(pairs []linkPair) map[int64][]int64 {
+	grouped := make(map[int64][]int64)
+	for _, pair := range pairs {
+		grouped[pair.refID] = append(grouped[pair.refID], pair.videoID)
+	}
+	return grouped
+}
+
+// insertLinkPairs inserts link rows, one statement per linked record
+func insertLinkPairs(tx *sql.Tx, table, column string, pairs []linkPair) error {
+	for refID, videoIDs := range groupLinkPairs(pairs) {
+		args := append([]interface{}{refID}, idArgs(videoIDs)...)
+		if _, err := tx.Exec(fmt.Sprintf(`INSERT INTO %s (video_id, %s) SELECT id, ? FROM videos WHERE id IN (?%s)`,
+			table, column, strings.Repeat(",?", len(videoIDs)-1)), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendLinkChanges journals link rows as added or deleted
+func appendLinkChanges(changes []models.OperationChange, kind, table string, pairs []linkPair) []models.OperationChange {
+	for _, pair := range pairs {
+		changes = append(changes, linkChange(kind, table, pair.videoID, pair.refID))
+	}
+	return changes
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// facetLimit caps how many values the tag, performer and studio facets return
+const facetLimit = 50
+
+// namedFacetQuery counts filtered videos per linked tag, performer or studio
+func namedFacetQuery(joinTable, nameTable, foreignKey string) string {
+	return fmt.Sprintf(`
+		SELECT n.id, n.name, COUNT(*) AS count
+		FROM filtered f
+		JOIN %s j ON j.video_id = f.id
+		JOIN %s n ON n.id = j.%s
+		GROUP BY n.id
+		ORDER BY count DESC, n.name COLLATE NOCASE ASC
+		LIMIT %d`, joinTable, nameTable, foreignKey, facetLimit)
+}
+
+// bucketFacetQuery counts filtered videos per computed bucket. order sorts the
+// buckets; it's aggregated because it's evaluated per group.
+func bucketFacetQuery(bucket, order string) string {
+	return fmt.Sprintf(`
+		SELECT NULL, %s AS bucket, COUNT(*) AS count
+		FROM filtered f
+		JOIN videos v ON v.id = f.id
+		GROUP BY bucket
+		ORDER BY %s`, bucket, order)
+}
+
+// resolutionBucket labels the shorter side of a video's resolution. Labels other
+// than sd and unknown work as res>= values in structured queries.
+const resolutionBucket = `CASE
+	WHEN ` + resolutionExpr + ` >= 4320 THEN '8k'
+	WHEN ` + resolutionExpr + ` >= 2160 THEN '4k'
+	WHEN ` + resolutionExpr + ` >= 1440 THEN '1440p'
+	WHEN ` + resolutionExpr + ` >= 1080 THEN '1080p'
+	WHEN ` + resolutionExpr + ` >= 720 THEN '720p'
+	WHEN ` + resolutionExpr + ` >= 480 THEN '480p'
+	WHEN ` + resolutionExpr + ` > 0 THEN 'sd'
+	ELSE 'unknown' END`
+
+// durationBucket groups videos into length bands
+const durationBucket = `CASE
+	WHEN COALESCE(v.duration, 0) <= 0 THEN 'unknown'
+	WHEN v.duration < 300 THEN '0-5m'
+	WHEN v.duration < 1200 THEN '5-20m'
+	WHEN v.duration < 2400 THEN '20-40m'
+	WHEN v.duration < 3600 THEN '40-60m'
+	ELSE '60m+' END`
+
+// facetQueries follow a "filtered" CTE holding the IDs of the videos that match the search
+var facetQueries = map[string]string{
+	models.FacetTags:       namedFacetQuery("video_tags", "tags", "tag_id"),
+	models.FacetPerformers: namedFacetQuery("video_performers", "performers", "performer_id"),
+	models.FacetStudios:    namedFacetQuery("video_studios", "studios", "studio_id"),
+	models.FacetResolution: bucketFacetQuery(resolutionBucket, "MAX("+resolutionExpr+") DESC"),
+	models.FacetCodec:      bucketFacetQuery(`COALESCE(NULLIF(LOWER(v.codec), ''), 'unknown')`, "count DESC, bucket ASC"),
+	models.FacetDuration:   bucketFacetQuery(durationBucket, "MIN(COALESCE(v.duration, 0)) ASC"),
+}
+
+// ParseFacetNames splits a comma-separated facets parameter and rejects unknown facets
+func ParseFacetNames(raw string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := facetQueries[name]; !ok {
+			return nil, fmt.Errorf("unknown facet %q (use tags, performers, studios, resolution, codec or duration)", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// GetFacets counts the videos matching a search by tag, performer, studio,
+// resolution, codec or duration band. Counts cover every page of results.
+func (s *VideoService) GetFacets(query *models.VideoSearchQuery, names []string) (map[string][]models.FacetCount, error) {
+	filter, err := s.buildVideoFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	filtered := "WITH filtered AS (SELECT v.id FROM videos v" + filter.joins + filter.where + ")"
+
+	facets := make(map[string][]models.FacetCount, len(names))
+	for _, name := range names {
+		counts, err := s.facetCounts(filtered+facetQueries[name], filter.args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s facet: %w", name, err)
+		}
+		facets[name] = counts
+	}
+	return facets, nil
+}
+
+// facetCounts runs one facet query
+func (s *VideoService) facetCounts(query string, args []interface{}) ([]models.FacetCount, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	counts := []models.FacetCount{}
+	for rows.Next() {
+		var id sql.NullInt64
+		var count models.FacetCount
+		if err := rows.Scan(&id, &count.Value, &count.Count); err != nil {
+			return nil, err
+		}
+		if id.Valid {
+			count.ID = &id.Int64
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
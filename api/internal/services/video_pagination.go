@@ -0,0 +1,176 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// ErrInvalidCursor is returned for a cursor that's malformed or from a different sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// videoSortKeys are the sort expressions for each sort_by value. Each is non-null
+// so keyset comparisons never meet NULL, and none carries a DATETIME declared type,
+// so keys scan as the raw stored values the comparisons use.
+var videoSortKeys = map[string]string{
+	"id":              "v.id",
+	"created_at":      "COALESCE(v.created_at, '')",
+	"updated_at":      "COALESCE(v.updated_at, '')",
+	"title":           "COALESCE(v.title, '')",
+	"duration":        "COALESCE(v.duration, 0)",
+	"file_size":       "COALESCE(v.file_size, 0)",
+	"play_count":      "COALESCE(v.play_count, 0)",
+	"rating":          "COALESCE(v.rating, 0)",
+	"last_played_at":  "COALESCE(v.last_played_at, '')",
+	"bitrate":         "COALESCE(v.bitrate, 0)",
+	"performer_count": "(SELECT COUNT(*) FROM video_performers WHERE video_id = v.id)",
+}
+
+// randomPrime bounds the random sort keys; products of two keys stay under 2^62
+const randomPrime = 2147483647
+
+// splitmix64 scrambles a seed into well-spread constants
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// randomSortKey is a seeded pseudo-random key for each video. Squaring twice mod a
+// prime breaks up the stride pattern a linear hash of sequential IDs would show.
+// The key only depends on the video's own ID, so adding videos doesn't reshuffle
+// pages a client has already seen.
+func randomSortKey(seed int64) string {
+	constant := func(i uint64) uint64 {
+		return splitmix64(uint64(seed)+i)%(randomPrime-1) + 1
+	}
+	k := fmt.Sprintf("((v.id * %d + %d) %% %d)", constant(1), constant(2), randomPrime)
+	r := fmt.Sprintf("((%[1]s * %[1]s + %[2]d) %% %[3]d)", k, constant(3), randomPrime)
+	return fmt.Sprintf("((%[1]s * %[1]s) %% %[2]d)", r, randomPrime)
+}
+
+// newRandomSeed picks a seed for a shuffle that didn't ask for one
+func newRandomSeed() int64 {
+	return int64(splitmix64(uint64(time.Now().UnixNano())) % randomPrime)
+}
+
+// videoSort is a search's sort order after defaults are applied
+type videoSort struct {
+	by    string // sort_by value
+	order string // asc or desc
+	key   string // SQL expression to order by, before v.id
+	seed  int64  // Shuffle seed when by is random
+}
+
+// resolveVideoSort applies defaults to a query's sort. Unknown keys fall back
+// to created_at so the column never comes from user input; searches default to
+// relevance.
+func resolveVideoSort(query *models.VideoSearchQuery, ftsMatch string) videoSort {
+	sort := videoSort{by: query.SortBy, order: query.SortOrder, seed: query.Seed}
+	if sort.by == "" {
+		sort.by = "created_at"
+		if ftsMatch != "" {
+			sort.by = "relevance"
+		}
+	}
+	if sort.order != "asc" && sort.order != "desc" {
+		sort.order = "desc"
+	}
+
+	switch {
+	case sort.by == "relevance" && ftsMatch != "":
+		// bm25 scores are lower for better matches
+		sort.key, sort.order = "fts.rank", "asc"
+	case sort.by == "random":
+		if sort.seed == 0 {
+			sort.seed = newRandomSeed()
+		}
+		sort.key = randomSortKey(sort.seed)
+	default:
+		key, ok := videoSortKeys[sort.by]
+		if !ok {
+			sort.by, key = "created_at", videoSortKeys["created_at"]
+		}
+		sort.key = key
+	}
+	return sort
+}
+
+// videoCursor marks where a page ended: the sort it belongs to and the last row's
+// sort key and ID. Clients treat the encoded form as opaque.
+type videoCursor struct {
+	Sort  string      `json:"s"`
+	Order string      `json:"o"`
+	Seed  int64       `json:"r,omitempty"`
+	Key   interface{} `json:"k"`
+	ID    int64       `json:"i"`
+}
+
+func (c *videoCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeVideoCursor reads a cursor from a previous page
+func decodeVideoCursor(encoded string) (*videoCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor videoCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// cursorKey converts a scanned sort key to a value that survives the JSON round trip
+func cursorKey(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999999-07:00")
+	}
+	return value
+}
+
+// SearchIDs returns the IDs of up to max videos matching a search, in its sort
+// order, without loading the videos
+func (s *VideoService) SearchIDs(query *models.VideoSearchQuery, max int) ([]int64, error) {
+	filter, err := s.buildVideoFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	order := resolveVideoSort(query, filter.ftsMatch)
+	direction := strings.ToUpper(order.order)
+
+	rows, err := s.db.Query("SELECT v.id FROM videos v"+filter.joins+filter.where+
+		fmt.Sprintf(" ORDER BY %s %s, v.id %s LIMIT ?", order.key, direction, direction),
+		append(filter.args, max)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query videos: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan video: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
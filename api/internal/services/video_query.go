@@ -0,0 +1,714 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brixen96/video-storage-ai/internal/database"
+)
+
+// Structured video queries combine field terms with AND, OR, NOT (or a leading -)
+// and parentheses, for example:
+//
+//	performer:"Jane Doe" tag:outdoor -tag:compilation duration>20m res>=1080 added:<30d
+//
+// Terms next to each other are ANDed, and AND binds tighter than OR. Text
+// without a field searches titles, paths and descriptions.
+
+// VideoQueryError describes why a structured query couldn't be parsed
+type VideoQueryError struct {
+	Position int    `json:"position"` // 1-based character offset into the query
+	Message  string `json:"message"`
+}
+
+func (e *VideoQueryError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+type queryTokenKind int
+
+const (
+	queryTokenWord queryTokenKind = iota
+	queryTokenPhrase
+	queryTokenOpen
+	queryTokenClose
+	queryTokenNot
+	queryTokenEnd
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+// lexVideoQuery splits a query into words, quoted phrases, parentheses and
+// leading minus signs
+func lexVideoQuery(input string) ([]queryToken, error) {
+	runes := []rune(input)
+	var tokens []queryToken
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: queryTokenOpen, text: "(", pos: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: queryTokenClose, text: ")", pos: i + 1})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, queryToken{kind: queryTokenNot, text: "-", pos: i + 1})
+			i++
+		case r == '"':
+			start := i
+			var phrase strings.Builder
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				phrase.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &VideoQueryError{Position: start + 1, Message: "unterminated quote"}
+			}
+			i++
+			tokens = append(tokens, queryToken{kind: queryTokenPhrase, text: phrase.String(), pos: start + 1})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenWord, text: string(runes[start:i]), pos: start + 1})
+		}
+	}
+
+	return append(tokens, queryToken{kind: queryTokenEnd, pos: len(runes) + 1}), nil
+}
+
+// queryTermPattern splits field terms such as duration>=20m or tag:outdoor
+var queryTermPattern = regexp.MustCompile(`^([A-Za-z_]+)(:|!=|>=|<=|=|>|<)(.*)$`)
+
+// queryComparisons are the operators a value can start with after a colon, as in added:<30d
+var queryComparisons = []string{"!=", ">=", "<=", "=", ">", "<"}
+
+// compiledVideoQuery is a structured query translated to a WHERE condition
+type compiledVideoQuery struct {
+	where string
+	args  []interface{}
+	// filtersStatus is set when the query picks available or missing videos itself,
+	// so the default of hiding missing videos doesn't apply
+	filtersStatus bool
+}
+
+type videoQueryParser struct {
+	tokens []queryToken
+	index  int
+	now    time.Time
+	result compiledVideoQuery
+}
+
+// compileVideoQuery parses a structured query into SQL conditions on videos v
+func compileVideoQuery(input string, now time.Time) (*compiledVideoQuery, error) {
+	tokens, err := lexVideoQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &videoQueryParser{tokens: tokens, now: now}
+	if p.peek().kind == queryTokenEnd {
+		return nil, &VideoQueryError{Position: 1, Message: "query is empty"}
+	}
+	where, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != queryTokenEnd {
+		return nil, &VideoQueryError{Position: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+
+	p.result.where = where
+	return &p.result, nil
+}
+
+func (p *videoQueryParser) peek() queryToken {
+	return p.tokens[p.index]
+}
+
+func (p *videoQueryParser) next() queryToken {
+	tok := p.tokens[p.index]
+	if tok.kind != queryTokenEnd {
+		p.index++
+	}
+	return tok
+}
+
+// isKeyword reports whether a token is an operator keyword. Keywords are
+// uppercase so that lowercase "and" or "or" still search as text.
+func isKeyword(tok queryToken, keyword string) bool {
+	return tok.kind == queryTokenWord && tok.text == keyword
+}
+
+// startsTerm reports whether a token can begin a term
+func startsTerm(tok queryToken) bool {
+	switch tok.kind {
+	case queryTokenWord:
+		return !isKeyword(tok, "AND") && !isKeyword(tok, "OR")
+	case queryTokenPhrase, queryTokenOpen, queryTokenNot:
+		return true
+	}
+	return false
+}
+
+// expectTerm returns an error unless the next token can begin a term
+func (p *videoQueryParser) expectTerm(after string) error {
+	tok := p.peek()
+	if startsTerm(tok) {
+		return nil
+	}
+	if tok.kind == queryTokenEnd {
+		return &VideoQueryError{Position: tok.pos, Message: fmt.Sprintf("expected a search term after %s", after)}
+	}
+	return &VideoQueryError{Position: tok.pos, Message: fmt.Sprintf("unexpected %q after %s", tok.text, after)}
+}
+
+func (p *videoQueryParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{left}
+	for isKeyword(p.peek(), "OR") {
+		p.next()
+		if err := p.expectTerm("OR"); err != nil {
+			return "", err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, right)
+	}
+
+	if len(parts) == 1 {
+		return left, nil
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", nil
+}
+
+func (p *videoQueryParser) parseAnd() (string, error) {
+	var parts []string
+	for {
+		if isKeyword(p.peek(), "AND") {
+			p.next()
+			if len(parts) == 0 {
+				return "", &VideoQueryError{Position: p.tokens[p.index-1].pos, Message: "AND needs a term before it"}
+			}
+			if err := p.expectTerm("AND"); err != nil {
+				return "", err
+			}
+		}
+		if !startsTerm(p.peek()) {
+			break
+		}
+		part, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 0 {
+		tok := p.peek()
+		return "", &VideoQueryError{Position: tok.pos, Message: fmt.Sprintf("expected a search term before %q", tok.text)}
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return "(" + strings.Join(parts, " AND ") + ")", nil
+}
+
+func (p *videoQueryParser) parseUnary() (string, error) {
+	tok := p.peek()
+	if tok.kind == queryTokenNot || isKeyword(tok, "NOT") {
+		p.next()
+		if err := p.expectTerm(tok.text); err != nil {
+			return "", err
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + operand + ")", nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *videoQueryParser) parsePrimary() (string, error) {
+	tok := p.next()
+	switch tok.kind {
+	case queryTokenOpen:
+		if err := p.expectTerm("("); err != nil {
+			return "", err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if p.peek().kind != queryTokenClose {
+			return "", &VideoQueryError{Position: tok.pos, Message: "missing closing parenthesis"}
+		}
+		p.next()
+		return "(" + inner + ")", nil
+	case queryTokenPhrase:
+		return p.textCondition(tok.text, true), nil
+	case queryTokenWord:
+		return p.parseTerm(tok)
+	}
+	return "", &VideoQueryError{Position: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.text)}
+}
+
+// parseTerm compiles a field term, or treats the word as free text
+func (p *videoQueryParser) parseTerm(tok queryToken) (string, error) {
+	match := queryTermPattern.FindStringSubmatch(tok.text)
+	if match == nil {
+		return p.textCondition(tok.text, false), nil
+	}
+
+	field, op, value := strings.ToLower(match[1]), match[2], match[3]
+	if op == ":" {
+		for _, comparison := range queryComparisons {
+			if strings.HasPrefix(value, comparison) {
+				op, value = comparison, strings.TrimPrefix(value, comparison)
+				break
+			}
+		}
+	}
+
+	// performer:"Jane Doe" lexes as a word ending in the operator, then a phrase
+	quoted := false
+	if value == "" {
+		if p.peek().kind != queryTokenPhrase {
+			return "", &VideoQueryError{Position: tok.pos, Message: fmt.Sprintf("missing value for %s", field)}
+		}
+		value, quoted = p.next().text, true
+	}
+
+	condition, err := p.fieldCondition(field, op, value, quoted)
+	if err != nil {
+		return "", &VideoQueryError{Position: tok.pos, Message: err.Error()}
+	}
+	return condition, nil
+}
+
+// textCondition matches free text against titles, paths and descriptions,
+// through the full-text index when it's available
+func (p *videoQueryParser) textCondition(text string, phrase bool) string {
+	if database.FTSEnabled() {
+		input := text
+		if phrase {
+			input = `"` + text + `"`
+		}
+		if match := buildFTSQuery(input); match != "" {
+			p.result.args = append(p.result.args, match)
+			return "v.id IN (SELECT rowid FROM videos_fts WHERE videos_fts MATCH ?)"
+		}
+	}
+
+	pattern := "%" + text + "%"
+	p.result.args = append(p.result.args, pattern, pattern, pattern)
+	return "(v.title LIKE ? OR v.file_path LIKE ? OR v.description LIKE ?)"
+}
+
+// queryNameFields match videos by the name of a linked entity
+var queryNameFields = map[string]string{
+	"performer": "SELECT vp.video_id FROM video_performers vp JOIN performers n ON n.id = vp.performer_id WHERE %s",
+	"tag":       "SELECT vt.video_id FROM video_tags vt JOIN tags n ON n.id = vt.tag_id OR n.id IN (SELECT ancestor_id FROM tag_ancestors WHERE tag_id = vt.tag_id) WHERE %s",
+	"studio":    "SELECT vs.video_id FROM video_studios vs JOIN studios n ON n.id = vs.studio_id WHERE %s",
+	"group":     "SELECT vg.video_id FROM video_groups vg JOIN groups n ON n.id = vg.group_id WHERE %s",
+}
+
+// queryTextFields match a column by substring, or exactly when exact is set
+var queryTextFields = map[string]struct {
+	column string
+	exact  bool
+}{
+	"title":       {"v.title", false},
+	"description": {"v.description", false},
+	"path":        {"v.file_path", false},
+	"codec":       {"v.codec", true},
+}
+
+// resolutionExpr is the shorter side of a WxH resolution, so portrait videos
+// rank by the same p-number as landscape ones
+const resolutionExpr = `(CASE WHEN instr(v.resolution, 'x') > 0 THEN MIN(
+	CAST(substr(v.resolution, 1, instr(v.resolution, 'x') - 1) AS INTEGER),
+	CAST(substr(v.resolution, instr(v.resolution, 'x') + 1) AS INTEGER)) ELSE 0 END)`
+
+// queryNumberFields compare a numeric column against a parsed value
+var queryNumberFields = map[string]struct {
+	column string
+	parse  func(string) (float64, error)
+}{
+	"duration": {"v.duration", parseQueryDuration},
+	"size":     {"v.file_size", parseQuerySize},
+	"res":      {resolutionExpr, parseQueryResolution},
+	"rating":   {"v.rating", parseQueryNumber},
+	"plays":    {"v.play_count", parseQueryNumber},
+	"bitrate":  {"v.bitrate", parseQueryBitrate},
+	"fps":      {"v.fps", parseQueryNumber},
+}
+
+// queryDateFields compare a date column, stored in the given layout
+var queryDateFields = map[string]struct {
+	column string
+	layout string
+}{
+	"added":  {"v.created_at", "2006-01-02 15:04:05"},
+	"played": {"v.last_played_at", "2006-01-02 15:04:05"},
+	"date":   {"v.date", "2006-01-02"},
+}
+
+// queryFlags are the values accepted by is: and has:
+var queryFlags = map[string]map[string]string{
+	"is": {
+		"favorite":      "v.is_favorite = 1",
+		"pinned":        "v.is_pinned = 1",
+		"played":        "v.play_count > 0",
+		"unplayed":      "COALESCE(v.play_count, 0) = 0",
+		"notinterested": "v.not_interested = 1",
+		"editlist":      "v.in_edit_list = 1",
+		"missing":       "v.status = 'missing'",
+		"available":     "COALESCE(v.status, 'available') = 'available'",
+	},
+	"has": {
+		"preview":     "COALESCE(v.preview_path, '') != ''",
+		"thumbnail":   "COALESCE(v.thumbnail_path, '') != ''",
+		"description": "COALESCE(v.description, '') != ''",
+		"performer":   "EXISTS (SELECT 1 FROM video_performers WHERE video_id = v.id)",
+		"tag":         "EXISTS (SELECT 1 FROM video_tags WHERE video_id = v.id)",
+		"studio":      "EXISTS (SELECT 1 FROM video_studios WHERE video_id = v.id)",
+		"group":       "EXISTS (SELECT 1 FROM video_groups WHERE video_id = v.id)",
+	},
+}
+
+// queryFieldAliases maps alternate spellings to canonical field names
+var queryFieldAliases = map[string]string{
+	"performers": "performer", "actor": "performer",
+	"tags": "tag", "studios": "studio", "groups": "group",
+	"resolution": "res", "filesize": "size", "file": "path",
+	"created": "added", "lastplayed": "played", "playcount": "plays",
+}
+
+// queryFieldNames lists the supported fields for error messages
+const queryFieldNames = "performer, tag, studio, group, library, title, description, path, codec, " +
+	"duration, size, res, rating, plays, bitrate, fps, added, played, date, is, has"
+
+// fieldCondition compiles one field term
+func (p *videoQueryParser) fieldCondition(field, op, value string, quoted bool) (string, error) {
+	if alias, ok := queryFieldAliases[field]; ok {
+		field = alias
+	}
+	equality := op == ":" || op == "="
+	negate := ""
+	if op == "!=" {
+		negate = "NOT "
+	}
+
+	if subquery, ok := queryNameFields[field]; ok {
+		if !equality && op != "!=" {
+			return "", fmt.Errorf("%s only supports : and !=", field)
+		}
+		match := nameMatchExpr(value)
+		p.result.args = append(p.result.args, nameMatchValue(value))
+		// Performers also answer to their aliases
+		if field == "performer" {
+			if strings.Contains(value, "*") {
+				match = "(" + match + " OR n.id IN (SELECT performer_id FROM performer_aliases WHERE alias LIKE ?))"
+				p.result.args = append(p.result.args, nameMatchValue(value))
+			} else {
+				match = "(" + match + " OR n.id IN (SELECT performer_id FROM performer_aliases WHERE normalized = ?))"
+				p.result.args = append(p.result.args, normalizePerformerName(value))
+			}
+		}
+		// Tags answer to their aliases and match videos with any descendant tag
+		if field == "tag" {
+			if strings.Contains(value, "*") {
+				match = "(" + match + " OR n.id IN (SELECT tag_id FROM tag_aliases WHERE alias LIKE ?))"
+			} else {
+				match = "(" + match + " OR n.id IN (SELECT tag_id FROM tag_aliases WHERE alias = ?))"
+			}
+			p.result.args = append(p.result.args, nameMatchValue(value))
+		}
+		return fmt.Sprintf("v.id %sIN ("+subquery+")", negate, match), nil
+	}
+
+	if text, ok := queryTextFields[field]; ok {
+		if !equality && op != "!=" {
+			return "", fmt.Errorf("%s only supports : and !=", field)
+		}
+		if text.exact {
+			p.result.args = append(p.result.args, value)
+			return fmt.Sprintf("%s(%s = ? COLLATE NOCASE)", negate, text.column), nil
+		}
+		p.result.args = append(p.result.args, "%"+value+"%")
+		return fmt.Sprintf("%s(COALESCE(%s, '') LIKE ?)", negate, text.column), nil
+	}
+
+	if number, ok := queryNumberFields[field]; ok {
+		n, err := number.parse(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s %q: %v", field, value, err)
+		}
+		p.result.args = append(p.result.args, n)
+		return fmt.Sprintf("%s %s ?", number.column, sqlComparison(op)), nil
+	}
+
+	if date, ok := queryDateFields[field]; ok {
+		return p.dateCondition(field, date.column, date.layout, op, value)
+	}
+
+	switch field {
+	case "library":
+		if !equality && op != "!=" {
+			return "", fmt.Errorf("library only supports : and !=")
+		}
+		id, _ := strconv.ParseInt(value, 10, 64)
+		p.result.args = append(p.result.args, value, id)
+		return fmt.Sprintf("v.library_id %sIN (SELECT id FROM libraries WHERE name = ? COLLATE NOCASE OR id = ?)", negate), nil
+	case "is", "has":
+		if !equality && op != "!=" {
+			return "", fmt.Errorf("%s only supports : and !=", field)
+		}
+		condition, ok := queryFlags[field][strings.ToLower(value)]
+		if !ok {
+			names := make([]string, 0, len(queryFlags[field]))
+			for name := range queryFlags[field] {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return "", fmt.Errorf("unknown value %q for %s (use one of %s)", value, field, strings.Join(names, ", "))
+		}
+		if field == "is" && (strings.EqualFold(value, "missing") || strings.EqualFold(value, "available")) {
+			p.result.filtersStatus = true
+		}
+		return negate + "(" + condition + ")", nil
+	}
+
+	if quoted {
+		return "", fmt.Errorf("unknown field %q (supported: %s)", field, queryFieldNames)
+	}
+	return "", fmt.Errorf("unknown field %q (supported: %s; quote text containing a colon)", field, queryFieldNames)
+}
+
+// nameMatchExpr matches names case-insensitively, with * as a wildcard
+func nameMatchExpr(value string) string {
+	if strings.Contains(value, "*") {
+		return "n.name LIKE ?"
+	}
+	return "n.name = ? COLLATE NOCASE"
+}
+
+func nameMatchValue(value string) string {
+	return strings.ReplaceAll(value, "*", "%")
+}
+
+// sqlComparison maps a query operator to SQL
+func sqlComparison(op string) string {
+	if op == ":" {
+		return "="
+	}
+	return op
+}
+
+// dateCondition compares a date column against an absolute date (2024, 2024-05
+// or 2024-05-01) or an age such as 30d. Ages compare how long ago something
+// happened, so added:<30d means added less than 30 days ago.
+func (p *videoQueryParser) dateCondition(field, column, layout, op, value string) (string, error) {
+	if at, ok, err := parseQueryAge(value, p.now); ok {
+		if err != nil {
+			return "", fmt.Errorf("invalid %s %q: %v", field, value, err)
+		}
+		// A shorter age is a later date, so the comparison flips
+		flipped := map[string]string{":": ">=", "=": ">=", "<": ">", "<=": ">=", ">": "<", ">=": "<="}
+		sqlOp, ok := flipped[op]
+		if !ok {
+			return "", fmt.Errorf("%s ages don't support %s", field, op)
+		}
+		p.result.args = append(p.result.args, at.Format(layout))
+		return fmt.Sprintf("%s %s ?", column, sqlOp), nil
+	}
+
+	start, end, err := parseQueryDate(value, p.now)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %q: %v", field, value, err)
+	}
+	from, until := start.Format(layout), end.Format(layout)
+
+	switch op {
+	case ":", "=":
+		p.result.args = append(p.result.args, from, until)
+		return fmt.Sprintf("(%[1]s >= ? AND %[1]s < ?)", column), nil
+	case "!=":
+		p.result.args = append(p.result.args, from, until)
+		return fmt.Sprintf("NOT (%[1]s >= ? AND %[1]s < ?)", column), nil
+	case ">":
+		p.result.args = append(p.result.args, until)
+		return fmt.Sprintf("%s >= ?", column), nil
+	case ">=":
+		p.result.args = append(p.result.args, from)
+		return fmt.Sprintf("%s >= ?", column), nil
+	case "<":
+		p.result.args = append(p.result.args, from)
+		return fmt.Sprintf("%s < ?", column), nil
+	default:
+		p.result.args = append(p.result.args, until)
+		return fmt.Sprintf("%s < ?", column), nil
+	}
+}
+
+// queryAgePattern matches ages such as 12h, 30d, 2w, 6m (months) and 1y
+var queryAgePattern = regexp.MustCompile(`^(\d+)([hdwmy])$`)
+
+// parseQueryAge converts an age to the moment that long before now. ok is false
+// when the value isn't an age at all.
+func parseQueryAge(value string, now time.Time) (time.Time, bool, error) {
+	match := queryAgePattern.FindStringSubmatch(strings.ToLower(value))
+	if match == nil {
+		return time.Time{}, false, nil
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	switch match[2] {
+	case "h":
+		return now.Add(-time.Duration(n) * time.Hour), true, nil
+	case "d":
+		return now.AddDate(0, 0, -n), true, nil
+	case "w":
+		return now.AddDate(0, 0, -7*n), true, nil
+	case "m":
+		return now.AddDate(0, -n, 0), true, nil
+	default:
+		return now.AddDate(-n, 0, 0), true, nil
+	}
+}
+
+// parseQueryDate returns the span covered by a year, month or day, or by
+// today and yesterday
+func parseQueryDate(value string, now time.Time) (time.Time, time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "today":
+		return today, today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, nil
+	}
+
+	for _, format := range []struct {
+		layout              string
+		years, months, days int
+	}{
+		{"2006-01-02", 0, 0, 1},
+		{"2006-01", 0, 1, 0},
+		{"2006", 1, 0, 0},
+	} {
+		if start, err := time.ParseInLocation(format.layout, value, now.Location()); err == nil {
+			return start, start.AddDate(format.years, format.months, format.days), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("use YYYY, YYYY-MM, YYYY-MM-DD, today, yesterday or an age like 30d")
+}
+
+func parseQueryNumber(value string) (float64, error) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("not a number")
+	}
+	return n, nil
+}
+
+// parseQueryDuration reads seconds from plain numbers or Go-style durations such as 20m or 1h30m
+func parseQueryDuration(value string) (float64, error) {
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		return n, nil
+	}
+	d, err := time.ParseDuration(strings.ToLower(value))
+	if err != nil {
+		return 0, fmt.Errorf("use seconds or a duration like 90s, 20m or 1h30m")
+	}
+	return d.Seconds(), nil
+}
+
+// querySizeUnits are binary multiples, matching how file managers report sizes
+var querySizeUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40,
+}
+
+// querySizePattern splits a size into its number and unit
+var querySizePattern = regexp.MustCompile(`^([0-9]*\.?[0-9]+)([a-zA-Z]*)$`)
+
+// parseQuerySize reads bytes from sizes such as 500mb or 2.5G
+func parseQuerySize(value string) (float64, error) {
+	match := querySizePattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("use a size like 700mb or 2gb")
+	}
+	unit, ok := querySizeUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", match[2])
+	}
+	n, _ := strconv.ParseFloat(match[1], 64)
+	return n * unit, nil
+}
+
+// parseQueryBitrate reads bits per second, with optional k or m suffixes
+func parseQueryBitrate(value string) (float64, error) {
+	match := querySizePattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("use a bitrate like 5000k or 8m")
+	}
+	n, _ := strconv.ParseFloat(match[1], 64)
+	switch strings.ToLower(match[2]) {
+	case "":
+		return n, nil
+	case "k", "kbps":
+		return n * 1000, nil
+	case "m", "mbps":
+		return n * 1000 * 1000, nil
+	}
+	return 0, fmt.Errorf("unknown unit %q", match[2])
+}
+
+// queryResolutionNames are common names for resolutions
+var queryResolutionNames = map[string]float64{
+	"sd": 480, "hd": 720, "fhd": 1080, "2k": 1440, "qhd": 1440, "4k": 2160, "uhd": 2160, "8k": 4320,
+}
+
+// parseQueryResolution reads the shorter side of a resolution from 1080, 720p or 4k
+func parseQueryResolution(value string) (float64, error) {
+	value = strings.ToLower(value)
+	if n, ok := queryResolutionNames[value]; ok {
+		return n, nil
+	}
+	n, err := strconv.ParseFloat(strings.TrimSuffix(value, "p"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("use a height like 1080, 720p or 4k")
+	}
+	return n, nil
+}
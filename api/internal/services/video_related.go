@@ -0,0 +1,491 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brixen96/video-storage-ai/internal/models"
+)
+
+// Related video score weights. Each signal scores from 0 to 1 before weighting,
+// so a video sharing everything with the source scores 1. Perceptual hashes
+// aren't computed yet, so visual similarity isn't part of the score.
+const (
+	relatedWeightPerformers = 0.35
+	relatedWeightTags       = 0.30
+	relatedWeightStudio     = 0.10
+	relatedWeightGroup      = 0.10
+	relatedWeightFolder     = 0.05
+	relatedWeightDuration   = 0.05
+	relatedWeightResolution = 0.05
+)
+
+// relatedCandidatePool is how many of the best-linked candidates get the
+// duration and resolution comparison; the rest can't catch up on those alone
+const relatedCandidatePool = 500
+
+// relatedCandidate accumulates the signals a video shares with the source
+type relatedCandidate struct {
+	id         int64
+	score      float64
+	performers []string
+	tags       []string
+	tagWeight  float64
+	studios    []string
+	groups     []string
+	sameFolder bool
+}
+
+// reasons lists the shared signals behind a candidate's score
+func (c *relatedCandidate) reasons(sourcePerformers, sourceTagWeight float64) []models.RelatedReason {
+	var reasons []models.RelatedReason
+	if len(c.performers) > 0 {
+		reasons = append(reasons, models.RelatedReason{Kind: models.RelatedReasonPerformer, Names: c.performers,
+			Score: relatedWeightPerformers * float64(len(c.performers)) / sourcePerformers})
+	}
+	if len(c.tags) > 0 {
+		reasons = append(reasons, models.RelatedReason{Kind: models.RelatedReasonTag, Names: c.tags,
+			Score: relatedWeightTags * c.tagWeight / sourceTagWeight})
+	}
+	if len(c.studios) > 0 {
+		reasons = append(reasons, models.RelatedReason{Kind: models.RelatedReasonStudio, Names: c.studios, Score: relatedWeightStudio})
+	}
+	if len(c.groups) > 0 {
+		reasons = append(reasons, models.RelatedReason{Kind: models.RelatedReasonGroup, Names: c.groups, Score: relatedWeightGroup})
+	}
+	if c.sameFolder {
+		reasons = append(reasons, models.RelatedReason{Kind: models.RelatedReasonFolder, Score: relatedWeightFolder})
+	}
+	return reasons
+}
+
+// GetRelated finds videos like the source, as loaded by GetByID. They're ranked
+// by shared performers, tags (rare tags count for more), studio, group and
+// folder, then by how close their duration and resolution are. Missing and
+// not-interested videos and copies of the same file are left out.
+func (s *VideoService) GetRelated(source *models.Video, limit int) ([]models.RelatedVideo, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	id := source.ID
+
+	candidates := make(map[int64]*relatedCandidate)
+	candidate := func(videoID int64) *relatedCandidate {
+		c, ok := candidates[videoID]
+		if !ok {
+			c = &relatedCandidate{id: videoID}
+			candidates[videoID] = c
+		}
+		return c
+	}
+
+	// Performers: the share of the source's performers a candidate features
+	performerNames := make(map[int64]string, len(source.Performers))
+	for _, p := range source.Performers {
+		performerNames[p.ID] = p.Name
+	}
+	err := s.forEachLinkedVideo("video_performers", "performer_id", id, performerNames, func(videoID, performerID int64) {
+		c := candidate(videoID)
+		c.performers = append(c.performers, performerNames[performerID])
+		c.score += relatedWeightPerformers / float64(len(performerNames))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Tags, weighted by inverse document frequency so niche tags outweigh common ones
+	tagNames := make(map[int64]string, len(source.Tags))
+	for _, t := range source.Tags {
+		tagNames[t.ID] = t.Name
+	}
+	tagWeights, err := s.tagIDF(tagNames)
+	if err != nil {
+		return nil, err
+	}
+	var sourceTagWeight float64
+	for _, w := range tagWeights {
+		sourceTagWeight += w
+	}
+	err = s.forEachLinkedVideo("video_tags", "tag_id", id, tagNames, func(videoID, tagID int64) {
+		w := tagWeights[tagID]
+		c := candidate(videoID)
+		c.tags = append(c.tags, tagNames[tagID])
+		c.tagWeight += w
+		c.score += relatedWeightTags * w / sourceTagWeight
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Studios and groups count once however many are shared
+	studioNames := make(map[int64]string, len(source.Studios))
+	for _, st := range source.Studios {
+		studioNames[st.ID] = st.Name
+	}
+	err = s.forEachLinkedVideo("video_studios", "studio_id", id, studioNames, func(videoID, studioID int64) {
+		c := candidate(videoID)
+		if len(c.studios) == 0 {
+			c.score += relatedWeightStudio
+		}
+		c.studios = append(c.studios, studioNames[studioID])
+	})
+	if err != nil {
+		return nil, err
+	}
+	groupNames := make(map[int64]string, len(source.Groups))
+	for _, g := range source.Groups {
+		groupNames[g.ID] = g.Name
+	}
+	err = s.forEachLinkedVideo("video_groups", "group_id", id, groupNames, func(videoID, groupID int64) {
+		c := candidate(videoID)
+		if len(c.groups) == 0 {
+			c.score += relatedWeightGroup
+		}
+		c.groups = append(c.groups, groupNames[groupID])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Videos in the same folder are often parts of one set
+	folderIDs, err := s.sameFolderVideoIDs(source)
+	if err != nil {
+		return nil, err
+	}
+	for _, videoID := range folderIDs {
+		c := candidate(videoID)
+		c.sameFolder = true
+		c.score += relatedWeightFolder
+	}
+
+	// Keep the best-linked candidates for the closer comparison
+	pool := make([]*relatedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		pool = append(pool, c)
+	}
+	sortRelatedCandidates(pool)
+	if len(pool) > relatedCandidatePool {
+		pool = pool[:relatedCandidatePool]
+	}
+
+	attributes, err := s.relatedAttributes(pool)
+	if err != nil {
+		return nil, err
+	}
+	sourceHeight := resolutionHeight(source.Resolution)
+	reasons := make(map[int64][]models.RelatedReason, len(pool))
+	kept := pool[:0]
+	for _, c := range pool {
+		attr, ok := attributes[c.id]
+		if !ok || attr.status == models.VideoStatusMissing || attr.notInterested ||
+			(source.OSHash != "" && attr.oshash == source.OSHash) {
+			continue
+		}
+
+		reasons[c.id] = c.reasons(float64(len(performerNames)), sourceTagWeight)
+		if closeness := durationCloseness(source.Duration, attr.duration); closeness > 0 {
+			c.score += relatedWeightDuration * closeness
+			reasons[c.id] = append(reasons[c.id], models.RelatedReason{Kind: models.RelatedReasonDuration, Score: relatedWeightDuration * closeness})
+		}
+		if closeness := resolutionCloseness(sourceHeight, resolutionHeight(attr.resolution)); closeness > 0 {
+			c.score += relatedWeightResolution * closeness
+			reasons[c.id] = append(reasons[c.id], models.RelatedReason{Kind: models.RelatedReasonResolution, Score: relatedWeightResolution * closeness})
+		}
+		kept = append(kept, c)
+	}
+	sortRelatedCandidates(kept)
+	if len(kept) > limit {
+		kept = kept[:limit]
+	}
+
+	ids := make([]int64, len(kept))
+	for i, c := range kept {
+		ids[i] = c.id
+	}
+	videos, err := s.getVideosByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[int64]float64, len(kept))
+	for _, c := range kept {
+		scores[c.id] = c.score
+	}
+	related := make([]models.RelatedVideo, 0, len(videos))
+	for _, video := range videos {
+		for i := range reasons[video.ID] {
+			reasons[video.ID][i].Score = roundScore(reasons[video.ID][i].Score)
+		}
+		related = append(related, models.RelatedVideo{
+			Video:        video,
+			RelatedScore: roundScore(scores[video.ID]),
+			Reasons:      reasons[video.ID],
+		})
+	}
+	return related, nil
+}
+
+// roundScore trims a score to three decimals for display
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
+
+// sortRelatedCandidates orders candidates by score, newest first on ties
+func sortRelatedCandidates(candidates []*relatedCandidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].id > candidates[j].id
+	})
+}
+
+// forEachLinkedVideo calls fn for every other video linked through a join table
+// to one of the given entities, keyed by ID
+func (s *VideoService) forEachLinkedVideo(table, column string, sourceID int64, names map[int64]string, fn func(videoID, entityID int64)) error {
+	if len(names) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(names)+1)
+	for entityID := range names {
+		args = append(args, entityID)
+	}
+	args = append(args, sourceID)
+
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT video_id, %s FROM %s WHERE %s IN (?%s) AND video_id != ?`,
+		column, table, column, strings.Repeat(",?", len(names)-1)), args...)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var videoID, entityID int64
+		if err := rows.Scan(&videoID, &entityID); err != nil {
+			return fmt.Errorf("failed to scan %s: %w", table, err)
+		}
+		fn(videoID, entityID)
+	}
+	return rows.Err()
+}
+
+// tagIDF weighs each tag by how rare it is across the library
+func (s *VideoService) tagIDF(tagNames map[int64]string) (map[int64]float64, error) {
+	weights := make(map[int64]float64, len(tagNames))
+	if len(tagNames) == 0 {
+		return weights, nil
+	}
+
+	var total float64
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM videos`).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count videos: %w", err)
+	}
+
+	args := make([]interface{}, 0, len(tagNames))
+	for tagID := range tagNames {
+		args = append(args, tagID)
+	}
+	rows, err := s.db.Query(`SELECT tag_id, COUNT(*) FROM video_tags WHERE tag_id IN (?`+
+		strings.Repeat(",?", len(tagNames)-1)+`) GROUP BY tag_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tag usage: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var tagID int64
+		var count float64
+		if err := rows.Scan(&tagID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag usage: %w", err)
+		}
+		weights[tagID] = math.Log(1 + total/count)
+	}
+	return weights, rows.Err()
+}
+
+// sameFolderVideoIDs finds the other videos directly in the source's folder
+func (s *VideoService) sameFolderVideoIDs(source *models.Video) ([]int64, error) {
+	cut := strings.LastIndexAny(source.FilePath, `/\`)
+	if cut < 0 {
+		return nil, nil
+	}
+	folder := source.FilePath[:cut+1]
+
+	// SQLite's substr counts characters, not bytes
+	rows, err := s.db.Query(`SELECT id, file_path FROM videos WHERE library_id = ? AND substr(file_path, 1, ?) = ? AND id != ?`,
+		source.LibraryID, utf8.RuneCountInString(folder), folder, source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query folder: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var ids []int64
+	for rows.Next() {
+		var videoID int64
+		var path string
+		if err := rows.Scan(&videoID, &path); err != nil {
+			return nil, fmt.Errorf("failed to scan folder video: %w", err)
+		}
+		// Files in subfolders aren't neighbours
+		if !strings.ContainsAny(path[len(folder):], `/\`) {
+			ids = append(ids, videoID)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// relatedAttributes are the candidate columns the closer comparison needs
+type relatedAttributes struct {
+	duration      float64
+	resolution    string
+	status        string
+	notInterested bool
+	oshash        string
+}
+
+// relatedAttributes loads duration, resolution and exclusion flags for candidates
+func (s *VideoService) relatedAttributes(candidates []*relatedCandidate) (map[int64]relatedAttributes, error) {
+	attributes := make(map[int64]relatedAttributes, len(candidates))
+	if len(candidates) == 0 {
+		return attributes, nil
+	}
+	args := make([]interface{}, len(candidates))
+	for i, c := range candidates {
+		args[i] = c.id
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, COALESCE(duration, 0), COALESCE(resolution, ''), COALESCE(status, 'available'),
+		       COALESCE(not_interested, 0), COALESCE(oshash, '')
+		FROM videos WHERE id IN (?`+strings.Repeat(",?", len(candidates)-1)+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candidates: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var videoID int64
+		var attr relatedAttributes
+		if err := rows.Scan(&videoID, &attr.duration, &attr.resolution, &attr.status, &attr.notInterested, &attr.oshash); err != nil {
+			return nil, fmt.Errorf("failed to scan candidate: %w", err)
+		}
+		attributes[videoID] = attr
+	}
+	return attributes, rows.Err()
+}
+
+// durationCloseness is 1 for equal durations, falling to 0 at twice the length
+func durationCloseness(a, b float64) float64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	return 1 - math.Abs(a-b)/math.Max(a, b)
+}
+
+// resolutionHeight is the shorter side of a WxH resolution, matching resolutionExpr
+func resolutionHeight(resolution string) float64 {
+	var w, h float64
+	if _, err := fmt.Sscanf(resolution, "%fx%f", &w, &h); err != nil {
+		return 0
+	}
+	return math.Min(w, h)
+}
+
+// resolutionCloseness is 1 for the same height, half for one step apart such as
+// 1080p and 2160p, and 0 from two steps apart
+func resolutionCloseness(a, b float64) float64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	return math.Max(0, 1-math.Abs(math.Log2(a/b))/2)
+}
+
+// getVideosByIDs loads videos with their relationships, in the order given
+func (s *VideoService) getVideosByIDs(ids []int64) ([]models.Video, error) {
+	if len(ids) == 0 {
+		return []models.Video{}, nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, library_id, title, file_path, file_size, duration, codec, resolution,
+		       bitrate, fps, thumbnail_path, preview_path, date, rating, description, is_favorite, is_pinned,
+		       not_interested, in_edit_list, created_at, updated_at, last_played_at, play_count,
+		       COALESCE(status, 'available'), missing_since, COALESCE(oshash, ''), COALESCE(md5, '')
+		FROM videos
+		WHERE id IN (?`+strings.Repeat(",?", len(ids)-1)+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query videos: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	byID := make(map[int64]models.Video, len(ids))
+	for rows.Next() {
+		var video models.Video
+		var lastPlayedAt, missingSince sql.NullTime
+		var date, description, previewPath sql.NullString
+		err := rows.Scan(
+			&video.ID, &video.LibraryID, &video.Title, &video.FilePath, &video.FileSize, &video.Duration,
+			&video.Codec, &video.Resolution, &video.Bitrate, &video.FPS, &video.ThumbnailPath, &previewPath,
+			&date, &video.Rating, &description, &video.IsFavorite, &video.IsPinned, &video.NotInterested, &video.InEditList,
+			&video.CreatedAt, &video.UpdatedAt, &lastPlayedAt, &video.PlayCount,
+			&video.Status, &missingSince, &video.OSHash, &video.MD5,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan video: %w", err)
+		}
+		if lastPlayedAt.Valid {
+			video.LastPlayedAt = &lastPlayedAt.Time
+		}
+		video.Date = date.String
+		video.Description = description.String
+		video.PreviewPath = previewPath.String
+		if missingSince.Valid {
+			video.MissingSince = &missingSince.Time
+		}
+		byID[video.ID] = video
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating videos: %w", err)
+	}
+
+	videos := make([]models.Video, 0, len(ids))
+	for _, id := range ids {
+		if video, ok := byID[id]; ok {
+			videos = append(videos, video)
+		}
+	}
+	if err := s.loadVideoRelationshipsBatch(videos); err != nil {
+		log.Printf("Warning: Failed to batch load relationships: %v", err)
+	}
+	s.markUnavailable(videos)
+	return videos, nil
+}
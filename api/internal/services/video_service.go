@@ -52,16 +52,16 @@ type ParallelScanConfig struct {
 	LocalMaxConcurrent  int
 }
 
-// GetAll retrieves all videos with optional filters
-func (s *VideoService) GetAll(query *models.VideoSearchQuery) ([]models.Video, int, error) {
-	// Build base query - include library_id in SELECT
-	baseQuery := `
-		SELECT DISTINCT v.id, v.library_id, v.title, v.file_path, v.file_size, v.duration, v.codec,
-		       v.resolution, v.bitrate, v.fps, v.thumbnail_path, v.preview_path, v.date, v.rating, v.description,
-		       v.is_favorite, v.is_pinned, v.not_interested, v.in_edit_list, v.created_at, v.updated_at, v.last_played_at, v.play_count
-		FROM videos v
-	`
+// videoFilter is the SQL for a video search's filters, to follow "FROM videos v"
+type videoFilter struct {
+	joins    string
+	where    string
+	args     []interface{}
+	ftsMatch string // FTS5 match expression when results can be ranked by relevance
+}
 
+// buildVideoFilter translates search filters into joins and WHERE conditions
+func (s *VideoService) buildVideoFilter(query *models.VideoSearchQuery) (*videoFilter, error) {
 	// Build WHERE conditions
 	var conditions []string
 	var args []interface{}
@@ -73,9 +73,22 @@ func (s *VideoService) GetAll(query *models.VideoSearchQuery) ([]models.Video, i
 		args = append(args, query.LibraryID)
 	}
 
+	// Full-text search ranks matches across titles, descriptions, paths and linked
+	// names; builds without FTS5 fall back to substring matching
+	var ftsMatch string
+	var joinArgs []interface{}
 	if query.Query != "" {
-		conditions = append(conditions, "(v.title LIKE ? OR v.file_path LIKE ?)")
-		args = append(args, "%"+query.Query+"%", "%"+query.Query+"%")
+		if database.FTSEnabled() {
+			ftsMatch = buildFTSQuery(query.Query)
+		}
+		if ftsMatch != "" {
+			joins = append(joins, "INNER JOIN (SELECT rowid AS id, "+videoSearchRank+" AS rank FROM videos_fts WHERE videos_fts MATCH ?) fts ON fts.id = v.id")
+			joinArgs = append(joinArgs, ftsMatch)
+		} else {
+			pattern := "%" + query.Query + "%"
+			conditions = append(conditions, "(v.title LIKE ? OR v.file_path LIKE ? OR v.description LIKE ?)")
+			args = append(args, pattern, pattern, pattern)
+		}
 	}
 
 	if query.Resolution != "" {
@@ -113,47 +126,47 @@ func (s *VideoService) GetAll(query *models.VideoSearchQuery) ([]models.Video, i
 		args = append(args, query.DateTo)
 	}
 
-	// Add JOIN conditions for relationships
+	// Relationship filters use subqueries rather than joins, so each video appears
+	// once and neither the list nor the count needs DISTINCT
 	if query.PerformerID > 0 {
-		joins = append(joins, "INNER JOIN video_performers vp ON v.id = vp.video_id")
-		conditions = append(conditions, "vp.performer_id = ?")
+		conditions = append(conditions, "v.id IN (SELECT video_id FROM video_performers WHERE performer_id = ?)")
 		args = append(args, query.PerformerID)
 	}
 
 	if query.StudioID > 0 {
-		joins = append(joins, "INNER JOIN video_studios vs ON v.id = vs.video_id")
-		conditions = append(conditions, "vs.studio_id = ?")
+		conditions = append(conditions, "v.id IN (SELECT video_id FROM video_studios WHERE studio_id = ?)")
 		args = append(args, query.StudioID)
 	}
 
 	if query.GroupID > 0 {
-		joins = append(joins, "INNER JOIN video_groups vg ON v.id = vg.video_id")
-		conditions = append(conditions, "vg.group_id = ?")
+		conditions = append(conditions, "v.id IN (SELECT video_id FROM video_groups WHERE group_id = ?)")
 		args = append(args, query.GroupID)
 	}
 
 	if len(query.TagIDs) > 0 {
-		joins = append(joins, "INNER JOIN video_tags vt ON v.id = vt.video_id")
 		placeholders := make([]string, len(query.TagIDs))
 		for i, tagID := range query.TagIDs {
 			placeholders[i] = "?"
 			args = append(args, tagID)
 		}
-		conditions = append(conditions, fmt.Sprintf("vt.tag_id IN (%s)", strings.Join(placeholders, ",")))
+		// A parent tag also matches videos with any of its descendants
+		for _, tagID := range query.TagIDs {
+			args = append(args, tagID)
+		}
+		conditions = append(conditions, fmt.Sprintf("v.id IN (SELECT video_id FROM video_tags WHERE tag_id IN (%s) OR tag_id IN (SELECT tag_id FROM tag_ancestors WHERE ancestor_id IN (%s)))",
+			strings.Join(placeholders, ","), strings.Join(placeholders, ",")))
 	}
 
 	// Single tag filter
 	if query.TagID > 0 {
-		joins = append(joins, "INNER JOIN video_tags vt2 ON v.id = vt2.video_id")
-		conditions = append(conditions, "vt2.tag_id = ?")
-		args = append(args, query.TagID)
+		conditions = append(conditions, "v.id IN (SELECT video_id FROM video_tags WHERE tag_id = ? OR tag_id IN (SELECT tag_id FROM tag_ancestors WHERE ancestor_id = ?))")
+		args = append(args, query.TagID, query.TagID)
 	}
 
 	// Category filter (checks if video has any performers with specified category)
 	if query.Category != "" {
-		joins = append(joins, "INNER JOIN video_performers vp2 ON v.id = vp2.video_id")
-		joins = append(joins, "INNER JOIN performers p ON vp2.performer_id = p.id")
-		conditions = append(conditions, "p.category = ?")
+		conditions = append(conditions, `v.id IN (SELECT vp.video_id FROM video_performers vp
+			JOIN performers p ON vp.performer_id = p.id WHERE p.category = ?)`)
 		args = append(args, query.Category)
 	}
 
@@ -168,59 +181,78 @@ func (s *VideoService) GetAll(query *models.VideoSearchQuery) ([]models.Video, i
 		args = append(args, *query.InEditList)
 	}
 
-	// Build the WHERE clause
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	// Structured query from the search box
+	filtersStatus := false
+	if query.Q != "" {
+		structured, err := compileVideoQuery(query.Q, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, structured.where)
+		args = append(args, structured.args...)
+		filtersStatus = structured.filtersStatus
+	}
+
+	// Availability filter - missing videos are hidden unless asked for
+	switch query.Status {
+	case "all":
+	case models.VideoStatusMissing:
+		conditions = append(conditions, "v.status = ?")
+		args = append(args, models.VideoStatusMissing)
+	default:
+		if !filtersStatus {
+			conditions = append(conditions, "COALESCE(v.status, 'available') != ?")
+			args = append(args, models.VideoStatusMissing)
+		}
 	}
 
-	// Build count query separately
-	countQuery := "SELECT COUNT(DISTINCT v.id) FROM videos v"
+	// Join placeholders come before the WHERE placeholders
+	args = append(joinArgs, args...)
+
+	filter := &videoFilter{args: args, ftsMatch: ftsMatch}
 	if len(joins) > 0 {
-		countQuery += " " + strings.Join(joins, " ")
+		filter.joins = " " + strings.Join(joins, " ")
 	}
-	countQuery += whereClause
-
-	// Count total results
-	var total int
-	err := s.db.QueryRow(countQuery, args...).Scan(&total)
-	if err != nil && err != sql.ErrNoRows {
-		log.Printf("Count query failed: %v", err)
-		return nil, 0, fmt.Errorf("failed to count videos: %w", err)
+	if len(conditions) > 0 {
+		filter.where = " WHERE " + strings.Join(conditions, " AND ")
 	}
+	return filter, nil
+}
 
-	// Build the main query
-	mainQuery := baseQuery
-	if len(joins) > 0 {
-		mainQuery += " " + strings.Join(joins, " ")
+// GetAll retrieves all videos with optional filters
+func (s *VideoService) GetAll(query *models.VideoSearchQuery) ([]models.Video, int, error) {
+	page, err := s.GetPage(query)
+	if err != nil {
+		return nil, 0, err
 	}
-	mainQuery += whereClause
+	return page.Videos, page.Total, nil
+}
 
-	// Add sorting
-	sortBy := query.SortBy
-	if sortBy == "" {
-		sortBy = "created_at"
-	}
-	sortOrder := query.SortOrder
-	if sortOrder == "" {
-		sortOrder = "desc"
+// GetPage retrieves one page of videos. Pages follow query.Cursor from the
+// previous page when set, which stays fast deep into large libraries; otherwise
+// query.Page selects the page by offset.
+func (s *VideoService) GetPage(query *models.VideoSearchQuery) (*models.VideoPage, error) {
+	filter, err := s.buildVideoFilter(query)
+	if err != nil {
+		return nil, err
 	}
+	args := filter.args
+	ftsMatch := filter.ftsMatch
 
-	// Validate sort column to prevent SQL injection
-	validSortColumns := map[string]bool{
-		"id": true, "created_at": true, "updated_at": true, "title": true,
-		"duration": true, "file_size": true, "play_count": true,
-	}
-	if !validSortColumns[sortBy] {
-		sortBy = "created_at"
-	}
+	// Build count query separately
+	countQuery := "SELECT COUNT(*) FROM videos v" + filter.joins + filter.where
 
-	// Validate sort order
-	if sortOrder != "asc" && sortOrder != "desc" {
-		sortOrder = "desc"
+	// Count total results
+	var total int
+	err = s.db.QueryRow(countQuery, args...).Scan(&total)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Count query failed: %v", err)
+		return nil, fmt.Errorf("failed to count videos: %w", err)
 	}
 
-	mainQuery += fmt.Sprintf(" ORDER BY v.%s %s", sortBy, strings.ToUpper(sortOrder))
+	// Add sorting
+	order := resolveVideoSort(query, ftsMatch)
+	sortBy, sortOrder, sortKey, seed := order.by, order.order, order.key, order.seed
 
 	// Add pagination
 	limit := query.Limit
@@ -233,14 +265,56 @@ func (s *VideoService) GetAll(query *models.VideoSearchQuery) ([]models.Video, i
 	}
 	offset := (page - 1) * limit
 
-	mainQuery += " LIMIT ? OFFSET ?"
-	queryArgs := append(args, limit, offset)
+	// Keyset pagination continues after the last row of the previous page. Rows
+	// with equal keys are ordered by ID in the same direction, so none are skipped.
+	// The leading inclusive bound lets SQLite seek the keyset index to the cursor.
+	where := filter.where
+	queryArgs := append([]interface{}{}, args...)
+	if query.Cursor != "" {
+		cursor, err := decodeVideoCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != sortBy || cursor.Order != sortOrder || (sortBy == "random" && query.Seed != 0 && cursor.Seed != query.Seed) {
+			return nil, fmt.Errorf("%w: it belongs to a different sort, start again without it", ErrInvalidCursor)
+		}
+		if sortBy == "random" {
+			seed = cursor.Seed
+			sortKey = randomSortKey(seed)
+		}
+
+		comparison := "<"
+		if sortOrder == "asc" {
+			comparison = ">"
+		}
+		condition := fmt.Sprintf("%[1]s %[2]s= ? AND (%[1]s %[2]s ? OR v.id %[2]s ?)", sortKey, comparison)
+		if where == "" {
+			where = " WHERE " + condition
+		} else {
+			where += " AND " + condition
+		}
+		queryArgs = append(queryArgs, cursor.Key, cursor.Key, cursor.ID)
+		offset = 0
+	}
+
+	// Build the main query - include library_id in SELECT. One extra row tells
+	// whether there's another page.
+	direction := strings.ToUpper(sortOrder)
+	mainQuery := `
+		SELECT v.id, v.library_id, v.title, v.file_path, v.file_size, v.duration, v.codec,
+		       v.resolution, v.bitrate, v.fps, v.thumbnail_path, v.preview_path, v.date, v.rating, v.description,
+		       v.is_favorite, v.is_pinned, v.not_interested, v.in_edit_list, v.created_at, v.updated_at, v.last_played_at, v.play_count,
+		       COALESCE(v.status, 'available'), v.missing_since, COALESCE(v.oshash, ''), COALESCE(v.md5, ''),
+		       ` + sortKey + `
+		FROM videos v` + filter.joins + where +
+		fmt.Sprintf(" ORDER BY %s %s, v.id %s LIMIT ? OFFSET ?", sortKey, direction, direction)
+	queryArgs = append(queryArgs, limit+1, offset)
 
 	// Execute query
 	rows, err := s.db.Query(mainQuery, queryArgs...)
 	if err != nil {
 		log.Printf("Query execution failed: %v", err)
-		return nil, 0, fmt.Errorf("failed to query videos: %w", err)
+		return nil, fmt.Errorf("failed to query videos: %w", err)
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
@@ -250,22 +324,25 @@ func (s *VideoService) GetAll(query *models.VideoSearchQuery) ([]models.Video, i
 
 	// Initialize empty slice instead of nil
 	videos := make([]models.Video, 0)
+	var lastKey interface{}
 
 	for rows.Next() {
 		var video models.Video
-		var lastPlayedAt sql.NullTime
+		var lastPlayedAt, missingSince sql.NullTime
 		var date sql.NullString
 		var description sql.NullString
 		var previewPath sql.NullString
+		var key interface{}
 		err := rows.Scan(
 			&video.ID, &video.LibraryID, &video.Title, &video.FilePath, &video.FileSize, &video.Duration,
 			&video.Codec, &video.Resolution, &video.Bitrate, &video.FPS, &video.ThumbnailPath, &previewPath,
 			&date, &video.Rating, &description, &video.IsFavorite, &video.IsPinned, &video.NotInterested, &video.InEditList,
 			&video.CreatedAt, &video.UpdatedAt, &lastPlayedAt, &video.PlayCount,
+			&video.Status, &missingSince, &video.OSHash, &video.MD5, &key,
 		)
 		if err != nil {
 			log.Printf("Failed to scan video row: %v", err)
-			return nil, 0, fmt.Errorf("failed to scan video: %w", err)
+			return nil, fmt.Errorf("failed to scan video: %w", err)
 		}
 
 		if lastPlayedAt.Valid {
@@ -280,14 +357,33 @@ func (s *VideoService) GetAll(query *models.VideoSearchQuery) ([]models.Video, i
 		if previewPath.Valid {
 			video.PreviewPath = previewPath.String
 		}
+		if missingSince.Valid {
+			video.MissingSince = &missingSince.Time
+		}
 
 		videos = append(videos, video)
+		if len(videos) <= limit {
+			lastKey = key
+		}
 	}
 
 	// Check for errors from iterating over rows
 	if err = rows.Err(); err != nil {
 		log.Printf("Row iteration error: %v", err)
-		return nil, 0, fmt.Errorf("error iterating videos: %w", err)
+		return nil, fmt.Errorf("error iterating videos: %w", err)
+	}
+
+	result := &models.VideoPage{Total: total}
+	if sortBy == "random" {
+		result.Seed = seed
+	}
+	if len(videos) > limit {
+		videos = videos[:limit]
+		next := videoCursor{Sort: sortBy, Order: sortOrder, Key: cursorKey(lastKey), ID: videos[limit-1].ID}
+		if sortBy == "random" {
+			next.Seed = seed
+		}
+		result.NextCursor = next.encode()
 	}
 
 	// Load relationships for all videos in batch
@@ -296,22 +392,28 @@ func (s *VideoService) GetAll(query *models.VideoSearchQuery) ([]models.Video, i
 			log.Printf("Warning: Failed to batch load relationships: %v", err)
 			// Don't fail the entire request, just log the warning
 		}
+		s.markUnavailable(videos)
+		if ftsMatch != "" {
+			s.attachSearchHighlights(videos, ftsMatch)
+		}
 	}
 
-	return videos, total, nil
+	result.Videos = videos
+	return result, nil
 }
 
 // GetByID retrieves a video by ID
 func (s *VideoService) GetByID(id int64) (*models.Video, error) {
 	var video models.Video
-	var lastPlayedAt sql.NullTime
+	var lastPlayedAt, missingSince sql.NullTime
 	var date sql.NullString
 	var description sql.NullString
 
 	query := `
 		SELECT id, library_id, title, file_path, file_size, duration, codec, resolution,
 		       bitrate, fps, thumbnail_path, date, rating, description, is_favorite, is_pinned,
-		       not_interested, in_edit_list, created_at, updated_at, last_played_at, play_count
+		       not_interested, in_edit_list, created_at, updated_at, last_played_at, play_count,
+		       COALESCE(status, 'available'), missing_since, COALESCE(oshash, ''), COALESCE(md5, '')
 		FROM videos
 		WHERE id = ?
 	`
@@ -321,6 +423,7 @@ func (s *VideoService) GetByID(id int64) (*models.Video, error) {
 		&video.Codec, &video.Resolution, &video.Bitrate, &video.FPS, &video.ThumbnailPath,
 		&date, &video.Rating, &description, &video.IsFavorite, &video.IsPinned, &video.NotInterested, &video.InEditList,
 		&video.CreatedAt, &video.UpdatedAt, &lastPlayedAt, &video.PlayCount,
+		&video.Status, &missingSince, &video.OSHash, &video.MD5,
 	)
 
 	if err == sql.ErrNoRows {
@@ -339,13 +442,18 @@ func (s *VideoService) GetByID(id int64) (*models.Video, error) {
 	if description.Valid {
 		video.Description = description.String
 	}
+	if missingSince.Valid {
+		video.MissingSince = &missingSince.Time
+	}
 
 	// Load relationships
 	if err := s.loadVideoRelationships(&video); err != nil {
 		log.Printf("Warning: Failed to load relationships for video %d: %v", video.ID, err)
 	}
+	videos := []models.Video{video}
+	s.markUnavailable(videos)
 
-	return &video, nil
+	return &videos[0], nil
 }
 
 // GetByPerformer retrieves all videos featuring a specific performer
@@ -353,7 +461,8 @@ func (s *VideoService) GetByPerformer(performerID int64) ([]models.Video, error)
 	query := `
 		SELECT DISTINCT v.id, v.library_id, v.title, v.file_path, v.file_size, v.duration, v.codec, v.resolution,
 		       v.bitrate, v.fps, v.thumbnail_path, v.date, v.rating, v.description, v.is_favorite, v.is_pinned,
-		       v.not_interested, v.in_edit_list, v.created_at, v.updated_at, v.last_played_at, v.play_count
+		       v.not_interested, v.in_edit_list, v.created_at, v.updated_at, v.last_played_at, v.play_count,
+		       COALESCE(v.status, 'available'), v.missing_since, COALESCE(v.oshash, ''), COALESCE(v.md5, '')
 		FROM videos v
 		INNER JOIN video_performers vp ON v.id = vp.video_id
 		WHERE vp.performer_id = ?
@@ -369,7 +478,7 @@ func (s *VideoService) GetByPerformer(performerID int64) ([]models.Video, error)
 	var videos []models.Video
 	for rows.Next() {
 		var video models.Video
-		var lastPlayedAt sql.NullTime
+		var lastPlayedAt, missingSince sql.NullTime
 		var date, description sql.NullString
 
 		err := rows.Scan(
@@ -377,10 +486,14 @@ func (s *VideoService) GetByPerformer(performerID int64) ([]models.Video, error)
 			&video.Codec, &video.Resolution, &video.Bitrate, &video.FPS, &video.ThumbnailPath,
 			&date, &video.Rating, &description, &video.IsFavorite, &video.IsPinned, &video.NotInterested, &video.InEditList,
 			&video.CreatedAt, &video.UpdatedAt, &lastPlayedAt, &video.PlayCount,
+			&video.Status, &missingSince, &video.OSHash, &video.MD5,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan video: %w", err)
 		}
+		if missingSince.Valid {
+			video.MissingSince = &missingSince.Time
+		}
 
 		if lastPlayedAt.Valid {
 			video.LastPlayedAt = &lastPlayedAt.Time
@@ -415,6 +528,7 @@ func (s *VideoService) Create(create *models.VideoCreate) (*models.Video, error)
 		Bitrate:       create.Bitrate,
 		FPS:           create.FPS,
 		ThumbnailPath: create.ThumbnailPath,
+		Status:        models.VideoStatusAvailable,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
@@ -583,10 +697,9 @@ func (s *VideoService) Update(id int64, update *models.VideoUpdate) (*models.Vid
 		if _, err := s.db.Exec("DELETE FROM video_tags WHERE video_id = ?", id); err != nil {
 			return nil, fmt.Errorf("failed to clear tags: %w", err)
 		}
-		// Add new tags
+		// Add new tags, along with their ancestors
 		for _, tagID := range update.TagIDs {
-			if _, err := s.db.Exec("INSERT INTO video_tags (video_id, tag_id) VALUES (?, ?)",
-				id, tagID); err != nil {
+			if _, err := insertVideoTags(s.db, []linkPair{{id, tagID}}); err != nil {
 				return nil, fmt.Errorf("failed to add tag: %w", err)
 			}
 		}
@@ -608,6 +721,12 @@ func (s *VideoService) Delete(id int64) error {
 
 // ScanLibrary scans a library for video files
 func (s *VideoService) ScanLibrary(libraryID int64) error {
+	return s.scanLibrary(libraryID, nil)
+}
+
+// scanLibrary scans a library for video files. When queued is non-nil the scan
+// reports into that pending child activity instead of creating its own.
+func (s *VideoService) scanLibrary(libraryID int64, queued *models.Activity) error {
 	// Initialize console log service
 	consoleLogSvc := NewConsoleLogService()
 
@@ -618,9 +737,29 @@ func (s *VideoService) ScanLibrary(libraryID int64) error {
 			"library_id": libraryID,
 			"error":      err.Error(),
 		})
+		if queued != nil {
+			if err := s.activityService.FailTask(queued.ID, fmt.Sprintf("Library not found: %v", err)); err != nil {
+				log.Printf("Failed to fail task: %v", err)
+			}
+		}
 		return fmt.Errorf("library not found: %w", err)
 	}
 
+	// An unmounted root would otherwise look like every video was deleted
+	if err := s.libraryService.EnsureOnline(library); err != nil {
+		consoleLogSvc.LogAPI("warning", fmt.Sprintf("Library scan skipped, library offline: %s", library.Name), map[string]interface{}{
+			"library_id":   libraryID,
+			"library_path": library.Path,
+			"error":        err.Error(),
+		})
+		if queued != nil {
+			if err := s.activityService.FailTask(queued.ID, fmt.Sprintf("Library offline: %s", library.Path)); err != nil {
+				log.Printf("Failed to fail task: %v", err)
+			}
+		}
+		return err
+	}
+
 	// Log scan start
 	consoleLogSvc.LogAPI("info", fmt.Sprintf("Library scan started: %s", library.Name), map[string]interface{}{
 		"library_id":   libraryID,
@@ -628,16 +767,21 @@ func (s *VideoService) ScanLibrary(libraryID int64) error {
 		"library_path": library.Path,
 	})
 
-	// Create activity log
-	activity, err := s.activityService.StartTask(
-		"video_scan",
-		fmt.Sprintf("Scanning library: %s", library.Name),
-		map[string]interface{}{
-			"library_id":   libraryID,
-			"library_name": library.Name,
-			"library_path": library.Path,
-		},
-	)
+	// Create activity log, or start the one queued by the parent scan
+	activity := queued
+	if activity != nil {
+		err = s.activityService.BeginTask(activity.ID, fmt.Sprintf("Scanning library: %s", library.Name))
+	} else {
+		activity, err = s.activityService.StartTask(
+			"video_scan",
+			fmt.Sprintf("Scanning library: %s", library.Name),
+			map[string]interface{}{
+				"library_id":   libraryID,
+				"library_name": library.Name,
+				"library_path": library.Path,
+			},
+		)
+	}
 	if err != nil {
 		consoleLogSvc.LogAPI("error", "Failed to create activity log for library scan", map[string]interface{}{
 			"library_id": libraryID,
@@ -646,8 +790,16 @@ func (s *VideoService) ScanLibrary(libraryID int64) error {
 		return fmt.Errorf("failed to create activity log: %w", err)
 	}
 
-	// Scan for video files
-	videoFiles, err := s.findVideoFiles(library.Path)
+	// Scan for video files, honoring the library's include/exclude rules
+	filter, err := s.libraryService.GetScanFilter(library)
+	if err != nil {
+		if err := s.activityService.FailTask(activity.ID, fmt.Sprintf("Failed to load library settings: %v", err)); err != nil {
+			log.Printf("Failed to fail task: %v", err)
+		}
+		return err
+	}
+
+	videoFiles, err := s.findVideoFiles(filter)
 	if err != nil {
 		consoleLogSvc.LogAPI("error", "Failed to scan directory for videos", map[string]interface{}{
 			"library_id":   libraryID,
@@ -667,24 +819,71 @@ func (s *VideoService) ScanLibrary(libraryID int64) error {
 		"library_name":   library.Name,
 		"total_files":    total,
 	})
-	if err := s.activityService.UpdateProgress(activity.ID, 0, fmt.Sprintf("Found %d video files", total)); err != nil {
+	if err := s.activityService.UpdateItemProgress(activity.ID, 0, total, fmt.Sprintf("Found %d video files", total)); err != nil {
 		log.Printf("Failed to update progress: %v", err)
 	}
 
+	// Load what the database already knows about this library so the scan only
+	// processes differences instead of re-adding by exact path
+	index, err := s.loadLibraryIndex(libraryID)
+	if err != nil {
+		if err := s.activityService.FailTask(activity.ID, fmt.Sprintf("Failed to load library index: %v", err)); err != nil {
+			log.Printf("Failed to fail task: %v", err)
+		}
+		return err
+	}
+
+	onDisk := make(map[string]bool, total)
+	for _, filePath := range videoFiles {
+		onDisk[filePath] = true
+	}
+
+	// Rows whose files are gone are move candidates first and only get marked
+	// missing if no new file turns out to be them. Rows already missing anywhere
+	// are candidates too, so a file moved between libraries keeps its metadata.
+	vanished := make(map[int64]*indexedVideo)
+	var candidateRows []*indexedVideo
+	for path, row := range index {
+		if !onDisk[path] && row.Status != models.VideoStatusMissing {
+			// Files hidden by the library's settings are left alone, not marked missing
+			if _, err := os.Stat(path); err == nil {
+				continue
+			}
+			vanished[row.ID] = row
+			candidateRows = append(candidateRows, row)
+		}
+	}
+	missingRows, err := s.loadMissingVideos()
+	if err != nil {
+		log.Printf("Failed to load missing videos for move detection: %v", err)
+	}
+	candidateRows = append(candidateRows, missingRows...)
+	candidates := newMoveCandidates(candidateRows)
+
+	// Filename parse rules fill in metadata for newly added videos
+	parseRules, err := NewFilenameParserService().LoadRuleSet(libraryID)
+	if err != nil {
+		log.Printf("Failed to load filename parse rules: %v", err)
+		parseRules = &ParseRuleSet{}
+	}
+	nfoService := NewNFOService()
+
+	// Files the minimum duration kept out last time aren't probed again while unchanged
+	exclusions, err := s.loadScanExclusions(libraryID)
+	if err != nil {
+		log.Printf("Failed to load scan exclusions: %v", err)
+		exclusions = map[string]*scanExclusion{}
+	}
+	reusedExclusions := make(map[string]bool)
+
 	// Process each video file
 	processed := 0
-	added := 0
-	skipped := 0
+	result := SyncResult{}
+	var addedIDs []int64
 
 	// Create media service for metadata extraction
 	mediaService := NewMediaService()
 
-	// Get thumbnail base directory
-	thumbnailDir := os.Getenv("THUMBNAIL_DIR")
-	if thumbnailDir == "" {
-		thumbnailDir = filepath.Join("assets", "thumbnails")
-	}
-
 	// Setup parallel thumbnail generation using new hierarchical structure
 	numWorkers := runtime.NumCPU() * 2 // Use 2x CPU cores for I/O bound work
 	thumbnailJobs := make(chan thumbnailJobHierarchical, numWorkers*2)
@@ -697,138 +896,199 @@ func (s *VideoService) ScanLibrary(libraryID int64) error {
 		go func(workerID int) {
 			defer wg.Done()
 			for job := range thumbnailJobs {
-				result, err := mediaService.GenerateThumbnailHierarchical(job.config)
+				thumb, err := mediaService.GenerateThumbnailHierarchical(job.config)
 				if err != nil {
 					log.Printf("Worker %d: Failed to generate thumbnail for video ID %d: %v", workerID, job.videoID, err)
 				} else {
-					log.Printf("Worker %d: Generated thumbnail for video ID %d at %s", workerID, job.videoID, result.RelativePath)
+					log.Printf("Worker %d: Generated thumbnail for video ID %d at %s", workerID, job.videoID, thumb.RelativePath)
 					// Update video thumbnail path in database
 					thumbnailMutex.Lock()
-					s.updateVideoThumbnailPath(job.videoID, result.RelativePath)
+					s.updateVideoThumbnailPath(job.videoID, thumb.RelativePath)
 					thumbnailMutex.Unlock()
 				}
 			}
 		}(i)
 	}
 
+	progressMessage := func(currentFile string) string {
+		return fmt.Sprintf("Processing %d/%d (Unchanged: %d, Added: %d, Moved: %d, Restored: %d)\nCurrent: %s",
+			processed, total, result.Unchanged, result.Added, result.Moved, result.Restored, currentFile)
+	}
+
 	// Process videos sequentially, but queue thumbnails for parallel generation
 	for _, filePath := range videoFiles {
 		processed++
-		progress := int((float64(processed) / float64(total)) * 100)
 		currentFile := filepath.Base(filePath)
 
-		// Update progress with current file being processed
-		progressMsg := fmt.Sprintf("Processing %d/%d (Skipped: %d, Added: %d)\nCurrent: %s", processed, total, skipped, added, currentFile)
+		// Known path: bring it back if it was missing and refresh its fingerprint
+		// when it was never taken or the file was overwritten in place
+		if row, ok := index[filePath]; ok {
+			if row.Status == models.VideoStatusMissing {
+				if err := s.setVideoStatus(row.ID, models.VideoStatusAvailable); err != nil {
+					log.Printf("Failed to restore video %d: %v", row.ID, err)
+				} else {
+					result.Restored++
+				}
+			} else {
+				result.Unchanged++
+			}
+
+			if row.fingerprintStale(filePath) {
+				if fp, err := computeFingerprint(filePath); err == nil {
+					if err := s.saveFingerprint(row.ID, fp); err != nil {
+						log.Printf("Failed to refresh fingerprint for video %d: %v", row.ID, err)
+					}
+				}
+			}
 
-		// Check if video already exists
-		exists, err := s.videoExists(filePath)
-		if err == nil && exists {
-			skipped++
-			if err := s.activityService.UpdateProgress(activity.ID, progress, progressMsg); err != nil {
+			if err := s.activityService.UpdateItemProgress(activity.ID, processed, total, progressMessage(currentFile)); err != nil {
 				log.Printf("Failed to update progress: %v", err)
 			}
 			continue
 		}
 
-		// Get file size
-		fileInfo, err := os.Stat(filePath)
-		if err != nil {
-			skipped++
-			progressMsg = fmt.Sprintf("Processing %d/%d (Skipped: %d, Added: %d) - Error: %v\nCurrent: %s", processed, total, skipped, added, err, currentFile)
-			if err := s.activityService.UpdateProgress(activity.ID, progress, progressMsg); err != nil {
+		// The index only covers this library; a path another library already
+		// holds, like one nested inside it, is left to that library
+		if exists, err := s.videoExists(filePath); err == nil && exists {
+			result.Skipped++
+			if err := s.activityService.UpdateItemProgress(activity.ID, processed, total, progressMessage(currentFile)); err != nil {
 				log.Printf("Failed to update progress: %v", err)
 			}
 			continue
 		}
 
-		// Extract metadata using MediaService
-		metadata, err := mediaService.ExtractMetadata(filePath)
+		fp, err := computeFingerprint(filePath)
 		if err != nil {
-			// If metadata extraction fails, still create the video with basic info
-			metadata = &VideoMetadata{
-				Duration: 0,
-				Size:     fileInfo.Size(),
+			result.Failed++
+			progressMsg := fmt.Sprintf("%s - Error: %v", progressMessage(currentFile), err)
+			if err := s.activityService.UpdateItemProgress(activity.ID, processed, total, progressMsg); err != nil {
+				log.Printf("Failed to update progress: %v", err)
 			}
+			continue
 		}
 
-		// Build resolution string
-		resolution := ""
-		if metadata.Width > 0 && metadata.Height > 0 {
-			resolution = fmt.Sprintf("%dx%d", metadata.Width, metadata.Height)
+		// New path: check whether it's a known video that was moved or renamed
+		if row := candidates.claim(fp); row != nil {
+			if err := s.applyMove(row, library, filePath, fp); err != nil {
+				log.Printf("Failed to apply move for video %d: %v", row.ID, err)
+				result.Failed++
+			} else {
+				delete(vanished, row.ID)
+				result.Moved++
+			}
+			if err := s.activityService.UpdateItemProgress(activity.ID, processed, total, progressMessage(currentFile)); err != nil {
+				log.Printf("Failed to update progress: %v", err)
+			}
+			continue
 		}
 
-		// Get expected thumbnail path using hierarchical structure (but don't generate yet)
-		thumbnailConfig := ThumbnailConfig{
-			LibraryID:     libraryID,
-			LibraryPath:   library.Path,
-			VideoFilePath: filePath,
-			Duration:      metadata.Duration,
-			ThumbnailDir:  thumbnailDir,
+		if exclusion := exclusions[filePath]; exclusion != nil && exclusion.unchanged(fp) && !filter.MatchDuration(exclusion.Duration) {
+			reusedExclusions[filePath] = true
+			result.Skipped++
+			if err := s.activityService.UpdateItemProgress(activity.ID, processed, total, progressMessage(currentFile)); err != nil {
+				log.Printf("Failed to update progress: %v", err)
+			}
+			continue
 		}
 
-		expectedThumbnail := mediaService.GetThumbnailPath(thumbnailConfig)
-		thumbnailPath := ""
-		if expectedThumbnail != nil {
-			thumbnailPath = expectedThumbnail.RelativePath
+		create, thumbnailConfig := newVideoCreate(mediaService, library, filePath, fp.Size)
+		if !filter.MatchDuration(create.Duration) {
+			if err := s.saveScanExclusion(libraryID, filePath, fp, create.Duration); err != nil {
+				log.Printf("Failed to remember excluded file %s: %v", filePath, err)
+			} else {
+				reusedExclusions[filePath] = true
+			}
+			result.Skipped++
+			if err := s.activityService.UpdateItemProgress(activity.ID, processed, total, progressMessage(currentFile)); err != nil {
+				log.Printf("Failed to update progress: %v", err)
+			}
+			continue
 		}
-
-		// Create video record with library ID and thumbnail path
-		create := &models.VideoCreate{
-			LibraryID:     libraryID,
-			Title:         filepath.Base(filePath),
-			FilePath:      filePath,
-			FileSize:      metadata.Size,
-			Duration:      metadata.Duration,
-			Codec:         metadata.Codec,
-			Resolution:    resolution,
-			Bitrate:       metadata.Bitrate,
-			FPS:           metadata.FrameRate,
-			ThumbnailPath: thumbnailPath,
+		if !filter.Settings.AutoThumbnails {
+			create.ThumbnailPath = ""
 		}
-
 		video, err := s.Create(create)
 		if err != nil {
-			skipped++
-			progressMsg = fmt.Sprintf("Processing %d/%d (Skipped: %d, Added: %d) - Error: %v\nCurrent: %s", processed, total, skipped, added, err, currentFile)
-			if err := s.activityService.UpdateProgress(activity.ID, progress, progressMsg); err != nil {
+			result.Failed++
+			progressMsg := fmt.Sprintf("%s - Error: %v", progressMessage(currentFile), err)
+			if err := s.activityService.UpdateItemProgress(activity.ID, processed, total, progressMsg); err != nil {
 				log.Printf("Failed to update progress: %v", err)
 			}
 			continue
 		}
 
+		if err := s.saveFingerprint(video.ID, fp); err != nil {
+			log.Printf("Failed to save fingerprint for video %d: %v", video.ID, err)
+		}
+
+		if err := parseRules.ApplyToVideo(video.ID, filePath); err != nil {
+			log.Printf("Failed to apply filename parse rules to video %d: %v", video.ID, err)
+		}
+
+		// A sidecar NFO fills in whatever filename parsing left empty
+		if _, err := nfoService.ImportVideo(video.ID, models.NFOPolicyFill); err != nil {
+			log.Printf("Failed to import NFO for video %d: %v", video.ID, err)
+		}
+
 		// Queue thumbnail generation for parallel processing using hierarchical structure
-		thumbnailJobs <- thumbnailJobHierarchical{
-			videoID: video.ID,
-			config:  thumbnailConfig,
+		if filter.Settings.AutoThumbnails {
+			thumbnailJobs <- thumbnailJobHierarchical{
+				videoID: video.ID,
+				config:  thumbnailConfig,
+			}
 		}
 
-		thumbnailMutex.Lock()
-		added++
-		thumbnailMutex.Unlock()
+		result.Added++
+		addedIDs = append(addedIDs, video.ID)
 
-		progressMsg = fmt.Sprintf("Processing %d/%d (Skipped: %d, Added: %d)\nCurrent: %s", processed, total, skipped, added, currentFile)
-		if err := s.activityService.UpdateProgress(activity.ID, progress, progressMsg); err != nil {
+		if err := s.activityService.UpdateItemProgress(activity.ID, processed, total, progressMessage(currentFile)); err != nil {
 			log.Printf("Failed to update progress: %v", err)
 		}
 	}
 
+	// Whatever vanished and wasn't claimed by a move is missing. Rows are kept so
+	// metadata survives a temporarily unplugged drive; purge them explicitly.
+	for _, row := range vanished {
+		if err := s.setVideoStatus(row.ID, models.VideoStatusMissing); err != nil {
+			log.Printf("Failed to mark video %d missing: %v", row.ID, err)
+			continue
+		}
+		result.Missing++
+	}
+
+	// Forget the exclusions this scan didn't confirm
+	s.pruneScanExclusions(libraryID, exclusions, reusedExclusions)
+
 	// Close thumbnail jobs channel and wait for all workers to finish
 	close(thumbnailJobs)
 	log.Println("Waiting for thumbnail generation workers to complete...")
 	wg.Wait()
 	log.Println("All thumbnail generation workers completed")
 
+	// Auto-tag rules see the new videos with their parsed and NFO metadata
+	if err := NewAutoTagService().ApplyOnIngest(addedIDs); err != nil {
+		log.Printf("Failed to apply auto-tag rules to new videos: %v", err)
+	}
+
 	// Log scan completion
 	consoleLogSvc.LogAPI("info", fmt.Sprintf("Library scan completed: %s", library.Name), map[string]interface{}{
-		"library_id":     libraryID,
-		"library_name":   library.Name,
-		"total_files":    total,
-		"videos_added":   added,
-		"videos_skipped": skipped,
+		"library_id":       libraryID,
+		"library_name":     library.Name,
+		"total_files":      total,
+		"videos_added":     result.Added,
+		"videos_moved":     result.Moved,
+		"videos_restored":  result.Restored,
+		"videos_missing":   result.Missing,
+		"videos_unchanged": result.Unchanged,
+		"videos_skipped":   result.Skipped,
+		"videos_failed":    result.Failed,
 	})
 
 	// Complete activity
-	_ = s.activityService.CompleteTask(int64(activity.ID), fmt.Sprintf("Scan complete: %d videos added, %d skipped", added, skipped))
+	_ = s.activityService.CompleteTask(int64(activity.ID), fmt.Sprintf(
+		"Scan complete: %d added, %d moved, %d restored, %d missing, %d unchanged, %d skipped",
+		result.Added, result.Moved, result.Restored, result.Missing, result.Unchanged, result.Skipped,
+	))
 
 	return nil
 }
@@ -893,16 +1153,44 @@ func (s *VideoService) ScanAllLibrariesParallel(config ParallelScanConfig) error
 
 	log.Printf("Grouped libraries - Server: %d, Local: %d", len(serverLibraries), len(localLibraries))
 
+	// Queue a child activity per library so the tree is complete from the start;
+	// the parent's progress is aggregated from these children
+	childActivities := make(map[int64]*models.Activity)
 	if activity != nil {
+		for _, lib := range libraries {
+			child, err := s.activityService.QueueChildTask(
+				activity.ID,
+				"video_scan",
+				fmt.Sprintf("Waiting to scan library: %s", lib.Name),
+				map[string]interface{}{
+					"library_id":   lib.ID,
+					"library_name": lib.Name,
+					"library_path": lib.Path,
+				},
+			)
+			if err != nil {
+				log.Printf("Failed to queue child activity for library %s: %v", lib.Name, err)
+				continue
+			}
+			childActivities[lib.ID] = child
+		}
+
 		s.activityService.UpdateProgress(
 			activity.ID,
-			10,
+			0,
 			fmt.Sprintf("Grouped %d libraries (Server: %d, Local: %d)", len(libraries), len(serverLibraries), len(localLibraries)),
 		)
 	}
 
 	// Create wait group for all scans
 	var wg sync.WaitGroup
+	var failedMu sync.Mutex
+	failed := 0
+	countFailures := func(n int) {
+		failedMu.Lock()
+		failed += n
+		failedMu.Unlock()
+	}
 
 	// Scan server libraries with limited concurrency
 	if len(serverLibraries) > 0 {
@@ -910,7 +1198,7 @@ func (s *VideoService) ScanAllLibrariesParallel(config ParallelScanConfig) error
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			s.scanLibrariesConcurrent(serverLibraries, config.ServerMaxConcurrent, "SERVER")
+			countFailures(s.scanLibrariesConcurrent(serverLibraries, config.ServerMaxConcurrent, "SERVER", childActivities))
 		}()
 	}
 
@@ -920,36 +1208,12 @@ func (s *VideoService) ScanAllLibrariesParallel(config ParallelScanConfig) error
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			s.scanLibrariesConcurrent(localLibraries, config.LocalMaxConcurrent, "LOCAL")
+			countFailures(s.scanLibrariesConcurrent(localLibraries, config.LocalMaxConcurrent, "LOCAL", childActivities))
 		}()
 	}
 
-	// Update progress periodically while scans are running
-	done := make(chan struct{})
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-		progress := 20
-		for {
-			select {
-			case <-done:
-				return
-			case <-ticker.C:
-				if activity != nil && progress < 90 {
-					progress += 10
-					s.activityService.UpdateProgress(
-						activity.ID,
-						progress,
-						fmt.Sprintf("Scanning in progress... (%d%%)", progress),
-					)
-				}
-			}
-		}
-	}()
-
 	// Wait for all scans to complete
 	wg.Wait()
-	close(done)
 	log.Println("All parallel library scans completed")
 
 	// Log parallel scan completion
@@ -961,20 +1225,24 @@ func (s *VideoService) ScanAllLibrariesParallel(config ParallelScanConfig) error
 
 	// Complete activity
 	if activity != nil {
-		s.activityService.CompleteTask(
-			int64(activity.ID),
-			fmt.Sprintf("All %d libraries scanned successfully", len(libraries)),
-		)
+		message := fmt.Sprintf("All %d libraries scanned successfully", len(libraries))
+		if failed > 0 {
+			message = fmt.Sprintf("Scanned %d libraries, %d failed", len(libraries)-failed, failed)
+		}
+		s.activityService.CompleteTask(int64(activity.ID), message)
 	}
 
 	return nil
 }
 
-// scanLibrariesConcurrent scans multiple libraries with controlled concurrency
-func (s *VideoService) scanLibrariesConcurrent(libraries []models.Library, maxConcurrent int, driveType string) {
+// scanLibrariesConcurrent scans multiple libraries with controlled concurrency,
+// reporting each scan into its queued child activity. Returns the number of failed scans.
+func (s *VideoService) scanLibrariesConcurrent(libraries []models.Library, maxConcurrent int, driveType string, childActivities map[int64]*models.Activity) int {
 	// Create semaphore to limit concurrency
 	sem := make(chan struct{}, maxConcurrent)
 	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := 0
 
 	for _, library := range libraries {
 		wg.Add(1)
@@ -987,11 +1255,14 @@ func (s *VideoService) scanLibrariesConcurrent(libraries []models.Library, maxCo
 			log.Printf("[%s] Scanning library: %s (ID: %d, Path: %s)", driveType, lib.Name, lib.ID, lib.Path)
 			startTime := time.Now()
 
-			err := s.ScanLibrary(lib.ID)
+			err := s.scanLibrary(lib.ID, childActivities[lib.ID])
 			duration := time.Since(startTime)
 
 			if err != nil {
 				log.Printf("[%s] Failed to scan library %s: %v (Duration: %s)", driveType, lib.Name, err, duration)
+				mu.Lock()
+				failed++
+				mu.Unlock()
 			} else {
 				log.Printf("[%s] Successfully scanned library %s (Duration: %s)", driveType, lib.Name, duration)
 			}
@@ -1000,6 +1271,7 @@ func (s *VideoService) scanLibrariesConcurrent(libraries []models.Library, maxCo
 
 	wg.Wait()
 	log.Printf("[%s] All %d library scans completed", driveType, len(libraries))
+	return failed
 }
 
 // isServerDrive checks if a path is on a server drive
@@ -1038,28 +1310,12 @@ func (s *VideoService) isLocalDrive(path string, localDrives []string) bool {
 	return false
 }
 
-// findVideoFiles recursively finds all video files in a directory
-func (s *VideoService) findVideoFiles(dir string) ([]string, error) {
+// findVideoFiles recursively finds all video files in a library that pass its scan filter
+func (s *VideoService) findVideoFiles(filter *LibraryScanFilter) ([]string, error) {
 	var videoFiles []string
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		ext := strings.ToLower(filepath.Ext(path))
-		for _, supportedExt := range SupportedVideoExtensions {
-			if ext == supportedExt {
-				videoFiles = append(videoFiles, path)
-				break
-			}
-		}
-
-		return nil
+	err := filter.Walk(func(path string, info os.FileInfo) {
+		videoFiles = append(videoFiles, path)
 	})
 
 	return videoFiles, err
@@ -1582,11 +1838,12 @@ func (s *VideoService) GetVideoMarksBatch(filePaths []string) (map[string]*Video
 func (s *VideoService) GetByFilePath(filePath string) (*models.Video, error) {
 	var video models.Video
 	var date, description sql.NullString
-	var lastPlayedAt sql.NullTime
+	var lastPlayedAt, missingSince sql.NullTime
 
 	query := `SELECT id, library_id, title, file_path, file_size, duration, codec, resolution,
 	          bitrate, fps, thumbnail_path, date, rating, description, is_favorite, is_pinned,
-	          not_interested, in_edit_list, created_at, updated_at, last_played_at, play_count
+	          not_interested, in_edit_list, created_at, updated_at, last_played_at, play_count,
+	          COALESCE(status, 'available'), missing_since, COALESCE(oshash, ''), COALESCE(md5, '')
 	          FROM videos WHERE file_path = ?`
 
 	err := s.db.QueryRow(query, filePath).Scan(
@@ -1594,7 +1851,7 @@ func (s *VideoService) GetByFilePath(filePath string) (*models.Video, error) {
 		&video.Codec, &video.Resolution, &video.Bitrate, &video.FPS, &video.ThumbnailPath,
 		&date, &video.Rating, &description, &video.IsFavorite, &video.IsPinned,
 		&video.NotInterested, &video.InEditList, &video.CreatedAt, &video.UpdatedAt,
-		&lastPlayedAt, &video.PlayCount,
+		&lastPlayedAt, &video.PlayCount, &video.Status, &missingSince, &video.OSHash, &video.MD5,
 	)
 
 	if err == sql.ErrNoRows {
@@ -1614,6 +1871,9 @@ func (s *VideoService) GetByFilePath(filePath string) (*models.Video, error) {
 	if lastPlayedAt.Valid {
 		video.LastPlayedAt = &lastPlayedAt.Time
 	}
+	if missingSince.Valid {
+		video.MissingSince = &missingSince.Time
+	}
 
 	// Load related data
 	if err := s.loadVideoRelationships(&video); err != nil {
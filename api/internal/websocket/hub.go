@@ -66,6 +66,19 @@ func (h *Hub) BroadcastActivityUpdate(activity *models.Activity) {
 	}
 }
 
+// BroadcastActivityTree broadcasts a parent activity with its nested children to all clients.
+func (h *Hub) BroadcastActivityTree(tree *models.Activity) {
+	// Wrap message with type
+	wrapper := map[string]interface{}{
+		"type": "activity_tree",
+		"data": tree,
+	}
+	message, err := json.Marshal(wrapper)
+	if err == nil {
+		h.Broadcast(message)
+	}
+}
+
 // BroadcastStatusUpdate broadcasts a status update to all clients.
 func (h *Hub) BroadcastStatusUpdate(status *models.ActivityStatus) {
 	// Wrap message with type
@@ -90,6 +103,19 @@ func (h *Hub) BroadcastSystemEvent(event string) {
 	}
 }
 
+// BroadcastLibraryStatus broadcasts a library going offline or coming back online.
+func (h *Hub) BroadcastLibraryStatus(library *models.Library) {
+	// Wrap message with type
+	wrapper := map[string]interface{}{
+		"type": "library_status",
+		"data": library,
+	}
+	message, err := json.Marshal(wrapper)
+	if err == nil {
+		h.Broadcast(message)
+	}
+}
+
 // Register registers a new client with the hub.
 func (h *Hub) Register(client *Client) {
 	h.register <- client